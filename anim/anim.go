@@ -2,13 +2,16 @@ package anim
 
 import (
 	"korok.io/korok/anim/frame"
+	"korok.io/korok/anim/spine"
 	"korok.io/korok/anim/ween"
 	"korok.io/korok/gfx"
 )
 
 type AnimationSystem struct {
 	*frame.SpriteEngine
+	*frame.AnimatorEngine
 	*ween.TweenEngine
+	*spine.SkeletonEngine
 
 	// tables
 	st *gfx.SpriteTable
@@ -16,14 +19,19 @@ type AnimationSystem struct {
 }
 
 func NewAnimationSystem() *AnimationSystem {
+	se := frame.NewEngine()
 	return &AnimationSystem{
-		SpriteEngine: frame.NewEngine(),
+		SpriteEngine: se,
+		AnimatorEngine: frame.NewAnimatorEngine(se),
 		TweenEngine: ween.NewEngine(),
+		SkeletonEngine: spine.NewEngine(),
 	}
 }
 
 func (as *AnimationSystem) RequireTable(tables []interface{}) {
 	as.SpriteEngine.RequireTable(tables)
+	as.AnimatorEngine.RequireTable(tables)
+	as.SkeletonEngine.RequireTable(tables)
 
 	for _, t := range tables {
 		switch table := t.(type) {
@@ -37,7 +45,9 @@ func (as *AnimationSystem) RequireTable(tables []interface{}) {
 
 func (as *AnimationSystem) Update(dt float32) {
 	as.SpriteEngine.Update(dt)
+	as.AnimatorEngine.Update(dt)
 	as.TweenEngine.Update(dt)
+	as.SkeletonEngine.Update(dt)
 }
 
 // set shortcut
@@ -1,7 +1,10 @@
 package frame
 
 import (
+	"korok.io/korok/engi"
 	"korok.io/korok/gfx"
+
+	"log"
 )
 
 // implement sprite-animation system
@@ -13,10 +16,22 @@ type Animation struct {
 	Loop bool
 }
 
+// Frame of Sprite Animation, Duration is the seconds the frame shows,
+// the rate of FlipbookComp is used if it's 0. Event is sent to the
+// FlipbookComp when the frame is shown.
+type Frame struct {
+	Tex gfx.Tex2D
+	Duration float32
+	Event string
+}
+
 // Sprite Animation System
 type SpriteEngine struct {
 	// raw frames
 	frames []gfx.Tex2D
+	// per-frame duration and event
+	durations []float32
+	events []string
 	// raw animation
 	data []Animation
 	// mapping from name to index
@@ -25,6 +40,8 @@ type SpriteEngine struct {
 	// sprite and animate table
 	st *gfx.SpriteTable
 	at *FlipbookTable
+
+	listeners []FrameEventListener
 }
 
 func NewEngine() *SpriteEngine {
@@ -53,12 +70,58 @@ func (eng *SpriteEngine) NewAnimation(name string, frames []gfx.Tex2D, loop bool
 	// copy frames
 	start, size := len(eng.frames), len(frames)
 	eng.frames = append(eng.frames, frames...)
+	eng.durations = append(eng.durations, make([]float32, size)...)
+	eng.events = append(eng.events, make([]string, size)...)
 	// new animation
 	eng.data = append(eng.data, Animation{name, start, size, loop})
 	// keep mapping
 	eng.names[name] = len(eng.data)-1
 }
 
+// NewAnimationFrames creates animation with per-frame durations and events.
+func (eng *SpriteEngine) NewAnimationFrames(name string, frames []Frame, loop bool) {
+	start, size := len(eng.frames), len(frames)
+	for _, f := range frames {
+		eng.frames = append(eng.frames, f.Tex)
+		eng.durations = append(eng.durations, f.Duration)
+		eng.events = append(eng.events, f.Event)
+	}
+	eng.data = append(eng.data, Animation{name, start, size, loop})
+	eng.names[name] = len(eng.data)-1
+}
+
+// SetFrameDuration sets the duration of the frame, 0 to use the rate of FlipbookComp.
+func (eng *SpriteEngine) SetFrameDuration(name string, frame int, d float32) {
+	if i, ok := eng.frameAt(name, frame); ok {
+		eng.durations[i] = d
+	}
+}
+
+// SetFrameEvent sets the event of the frame, "" to remove it.
+func (eng *SpriteEngine) SetFrameEvent(name string, frame int, event string) {
+	if i, ok := eng.frameAt(name, frame); ok {
+		eng.events[i] = event
+	}
+}
+
+// FrameEvent returns the event of the frame.
+func (eng *SpriteEngine) FrameEvent(name string, frame int) string {
+	if i, ok := eng.frameAt(name, frame); ok {
+		return eng.events[i]
+	}
+	return ""
+}
+
+func (eng *SpriteEngine) frameAt(name string, frame int) (i int, ok bool) {
+	if ii, found := eng.names[name]; found {
+		if anim := &eng.data[ii]; frame >= 0 && frame < anim.Len {
+			return anim.Start+frame, true
+		}
+	}
+	log.Println("frame not found:", name, frame)
+	return
+}
+
 // 返回动画定义 - 好像并没有太大的意义
 func (eng *SpriteEngine) Animation(name string) (anim *Animation, seq []gfx.Tex2D) {
 	if ii, ok := eng.names[name]; ok {
@@ -81,32 +144,38 @@ func (eng *SpriteEngine) Update(dt float32) {
 				id    = eng.names[am.define]
 				data  = eng.data[id]
 			)
-			am.gfi = data.Start+int(am.frameIndex)
-			if am.dt += dt; am.dt > am.rate {
-				am.ii = am.ii + 1
-				am.dt = 0
-				frame := am.ii% data.Len
-
-				// frame end
-				if frame == 0 {
-					if am.loop && am.typ == PingPong {
-						am.reverse = !am.reverse
-						am.ii += 1 // skip one frame, or it'll repeat last frame
-						frame += 1
-					}
-					if !am.loop {
-						am.running = false
-						break
-					}
+			// first frame, the last one if play reversed
+			if am.start {
+				am.start = false
+				if am.reverse {
+					am.frameIndex = uint16(data.Len-1)
 				}
+				am.gfi = data.Start+int(am.frameIndex)
+				eng.fireEvent(am, am.gfi)
+			}
+			am.gfi = data.Start+int(am.frameIndex)
 
-				if am.reverse {
-					frame = data.Len-frame-1
+			// one update may pass several frames, the events of every
+			// frame are fired
+			am.dt += dt
+			for am.running {
+				d := eng.duration(am)
+				if am.dt <= d {
+					break
+				}
+				if d > 0 {
+					am.dt -= d
+				} else {
+					am.dt = 0
 				}
-				// update frame index
-				am.lastFrameIndex = am.frameIndex
-				am.frameIndex = uint16(frame)
-				am.gfi = data.Start+frame
+				eng.advance(am, &data)
+				if d <= 0 || am.start {
+					break
+				}
+			}
+			// stopped, or restarted by the callbacks
+			if !am.running || am.start {
+				continue
 			}
 
 			// update sprite-component
@@ -116,3 +185,80 @@ func (eng *SpriteEngine) Update(dt float32) {
 		}
 	}
 }
+
+// moves to the next frame, the callbacks are called after the state is
+// updated, so they can play another animation.
+func (eng *SpriteEngine) advance(am *FlipbookComp, data *Animation) {
+	am.ii = am.ii + 1
+	frame := am.ii% data.Len
+
+	// frame end
+	end := frame == 0
+	if end {
+		if am.loop && am.typ == PingPong && data.Len > 1 {
+			am.reverse = !am.reverse
+			am.ii += 1 // skip one frame, or it'll repeat last frame
+			frame += 1
+		}
+		if !am.loop {
+			am.running = false
+		}
+	}
+
+	if am.running {
+		if am.reverse {
+			frame = data.Len-frame-1
+		}
+		// update frame index
+		am.lastFrameIndex = am.frameIndex
+		am.frameIndex = uint16(frame)
+		am.gfi = data.Start+frame
+	}
+	if cb := am.completeCallback; end && cb != nil {
+		if cb(am.define); am.start || !am.running {
+			return
+		}
+	}
+	eng.fireEvent(am, am.gfi)
+}
+
+// duration of current frame
+func (eng *SpriteEngine) duration(am *FlipbookComp) float32 {
+	if d := eng.durations[am.gfi]; d > 0 {
+		return d
+	}
+	return am.rate
+}
+
+// sends the event of the frame to the callback of FlipbookComp and the
+// listeners
+func (eng *SpriteEngine) fireEvent(am *FlipbookComp, gfi int) {
+	event := eng.events[gfi]
+	if event == "" {
+		return
+	}
+	if cb := am.eventCallback; cb != nil {
+		cb(event, int(am.frameIndex))
+	}
+	for _, l := range eng.listeners {
+		l.OnFrameEvent(am.Entity, event)
+	}
+}
+
+// FrameEventListener receives the frame events of all the FlipbookComp.
+type FrameEventListener interface {
+	OnFrameEvent(entity engi.Entity, event string)
+}
+
+func (eng *SpriteEngine) AddFrameEventListener(l FrameEventListener) {
+	eng.listeners = append(eng.listeners, l)
+}
+
+func (eng *SpriteEngine) RemoveFrameEventListener(l FrameEventListener) {
+	for i, v := range eng.listeners {
+		if v == l {
+			eng.listeners = append(eng.listeners[:i], eng.listeners[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package frame
+
+import (
+	"testing"
+
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx"
+)
+
+func newTestFlipbook() (*SpriteEngine, *FlipbookComp) {
+	st := gfx.NewSpriteTable(4)
+	ft := NewFlipbookTable(4)
+	se := NewEngine()
+	se.RequireTable([]interface{}{st, ft})
+	se.NewAnimationFrames("attack", []Frame{
+		{Tex: testTex{}, Duration: .1},
+		{Tex: testTex{}, Duration: .3, Event: "hit"},
+		{Tex: testTex{}},
+	}, false)
+
+	e := engi.Entity(1)
+	st.NewComp(e)
+	fb := ft.NewComp(e)
+	fb.SetRate(.1)
+	return se, fb
+}
+
+func TestFrameDuration(t *testing.T) {
+	se, fb := newTestFlipbook()
+	var events []string
+	var frames []int
+	complete := 0
+	fb.OnEvent(func(event string, frame int) {
+		events = append(events, event)
+		frames = append(frames, frame)
+	})
+	fb.OnComplete(func(name string) {
+		if name == "attack" {
+			complete++
+		}
+	})
+	fb.Play("attack")
+
+	se.Update(.11)
+	if f, _ := fb.FrameIndex(); f != 1 || len(events) != 1 || frames[0] != 1 {
+		t.Fatalf("frame: %d, events: %v", f, events)
+	}
+	// the hit frame lasts .3s
+	se.Update(.11)
+	se.Update(.11)
+	if f, _ := fb.FrameIndex(); f != 1 {
+		t.Errorf("frame duration not used: %d", f)
+	}
+	se.Update(.11)
+	if f, _ := fb.FrameIndex(); f != 2 {
+		t.Errorf("frame: %d", f)
+	}
+	se.Update(.11)
+	if fb.Running() || complete != 1 {
+		t.Errorf("running: %v, complete: %d", fb.Running(), complete)
+	}
+	if f, _ := fb.FrameIndex(); f != 2 {
+		t.Errorf("last frame: %d", f)
+	}
+}
+
+func TestFrameReverse(t *testing.T) {
+	se, fb := newTestFlipbook()
+	se.SetFrameDuration("attack", 1, 0)
+	var frames []uint16
+	fb.SetReverse(true)
+	fb.SetLoop(true, PingPong)
+	fb.Play("attack")
+	for i := 0; i < 5; i++ {
+		se.Update(.11)
+		f, _ := fb.FrameIndex()
+		frames = append(frames, f)
+	}
+	expect := []uint16{1, 0, 1, 2, 1}
+	for i := range expect {
+		if frames[i] != expect[i] {
+			t.Fatalf("reverse ping-pong frames: %v", frames)
+		}
+	}
+}
+
+func TestCompleteChain(t *testing.T) {
+	se, fb := newTestFlipbook()
+	se.NewAnimation("idle", []gfx.Tex2D{testTex{}, testTex{}, testTex{}}, true)
+	se.SetFrameDuration("attack", 1, 0)
+
+	var complete []string
+	fb.OnComplete(func(name string) {
+		complete = append(complete, name)
+		if name == "attack" {
+			fb.Play("idle")
+		}
+	})
+	fb.Play("attack")
+	for i := 0; i < 3; i++ {
+		se.Update(.11)
+	}
+	if !fb.Running() || fb.Animation() != "idle" || len(complete) != 1 {
+		t.Fatalf("chain to idle: %v, %s, %v", fb.Running(), fb.Animation(), complete)
+	}
+	if loop, _ := fb.Loop(); loop {
+		t.Error("loop is not changed by Play")
+	}
+
+	// chains a looped animation
+	fb.SetLoop(true, PingPong)
+	fb.OnComplete(func(name string) {
+		complete = append(complete, name)
+		fb.Play("attack")
+	})
+	for i := 0; i < 3; i++ {
+		se.Update(.11)
+	}
+	if fb.Animation() != "attack" || len(complete) != 2 || complete[1] != "idle" {
+		t.Fatalf("chain from loop: %s, %v", fb.Animation(), complete)
+	}
+	if f, _ := fb.FrameIndex(); f != 0 {
+		t.Errorf("stale frame of idle is applied: %d", f)
+	}
+}
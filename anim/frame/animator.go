@@ -0,0 +1,511 @@
+package frame
+
+import (
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx"
+
+	"log"
+)
+
+// ParamType is the type of a parameter of the state machine.
+type ParamType uint8
+
+const (
+	ParamBool ParamType = iota
+	ParamFloat
+	// Trigger is a bool parameter which is reset after it's used by a
+	// transition.
+	ParamTrigger
+)
+
+// CompareOp compares a parameter with the value of Condition.
+type CompareOp uint8
+
+const (
+	// If is true if the bool or trigger is set
+	If CompareOp = iota
+	IfNot
+	Greater
+	Less
+	Equals
+	NotEqual
+)
+
+// Condition of Transition, a transition happens if all the conditions
+// are true.
+type Condition struct {
+	Param string
+	Op    CompareOp
+	Value float32
+}
+
+type condition struct {
+	param int
+	op    CompareOp
+	value float32
+}
+
+// AnyState is used as the source state of a transition which can happen
+// in any state.
+const AnyState = ""
+
+// Transition from one state to another.
+type Transition struct {
+	from, to   int
+	conditions []condition
+
+	// ExitOnComplete makes the transition wait until the animation of the
+	// source state completes(or completes a loop if looped).
+	ExitOnComplete bool
+}
+
+// State maps to a flipbook animation, the frame events are defined on
+// the frames of animation, see SpriteEngine.SetFrameEvent.
+type State struct {
+	Name      string
+	Animation string
+	Loop      bool
+	LoopType  LoopType
+
+	// seconds per frame, keeps the rate of FlipbookComp if it's 0
+	Rate float32
+}
+
+type param struct {
+	name  string
+	typ   ParamType
+	value float32
+}
+
+// StateMachine is the graph of states and transitions, it can be shared
+// by many AnimatorComp.
+type StateMachine struct {
+	states      []*State
+	transitions []*Transition
+	params      []param
+
+	// the default state, the first added state by default
+	entry int
+}
+
+func NewStateMachine() *StateMachine {
+	return &StateMachine{}
+}
+
+// AddState adds a new state which plays the animation.
+func (sm *StateMachine) AddState(name, animation string, loop bool) *State {
+	if i := sm.state(name); i >= 0 {
+		log.Println("state already exist:", name)
+		return sm.states[i]
+	}
+	s := &State{
+		Name:      name,
+		Animation: animation,
+		Loop:      loop,
+	}
+	sm.states = append(sm.states, s)
+	return s
+}
+
+// State returns the state with the name, or nil.
+func (sm *StateMachine) State(name string) *State {
+	if i := sm.state(name); i >= 0 {
+		return sm.states[i]
+	}
+	return nil
+}
+
+func (sm *StateMachine) state(name string) int {
+	for i := range sm.states {
+		if sm.states[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetEntry sets the default state.
+func (sm *StateMachine) SetEntry(name string) {
+	if i := sm.state(name); i >= 0 {
+		sm.entry = i
+	} else {
+		log.Println("state not found:", name)
+	}
+}
+
+func (sm *StateMachine) AddBool(name string, value bool) {
+	v := float32(0)
+	if value {
+		v = 1
+	}
+	sm.addParam(name, ParamBool, v)
+}
+
+func (sm *StateMachine) AddFloat(name string, value float32) {
+	sm.addParam(name, ParamFloat, value)
+}
+
+func (sm *StateMachine) AddTrigger(name string) {
+	sm.addParam(name, ParamTrigger, 0)
+}
+
+func (sm *StateMachine) addParam(name string, typ ParamType, value float32) {
+	if sm.param(name) >= 0 {
+		log.Println("param already exist:", name)
+		return
+	}
+	sm.params = append(sm.params, param{name, typ, value})
+}
+
+func (sm *StateMachine) param(name string) int {
+	for i := range sm.params {
+		if sm.params[i].name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// AddTransition adds a transition, from is AnyState if it can happen in
+// any state. Transitions are checked in the order they are added, and
+// transitions from AnyState are checked first.
+func (sm *StateMachine) AddTransition(from, to string, conds ...Condition) *Transition {
+	t := &Transition{from: -1}
+	if from != AnyState {
+		if t.from = sm.state(from); t.from < 0 {
+			log.Println("state not found:", from)
+			return nil
+		}
+	}
+	if t.to = sm.state(to); t.to < 0 {
+		log.Println("state not found:", to)
+		return nil
+	}
+	for _, c := range conds {
+		i := sm.param(c.Param)
+		if i < 0 {
+			log.Println("param not found:", c.Param)
+			return nil
+		}
+		t.conditions = append(t.conditions, condition{i, c.Op, c.Value})
+	}
+
+	// any state first
+	n := len(sm.transitions)
+	if t.from < 0 {
+		for n > 0 && sm.transitions[n-1].from >= 0 {
+			n--
+		}
+	}
+	sm.transitions = append(sm.transitions, nil)
+	copy(sm.transitions[n+1:], sm.transitions[n:])
+	sm.transitions[n] = t
+	return t
+}
+
+// AnimatorComp plays flipbook animations of the entity with a StateMachine,
+// the entity should have a FlipbookComp.
+type AnimatorComp struct {
+	engi.Entity
+	sm *StateMachine
+
+	// current state, -1 if not started
+	state  int
+	params []float32
+
+	// the state is changed, the animation is not played yet
+	dirty bool
+}
+
+// SetStateMachine sets the graph, the animator starts from the entry
+// state and the default values of the parameters.
+func (ac *AnimatorComp) SetStateMachine(sm *StateMachine) {
+	ac.sm = sm
+	ac.params = ac.params[:0]
+	for _, p := range sm.params {
+		ac.params = append(ac.params, p.value)
+	}
+	ac.state = -1
+	if len(sm.states) > 0 {
+		ac.enter(sm.entry)
+	}
+}
+
+func (ac *AnimatorComp) StateMachine() *StateMachine {
+	return ac.sm
+}
+
+// State returns the name of current state.
+func (ac *AnimatorComp) State() string {
+	if ac.sm != nil && ac.state >= 0 {
+		return ac.sm.states[ac.state].Name
+	}
+	return ""
+}
+
+// Play jumps to the state.
+func (ac *AnimatorComp) Play(state string) {
+	if ac.sm == nil {
+		return
+	}
+	if i := ac.sm.state(state); i >= 0 {
+		ac.enter(i)
+	} else {
+		log.Println("state not found:", state)
+	}
+}
+
+func (ac *AnimatorComp) enter(state int) {
+	ac.state = state
+	ac.dirty = true
+}
+
+func (ac *AnimatorComp) SetBool(name string, v bool) {
+	f := float32(0)
+	if v {
+		f = 1
+	}
+	ac.set(name, f)
+}
+
+func (ac *AnimatorComp) Bool(name string) bool {
+	return ac.get(name) != 0
+}
+
+func (ac *AnimatorComp) SetFloat(name string, v float32) {
+	ac.set(name, v)
+}
+
+func (ac *AnimatorComp) Float(name string) float32 {
+	return ac.get(name)
+}
+
+// SetTrigger sets the trigger, it's reset when a transition uses it.
+func (ac *AnimatorComp) SetTrigger(name string) {
+	ac.set(name, 1)
+}
+
+func (ac *AnimatorComp) ResetTrigger(name string) {
+	ac.set(name, 0)
+}
+
+func (ac *AnimatorComp) set(name string, v float32) {
+	if ac.sm == nil {
+		return
+	}
+	if i := ac.sm.param(name); i >= 0 {
+		ac.params[i] = v
+	} else {
+		log.Println("param not found:", name)
+	}
+}
+
+func (ac *AnimatorComp) get(name string) float32 {
+	if ac.sm == nil {
+		return 0
+	}
+	if i := ac.sm.param(name); i >= 0 {
+		return ac.params[i]
+	}
+	return 0
+}
+
+func (ac *AnimatorComp) check(t *Transition, complete bool) bool {
+	if t.ExitOnComplete && !complete {
+		return false
+	}
+	for _, c := range t.conditions {
+		v := ac.params[c.param]
+		var ok bool
+		switch c.op {
+		case If:
+			ok = v != 0
+		case IfNot:
+			ok = v == 0
+		case Greater:
+			ok = v > c.value
+		case Less:
+			ok = v < c.value
+		case Equals:
+			ok = v == c.value
+		case NotEqual:
+			ok = v != c.value
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// consumes the triggers used by the transition
+func (ac *AnimatorComp) consume(t *Transition) {
+	for _, c := range t.conditions {
+		if ac.sm.params[c.param].typ == ParamTrigger {
+			ac.params[c.param] = 0
+		}
+	}
+}
+
+// AnimatorTable
+type AnimatorTable struct {
+	comps      []AnimatorComp
+	_map       map[uint32]int
+	index, cap int
+}
+
+func NewAnimatorTable(cap int) *AnimatorTable {
+	return &AnimatorTable{
+		cap:  cap,
+		_map: make(map[uint32]int),
+	}
+}
+
+func (t *AnimatorTable) NewComp(entity engi.Entity) (ac *AnimatorComp) {
+	if size := len(t.comps); t.index >= size {
+		t.comps = animatorResize(t.comps, size+gfx.STEP)
+	}
+	ei := entity.Index()
+	if v, ok := t._map[ei]; ok {
+		ac = &t.comps[v]
+		return
+	}
+	ac = &t.comps[t.index]
+	*ac = AnimatorComp{Entity: entity, state: -1}
+	t._map[ei] = t.index
+	t.index++
+	return
+}
+
+func (t *AnimatorTable) Alive(entity engi.Entity) bool {
+	ei := entity.Index()
+	if v, ok := t._map[ei]; ok {
+		return t.comps[v].Entity == entity
+	}
+	return false
+}
+
+func (t *AnimatorTable) Comp(entity engi.Entity) (ac *AnimatorComp) {
+	ei := entity.Index()
+	if v, ok := t._map[ei]; ok {
+		ac = &t.comps[v]
+	}
+	return
+}
+
+func (t *AnimatorTable) Delete(entity engi.Entity) {
+	ei := entity.Index()
+	if v, ok := t._map[ei]; ok {
+		if tail := t.index - 1; v != tail && tail > 0 {
+			t.comps[v] = t.comps[tail]
+			// remap index
+			tComp := &t.comps[tail]
+			ei := tComp.Entity.Index()
+			t._map[ei] = v
+			*tComp = AnimatorComp{}
+		} else {
+			t.comps[tail] = AnimatorComp{}
+		}
+
+		t.index -= 1
+		delete(t._map, ei)
+	}
+}
+
+func (t *AnimatorTable) Size() (size, cap int) {
+	return t.index, t.cap
+}
+
+// EntityAt returns the entity of the i-th comp, i in [0, size)
+func (t *AnimatorTable) EntityAt(i int) engi.Entity {
+	return t.comps[i].Entity
+}
+
+func (t *AnimatorTable) Destroy() {
+	t.comps = make([]AnimatorComp, 0)
+	t._map = make(map[uint32]int)
+	t.index = 0
+}
+
+func animatorResize(slice []AnimatorComp, size int) []AnimatorComp {
+	newSlice := make([]AnimatorComp, size)
+	copy(newSlice, slice)
+	return newSlice
+}
+
+// AnimatorEngine runs the state machines of AnimatorComp, it should be
+// updated after the SpriteEngine.
+type AnimatorEngine struct {
+	eng *SpriteEngine
+	at  *AnimatorTable
+	ft  *FlipbookTable
+}
+
+func NewAnimatorEngine(eng *SpriteEngine) *AnimatorEngine {
+	return &AnimatorEngine{eng: eng}
+}
+
+func (ae *AnimatorEngine) RequireTable(tables []interface{}) {
+	for _, t := range tables {
+		switch table := t.(type) {
+		case *AnimatorTable:
+			ae.at = table
+		case *FlipbookTable:
+			ae.ft = table
+		}
+	}
+}
+
+func (ae *AnimatorEngine) Update(dt float32) {
+	if ae.at == nil || ae.ft == nil {
+		return
+	}
+	comps := ae.at.comps[:ae.at.index]
+	for i := range comps {
+		ac := &comps[i]
+		if ac.sm == nil || ac.state < 0 {
+			continue
+		}
+		fb := ae.ft.Comp(ac.Entity)
+		if fb == nil {
+			continue
+		}
+		if ac.dirty {
+			ae.play(ac, fb)
+			continue
+		}
+
+		state := ac.sm.states[ac.state]
+		anim, _ := ae.eng.Animation(state.Animation)
+		if anim == nil {
+			continue
+		}
+		complete := fb.ii >= anim.Len
+
+		// transitions
+		for j := range ac.sm.transitions {
+			t := ac.sm.transitions[j]
+			if t.from >= 0 && t.from != ac.state || t.from < 0 && t.to == ac.state {
+				continue
+			}
+			if ac.check(t, complete) {
+				ac.consume(t)
+				ac.enter(t.to)
+				ae.play(ac, fb)
+				break
+			}
+		}
+	}
+}
+
+// plays the animation of current state, the events of the first frame
+// are fired by SpriteEngine in the next update.
+func (ae *AnimatorEngine) play(ac *AnimatorComp, fb *FlipbookComp) {
+	state := ac.sm.states[ac.state]
+	fb.SetLoop(state.Loop, state.LoopType)
+	if state.Rate > 0 {
+		fb.SetRate(state.Rate)
+	}
+	fb.Play(state.Animation)
+	ac.dirty = false
+}
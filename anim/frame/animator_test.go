@@ -0,0 +1,165 @@
+package frame
+
+import (
+	"testing"
+
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx"
+)
+
+type testTex struct{}
+
+func (testTex) Tex() uint16        { return 0 }
+func (testTex) Region() gfx.Region { return gfx.Region{} }
+func (testTex) Size() gfx.Size     { return gfx.Size{} }
+
+type testFrameListener struct {
+	events []string
+}
+
+func (l *testFrameListener) OnFrameEvent(e engi.Entity, event string) {
+	l.events = append(l.events, event)
+}
+
+func newTestAnimator() (*SpriteEngine, *AnimatorEngine, *AnimatorComp, *FlipbookComp, *testFrameListener) {
+	st := gfx.NewSpriteTable(4)
+	ft := NewFlipbookTable(4)
+	at := NewAnimatorTable(4)
+	tables := []interface{}{st, ft, at}
+
+	se := NewEngine()
+	se.RequireTable(tables)
+	frames := []gfx.Tex2D{testTex{}, testTex{}, testTex{}}
+	se.NewAnimation("idle", frames, true)
+	se.NewAnimation("run", frames, true)
+	se.NewAnimation("jump", frames, false)
+	se.SetFrameEvent("run", 1, "footstep")
+	se.SetFrameEvent("jump", 0, "takeoff")
+
+	ae := NewAnimatorEngine(se)
+	ae.RequireTable(tables)
+	l := &testFrameListener{}
+	se.AddFrameEventListener(l)
+
+	sm := NewStateMachine()
+	sm.AddFloat("speed", 0)
+	sm.AddTrigger("jump")
+	sm.AddState("idle", "idle", true).Rate = .1
+	sm.AddState("run", "run", true).Rate = .1
+	sm.AddState("jump", "jump", false).Rate = .1
+	sm.AddTransition("idle", "run", Condition{"speed", Greater, .1})
+	sm.AddTransition("run", "idle", Condition{"speed", Less, .1})
+	sm.AddTransition(AnyState, "jump", Condition{Param: "jump", Op: If})
+	sm.AddTransition("jump", "idle").ExitOnComplete = true
+
+	e := engi.Entity(1)
+	st.NewComp(e)
+	fb := ft.NewComp(e)
+	ac := at.NewComp(e)
+	ac.SetStateMachine(sm)
+	return se, ae, ac, fb, l
+}
+
+func TestAnimatorTransition(t *testing.T) {
+	se, ae, ac, fb, l := newTestAnimator()
+	update := func() {
+		se.Update(.11)
+		ae.Update(.11)
+	}
+
+	update()
+	if ac.State() != "idle" || fb.Animation() != "idle" {
+		t.Fatalf("entry state: %s, %s", ac.State(), fb.Animation())
+	}
+
+	ac.SetFloat("speed", 1)
+	update()
+	if ac.State() != "run" || fb.Animation() != "run" {
+		t.Fatalf("run state: %s", ac.State())
+	}
+	update()
+	if len(l.events) != 1 || l.events[0] != "footstep" {
+		t.Errorf("frame events: %v", l.events)
+	}
+
+	ac.SetTrigger("jump")
+	update()
+	if ac.State() != "jump" {
+		t.Fatalf("jump state: %s", ac.State())
+	}
+	if ac.Bool("jump") {
+		t.Error("trigger is not consumed")
+	}
+
+	// exit on complete, then idle -> run by speed
+	ac.SetFloat("speed", 0)
+	for i := 0; i < 2; i++ {
+		update()
+		if ac.State() != "jump" {
+			t.Fatalf("exit before complete: %d", i)
+		}
+	}
+	if len(l.events) != 2 || l.events[1] != "takeoff" {
+		t.Errorf("frame events: %v", l.events)
+	}
+	update()
+	if ac.State() != "idle" {
+		t.Errorf("exit on complete: %s", ac.State())
+	}
+}
+
+func TestAnimatorPlay(t *testing.T) {
+	se, ae, ac, fb, _ := newTestAnimator()
+	ac.Play("jump")
+	se.Update(.11)
+	ae.Update(.11)
+	if ac.State() != "jump" || fb.Animation() != "jump" {
+		t.Errorf("play: %s, %s", ac.State(), fb.Animation())
+	}
+	if loop, _ := fb.Loop(); loop {
+		t.Error("jump should not loop")
+	}
+}
+
+func TestStateMachineBuild(t *testing.T) {
+	sm := NewStateMachine()
+	sm.AddTrigger("hit")
+	idle := sm.AddState("idle", "idle", true)
+	names := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	for _, n := range names {
+		sm.AddState(n, n, false)
+	}
+	idle.Rate = .2
+	if s := sm.State("idle"); s.Rate != .2 {
+		t.Errorf("state is not configured: %v", s.Rate)
+	}
+
+	tr := sm.AddTransition("a", "idle")
+	for _, n := range names {
+		sm.AddTransition(AnyState, n, Condition{Param: "hit", Op: If})
+	}
+	tr.ExitOnComplete = true
+	for _, v := range sm.transitions {
+		if v.from >= 0 && !v.ExitOnComplete {
+			t.Error("transition is not configured")
+		}
+	}
+}
+
+func TestAnimatorSkippedFrameEvents(t *testing.T) {
+	se, ae, ac, _, l := newTestAnimator()
+	se.SetFrameEvent("run", 2, "land")
+	ac.SetFloat("speed", 1)
+	ac.Play("run")
+	se.Update(.01)
+	ae.Update(.01)
+	se.Update(.01)
+	ae.Update(.01)
+
+	// passes 3 frames in one update
+	se.Update(.35)
+	ae.Update(.35)
+	if len(l.events) != 2 || l.events[0] != "footstep" || l.events[1] != "land" {
+		t.Errorf("events of skipped frames: %v", l.events)
+	}
+}
@@ -13,6 +13,14 @@ const (
 	None
 )
 
+// EventCallback is called when a frame with event is shown.
+type EventCallback func(event string, frame int)
+
+// CompleteCallback is called when the animation reaches the end, it's
+// called every loop if the animation is looped. Play can be called in
+// the callback to chain animations.
+type CompleteCallback func(name string)
+
 // Sprite Animation Component
 type FlipbookComp struct {
 	engi.Entity
@@ -25,12 +33,19 @@ type FlipbookComp struct {
 	gfi                        int
 	typ                        LoopType
 	reverse                    bool
+	// play from the last frame
+	backward                   bool
+	start                      bool
+
+	eventCallback    EventCallback
+	completeCallback CompleteCallback
 }
 
 func (fb *FlipbookComp) Play(name string) {
 	fb.define = name
 	fb.running = true
-	fb.reverse = false
+	fb.start = true
+	fb.reverse = fb.backward
 	fb.frameIndex = 0
 	fb.lastFrameIndex = 0
 	fb.dt, fb.ii = 0, 0
@@ -56,6 +71,10 @@ func (fb *FlipbookComp) SetAnimation(name string) {
 	fb.define = name
 }
 
+func (fb *FlipbookComp) Animation() string {
+	return fb.define
+}
+
 func (fb *FlipbookComp) Loop() (bool, LoopType) {
 	return fb.loop, fb.typ
 }
@@ -72,6 +91,26 @@ func (fb *FlipbookComp) SetRate(r float32) {
 	fb.rate = r
 }
 
+// SetReverse plays the animation from the last frame to the first, it
+// takes effect the next time Play is called.
+func (fb *FlipbookComp) SetReverse(v bool) {
+	fb.backward = v
+}
+
+func (fb *FlipbookComp) Reverse() bool {
+	return fb.backward
+}
+
+// OnEvent sets the callback of frame events.
+func (fb *FlipbookComp) OnEvent(cb EventCallback) {
+	fb.eventCallback = cb
+}
+
+// OnComplete sets the callback of animation end.
+func (fb *FlipbookComp) OnComplete(cb CompleteCallback) {
+	fb.completeCallback = cb
+}
+
 func (fb *FlipbookComp) FrameIndex() (frame, lastFrame uint16) {
 	return fb.frameIndex, fb.lastFrameIndex
 }
@@ -100,7 +139,7 @@ func (t *FlipbookTable) NewComp(entity engi.Entity) (am *FlipbookComp) {
 		return
 	}
 	am = &t.comps[t.index]
-	am.Entity = entity
+	*am = FlipbookComp{Entity: entity}
 	t._map[ei] = t.index
 	t.index ++
 	return
@@ -109,7 +148,7 @@ func (t *FlipbookTable) NewComp(entity engi.Entity) (am *FlipbookComp) {
 func (t *FlipbookTable) Alive(entity engi.Entity) bool {
 	ei := entity.Index()
 	if v, ok := t._map[ei]; ok {
-		return t.comps[v].Entity != 0
+		return t.comps[v].Entity == entity
 	}
 	return false
 }
@@ -131,9 +170,9 @@ func (t *FlipbookTable) Delete(entity engi.Entity) {
 			tComp := &t.comps[tail]
 			ei := tComp.Entity.Index()
 			t._map[ei] = v
-			tComp.Entity = 0
+			*tComp = FlipbookComp{}
 		} else {
-			t.comps[tail].Entity = 0
+			t.comps[tail] = FlipbookComp{}
 		}
 
 		t.index -= 1
@@ -145,6 +184,11 @@ func (t *FlipbookTable) Size() (size, cap int) {
 	return t.index, t.cap
 }
 
+// EntityAt returns the entity of the i-th comp, i in [0, size)
+func (t *FlipbookTable) EntityAt(i int) engi.Entity {
+	return t.comps[i].Entity
+}
+
 func (t *FlipbookTable) Destroy() {
 	t.comps = make([]FlipbookComp, 0)
 	t._map = make(map[uint32]int)
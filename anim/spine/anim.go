@@ -306,6 +306,187 @@ func (t *AttachmentTimeline) Apply(skeleton *Skeleton, time, alpha float32) {
 	skeleton.Slots[t.slotIndex].Attachment = attachment
 }
 
+// FfdTimeline deforms the vertices of a mesh attachment.
+type FfdTimeline struct {
+	slotIndex     int
+	attachment    Attachment
+	frames        []float32
+	frameVertices [][]float32
+	curve         *Curve
+}
+
+func NewFfdTimeline(l int) *FfdTimeline {
+	return &FfdTimeline{
+		frames:        make([]float32, l),
+		frameVertices: make([][]float32, l),
+		curve:         NewCurve(l),
+	}
+}
+
+func (t *FfdTimeline) frameCount() int {
+	return len(t.frames)
+}
+
+func (t *FfdTimeline) setFrame(index int, time float32, vertices []float32) {
+	t.frames[index] = time
+	t.frameVertices[index] = vertices
+}
+
+func (t *FfdTimeline) Apply(skeleton *Skeleton, time, alpha float32) {
+	slot := skeleton.Slots[t.slotIndex]
+	if slot.Attachment != t.attachment {
+		return
+	}
+	frames := t.frames
+	if time < frames[0] {
+		return // Time is before first frame.
+	}
+
+	vertexCount := len(t.frameVertices[0])
+	if len(slot.AttachmentVertices) != vertexCount {
+		alpha = 1 // Don't mix from uninitialized slot vertices.
+	}
+	if cap(slot.AttachmentVertices) < vertexCount {
+		slot.AttachmentVertices = make([]float32, vertexCount)
+	}
+	vertices := slot.AttachmentVertices[:vertexCount]
+	slot.AttachmentVertices = vertices
+
+	if time >= frames[len(frames)-1] { // Time is after last frame.
+		last := t.frameVertices[len(frames)-1]
+		if alpha < 1 {
+			for i, v := range last {
+				vertices[i] += (v - vertices[i]) * alpha
+			}
+		} else {
+			copy(vertices, last)
+		}
+		return
+	}
+
+	// Interpolate between the previous frame and the current frame.
+	frameIndex := binarySearch(frames, time, 1)
+	frameTime := frames[frameIndex]
+	percent := 1 - (time-frameTime)/(frames[frameIndex-1]-frameTime)
+	percent = t.curve.CurvePercent(frameIndex-1, percent)
+
+	prev := t.frameVertices[frameIndex-1]
+	next := t.frameVertices[frameIndex]
+	if alpha < 1 {
+		for i := range vertices {
+			v := prev[i] + (next[i]-prev[i])*percent
+			vertices[i] += (v - vertices[i]) * alpha
+		}
+	} else {
+		for i := range vertices {
+			vertices[i] = prev[i] + (next[i]-prev[i])*percent
+		}
+	}
+}
+
+// DrawOrderTimeline changes the draw order of slots.
+type DrawOrderTimeline struct {
+	frames     []float32
+	drawOrders [][]int
+}
+
+func NewDrawOrderTimeline(l int) *DrawOrderTimeline {
+	return &DrawOrderTimeline{
+		frames:     make([]float32, l),
+		drawOrders: make([][]int, l),
+	}
+}
+
+func (t *DrawOrderTimeline) frameCount() int {
+	return len(t.frames)
+}
+
+// drawOrder is the setup index of the slots, nil means the setup order
+func (t *DrawOrderTimeline) setFrame(index int, time float32, drawOrder []int) {
+	t.frames[index] = time
+	t.drawOrders[index] = drawOrder
+}
+
+func (t *DrawOrderTimeline) Apply(skeleton *Skeleton, time, alpha float32) {
+	frames := t.frames
+	if time < frames[0] {
+		return // Time is before first frame.
+	}
+
+	var frameIndex int
+	if time >= frames[len(frames)-1] { // Time is after last frame.
+		frameIndex = len(frames) - 1
+	} else {
+		frameIndex = binarySearch(frames, time, 1) - 1
+	}
+
+	drawOrder, slots := skeleton.DrawOrder, skeleton.Slots
+	if order := t.drawOrders[frameIndex]; order == nil {
+		copy(drawOrder, slots)
+	} else {
+		for i, setupIndex := range order {
+			drawOrder[i] = slots[setupIndex]
+		}
+	}
+}
+
+// EventTimeline fires events, it doesn't change the skeleton.
+type EventTimeline struct {
+	frames []float32
+	events []*Event
+}
+
+func NewEventTimeline(l int) *EventTimeline {
+	return &EventTimeline{
+		frames: make([]float32, l),
+		events: make([]*Event, l),
+	}
+}
+
+func (t *EventTimeline) frameCount() int {
+	return len(t.frames)
+}
+
+func (t *EventTimeline) setFrame(index int, event *Event) {
+	t.frames[index] = event.Time
+	t.events[index] = event
+}
+
+func (t *EventTimeline) Apply(skeleton *Skeleton, time, alpha float32) {
+}
+
+// Fire appends the events in (lastTime, time] to fired.
+func (t *EventTimeline) Fire(lastTime, time float32, fired []*Event) []*Event {
+	frames := t.frames
+	n := len(frames)
+	if lastTime > time { // Fire events after last time for looped animations.
+		fired = t.Fire(lastTime, float32(math.MaxFloat32), fired)
+		lastTime = -1
+	} else if lastTime >= frames[n-1] { // Last time is after last frame.
+		return fired
+	}
+	if time < frames[0] {
+		return fired // Time is before first frame.
+	}
+
+	var frameIndex int
+	if lastTime < frames[0] {
+		frameIndex = 0
+	} else {
+		frameIndex = binarySearch(frames, lastTime, 1)
+		// Fire multiple events with the same frame.
+		for frameTime := frames[frameIndex]; frameIndex > 0; frameIndex-- {
+			if frames[frameIndex-1] != frameTime {
+				break
+			}
+		}
+	}
+	for ; frameIndex < n && time >= frames[frameIndex]; frameIndex++ {
+		fired = append(fired, t.events[frameIndex])
+	}
+	return fired
+}
+
 type Animation struct {
 	name      string
 	timelines []Timeline
@@ -339,6 +520,27 @@ func (a *Animation) Mix(skeleton *Skeleton, time float32, loop bool, alpha float
 	}
 }
 
+// FireEvents appends the events in (lastTime, time] to fired.
+func (a *Animation) FireEvents(lastTime, time float32, loop bool, fired []*Event) []*Event {
+	if loop && a.duration != 0 {
+		time = float32(math.Mod(float64(time), float64(a.duration)))
+		// negative last time means the events at time 0 are not fired yet
+		if lastTime >= 0 {
+			lastTime = float32(math.Mod(float64(lastTime), float64(a.duration)))
+		}
+	}
+	for _, timeline := range a.timelines {
+		if et, ok := timeline.(*EventTimeline); ok {
+			fired = et.Fire(lastTime, time, fired)
+		}
+	}
+	return fired
+}
+
 func (a *Animation) Duration() float32 {
 	return a.duration
 }
+
+func (a *Animation) Name() string {
+	return a.name
+}
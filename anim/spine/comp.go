@@ -0,0 +1,281 @@
+package spine
+
+import (
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx"
+)
+
+// SkeletonComp renders a spine skeleton at the position of the entity.
+// Animations are played by the AnimationState, Play and PlayMix play the
+// animation in track 0.
+type SkeletonComp struct {
+	engi.Entity
+	skeleton *Skeleton
+	state    *AnimationState
+
+	zOrder  int16
+	visible bool
+
+	timeScale float32
+
+	// default mix duration
+	mix float32
+
+	// local bounds of the attachments, updated by SkeletonEngine
+	bounds struct {
+		x, y, w, h float32
+	}
+	vertices []float32
+	hasMesh  bool
+}
+
+// SetSkeleton creates a new Skeleton from the data, the skeleton is in
+// setup pose.
+func (sc *SkeletonComp) SetSkeleton(data *SkeletonData) {
+	sk := NewSkeleton(data)
+	if data.defaultSkin != nil {
+		sk.SetSkin(data.defaultSkin)
+	}
+	sk.SetToSetupPose()
+	sk.UpdateWorldTransform()
+
+	sc.skeleton = sk
+	sc.state = NewAnimationState(NewAnimationStateData(data))
+	sc.state.Data.DefaultMix = sc.mix
+}
+
+func (sc *SkeletonComp) Skeleton() *Skeleton {
+	return sc.skeleton
+}
+
+// State returns the AnimationState, it's used to play animations in
+// multiple tracks, set mix durations and listen the animation events.
+func (sc *SkeletonComp) State() *AnimationState {
+	return sc.state
+}
+
+// Play starts the named animation, mixes from the current animation if
+// the mix duration is not zero.
+func (sc *SkeletonComp) Play(name string, loop bool) {
+	if sc.state == nil {
+		return
+	}
+	if sc.state.Empty() {
+		sc.skeleton.SetToSetupPose()
+	}
+	sc.state.SetAnimation(0, name, loop)
+}
+
+// PlayMix starts the named animation with the mix duration.
+func (sc *SkeletonComp) PlayMix(name string, loop bool, mix float32) {
+	if sc.state == nil {
+		return
+	}
+	if sc.state.Empty() {
+		sc.skeleton.SetToSetupPose()
+	}
+	last := sc.state.Current(0)
+	if entry := sc.state.SetAnimation(0, name, loop); entry != nil {
+		entry.MixTime, entry.MixDuration = 0, mix
+		if mix <= 0 {
+			entry.previous = nil
+		} else if entry.previous == nil {
+			entry.previous = last
+		}
+	}
+}
+
+// Stop stops the animation, the skeleton keeps the current pose.
+func (sc *SkeletonComp) Stop() {
+	if sc.state != nil {
+		sc.state.ClearTracks()
+	}
+}
+
+// Animation returns the name of current animation.
+func (sc *SkeletonComp) Animation() string {
+	if e := sc.current(); e != nil {
+		return e.Animation.name
+	}
+	return ""
+}
+
+func (sc *SkeletonComp) current() *TrackEntry {
+	if sc.state != nil {
+		return sc.state.Current(0)
+	}
+	return nil
+}
+
+// Events returns the events fired in this frame.
+func (sc *SkeletonComp) Events() []*Event {
+	if sc.state != nil {
+		return sc.state.Events()
+	}
+	return nil
+}
+
+// Complete returns true if the animation is not looped and reaches the end.
+func (sc *SkeletonComp) Complete() bool {
+	e := sc.current()
+	return e != nil && !e.Loop && e.Complete()
+}
+
+func (sc *SkeletonComp) Time() float32 {
+	if e := sc.current(); e != nil {
+		return e.Time
+	}
+	return 0
+}
+
+func (sc *SkeletonComp) SetTime(t float32) {
+	if e := sc.current(); e != nil {
+		e.Time, e.LastTime = t, t
+	}
+}
+
+func (sc *SkeletonComp) SetMix(duration float32) {
+	sc.mix = duration
+	if sc.state != nil {
+		sc.state.Data.DefaultMix = duration
+	}
+}
+
+func (sc *SkeletonComp) Mix() float32 {
+	return sc.mix
+}
+
+func (sc *SkeletonComp) SetTimeScale(scale float32) {
+	sc.timeScale = scale
+}
+
+func (sc *SkeletonComp) TimeScale() float32 {
+	return sc.timeScale
+}
+
+func (sc *SkeletonComp) SetSkin(name string) {
+	if sc.skeleton != nil {
+		sc.skeleton.SetSkinByName(name)
+	}
+}
+
+func (sc *SkeletonComp) SetFlip(flipX, flipY bool) {
+	if sc.skeleton != nil {
+		sc.skeleton.FlipX, sc.skeleton.FlipY = flipX, flipY
+	}
+}
+
+func (sc *SkeletonComp) SetZOrder(z int16) {
+	sc.zOrder = z
+}
+
+func (sc *SkeletonComp) ZOrder() int16 {
+	return sc.zOrder
+}
+
+func (sc *SkeletonComp) SetVisible(v bool) {
+	sc.visible = v
+}
+
+func (sc *SkeletonComp) Visible() bool {
+	return sc.visible
+}
+
+// Bounds returns the local bounds of the attachments.
+func (sc *SkeletonComp) Bounds() (x, y, w, h float32) {
+	b := sc.bounds
+	return b.x, b.y, b.w, b.h
+}
+
+// SkeletonTable
+type SkeletonTable struct {
+	comps      []SkeletonComp
+	_map       map[uint32]int
+	index, cap int
+}
+
+func NewSkeletonTable(cap int) *SkeletonTable {
+	return &SkeletonTable{
+		cap:  cap,
+		_map: make(map[uint32]int),
+	}
+}
+
+func (t *SkeletonTable) NewComp(entity engi.Entity) (sc *SkeletonComp) {
+	if size := len(t.comps); t.index >= size {
+		t.comps = skeletonResize(t.comps, size+gfx.STEP)
+	}
+	ei := entity.Index()
+	if v, ok := t._map[ei]; ok {
+		sc = &t.comps[v]
+		return
+	}
+	sc = &t.comps[t.index]
+	*sc = SkeletonComp{Entity: entity, visible: true, timeScale: 1}
+	t._map[ei] = t.index
+	t.index++
+	return
+}
+
+// NewCompX creates a new SkeletonComp with the skeleton data.
+func (t *SkeletonTable) NewCompX(entity engi.Entity, data *SkeletonData) (sc *SkeletonComp) {
+	sc = t.NewComp(entity)
+	sc.SetSkeleton(data)
+	return
+}
+
+func (t *SkeletonTable) Alive(entity engi.Entity) bool {
+	ei := entity.Index()
+	if v, ok := t._map[ei]; ok {
+		return t.comps[v].Entity == entity
+	}
+	return false
+}
+
+func (t *SkeletonTable) Comp(entity engi.Entity) (sc *SkeletonComp) {
+	ei := entity.Index()
+	if v, ok := t._map[ei]; ok {
+		sc = &t.comps[v]
+	}
+	return
+}
+
+func (t *SkeletonTable) Delete(entity engi.Entity) {
+	ei := entity.Index()
+	if v, ok := t._map[ei]; ok {
+		if tail := t.index - 1; v != tail && tail > 0 {
+			t.comps[v] = t.comps[tail]
+			// remap index
+			tComp := &t.comps[tail]
+			ei := tComp.Entity.Index()
+			t._map[ei] = v
+			*tComp = SkeletonComp{}
+		} else {
+			t.comps[tail] = SkeletonComp{}
+		}
+
+		t.index -= 1
+		delete(t._map, ei)
+	}
+}
+
+func (t *SkeletonTable) Size() (size, cap int) {
+	return t.index, t.cap
+}
+
+// EntityAt returns the entity of the i-th comp, i in [0, size)
+func (t *SkeletonTable) EntityAt(i int) engi.Entity {
+	return t.comps[i].Entity
+}
+
+func (t *SkeletonTable) Destroy() {
+	t.comps = make([]SkeletonComp, 0)
+	t._map = make(map[uint32]int)
+	t.index = 0
+}
+
+func skeletonResize(slice []SkeletonComp, size int) []SkeletonComp {
+	newSlice := make([]SkeletonComp, size)
+	copy(newSlice, slice)
+	return newSlice
+}
@@ -0,0 +1,114 @@
+package spine
+
+import (
+	"strings"
+	"testing"
+
+	"korok.io/korok/engi"
+)
+
+const testAtlas = `
+test.png
+format: RGBA8888
+size: 64,64
+filter: Linear,Linear
+repeat: none
+head
+  rotate: false
+  xy: 0, 0
+  size: 32, 32
+  orig: 32, 32
+  offset: 0, 0
+  index: -1
+`
+
+const testSkeleton = `{
+"bones": [
+	{"name": "root"},
+	{"name": "arm", "parent": "root", "x": 10}
+],
+"slots": [
+	{"name": "head", "bone": "arm", "attachment": "head"}
+],
+"skins": {
+	"default": {"head": {"head": {"width": 32, "height": 32}}}
+},
+"animations": {
+	"idle": {"bones": {"arm": {"translate": [{"time": 0, "x": 0, "y": 0}, {"time": 1, "x": 0, "y": 0}]}}},
+	"walk": {"bones": {"arm": {"translate": [{"time": 0, "x": 100, "y": 0}, {"time": 1, "x": 100, "y": 0}]}}}
+}
+}`
+
+type testTextureLoader struct{}
+
+func (testTextureLoader) Load(page *AtlasPage) error {
+	page.RendererObject = uint16(1)
+	return nil
+}
+
+func (testTextureLoader) Unload(page *AtlasPage) error {
+	return nil
+}
+
+func newTestData(t *testing.T) *SkeletonData {
+	atlas, err := NewAtlas(strings.NewReader(testAtlas), testTextureLoader{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := New(strings.NewReader(testSkeleton), 1, AtlasAttachmentLoader{atlas})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestSkeletonMix(t *testing.T) {
+	st := NewSkeletonTable(8)
+	eng := NewEngine()
+	eng.RequireTable([]interface{}{st})
+
+	e := engi.Entity(1)
+	sc := st.NewCompX(e, newTestData(t))
+	sc.Play("idle", true)
+	eng.Update(.1)
+
+	_, arm := sc.Skeleton().FindBone("arm")
+	if arm.X != 10 {
+		t.Errorf("idle pose: %f", arm.X)
+	}
+	if x, y, w, h := sc.Bounds(); x != -6 || y != -16 || w != 32 || h != 32 {
+		t.Errorf("bounds: %f, %f, %f, %f", x, y, w, h)
+	}
+
+	sc.PlayMix("walk", true, .4)
+	eng.Update(.2)
+	if arm.X != 60 {
+		t.Errorf("half mixed pose: %f", arm.X)
+	}
+	eng.Update(.3)
+	if arm.X != 110 || sc.Animation() != "walk" || sc.State().Current(0).previous != nil {
+		t.Errorf("mix should be done: %f", arm.X)
+	}
+}
+
+func TestSkeletonComplete(t *testing.T) {
+	st := NewSkeletonTable(8)
+	eng := NewEngine()
+	eng.RequireTable([]interface{}{st})
+
+	sc := st.NewCompX(engi.Entity(1), newTestData(t))
+	sc.Play("walk", false)
+	eng.Update(.5)
+	if sc.Complete() {
+		t.Error("animation should not complete")
+	}
+	eng.Update(.6)
+	if !sc.Complete() {
+		t.Error("animation should complete")
+	}
+
+	st.Delete(engi.Entity(1))
+	if st.Alive(engi.Entity(1)) {
+		t.Error("comp should be deleted")
+	}
+}
@@ -0,0 +1,93 @@
+package spine
+
+// SkeletonEngine advances the animations of SkeletonComp.
+type SkeletonEngine struct {
+	st *SkeletonTable
+}
+
+func NewEngine() *SkeletonEngine {
+	return &SkeletonEngine{}
+}
+
+func (eng *SkeletonEngine) RequireTable(tables []interface{}) {
+	for _, t := range tables {
+		switch table := t.(type) {
+		case *SkeletonTable:
+			eng.st = table
+		}
+	}
+}
+
+func (eng *SkeletonEngine) Update(dt float32) {
+	if eng.st == nil {
+		return
+	}
+	comps := eng.st.comps[:eng.st.index]
+	for i := range comps {
+		if sc := &comps[i]; sc.skeleton != nil {
+			sc.update(dt)
+		}
+	}
+}
+
+func (sc *SkeletonComp) update(dt float32) {
+	sk := sc.skeleton
+	dt *= sc.timeScale
+	sk.Update(dt)
+
+	if state := sc.state; state.Empty() {
+		state.events = state.events[:0]
+	} else {
+		state.Update(dt)
+
+		// bones not keyed by the animations stay in setup pose
+		sk.setBonesToSetupPose()
+		state.Apply(sk)
+	}
+	sk.UpdateWorldTransform()
+	sc.updateBounds()
+}
+
+// bounds of the attachments, relative to the skeleton origin
+func (sc *SkeletonComp) updateBounds() {
+	var (
+		sk                     = sc.skeleton
+		minX, minY, maxX, maxY float32
+		first                  = true
+		verts                  []float32
+	)
+	sc.hasMesh = false
+	for _, slot := range sk.DrawOrder {
+		switch attachment := slot.Attachment.(type) {
+		case *RegionAttachment:
+			quad := attachment.Update(slot)
+			verts = append(sc.vertices[:0], quad[:]...)
+		case *MeshAttachment:
+			verts = attachment.ComputeWorldVertices(slot, sc.vertices)
+			sc.hasMesh = true
+		default:
+			continue
+		}
+		sc.vertices = verts
+		for i := 0; i < len(verts); i += 2 {
+			x, y := verts[i]-sk.X, verts[i+1]-sk.Y
+			if first {
+				minX, minY, maxX, maxY = x, y, x, y
+				first = false
+				continue
+			}
+			if x < minX {
+				minX = x
+			} else if x > maxX {
+				maxX = x
+			}
+			if y < minY {
+				minY = y
+			} else if y > maxY {
+				maxY = y
+			}
+		}
+	}
+	b := &sc.bounds
+	b.x, b.y, b.w, b.h = minX, minY, maxX-minX, maxY-minY
+}
@@ -0,0 +1,31 @@
+package spine
+
+// EventData is the setup values of an event.
+type EventData struct {
+	name   string
+	Int    int
+	Float  float32
+	String string
+}
+
+func NewEventData(name string) *EventData {
+	return &EventData{name: name}
+}
+
+func (e *EventData) Name() string {
+	return e.name
+}
+
+// Event is a keyframe of EventTimeline, values are from the EventData if
+// not keyed.
+type Event struct {
+	Data   *EventData
+	Time   float32
+	Int    int
+	Float  float32
+	String string
+}
+
+func (e *Event) Name() string {
+	return e.Data.name
+}
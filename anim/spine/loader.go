@@ -0,0 +1,61 @@
+package spine
+
+import (
+	"korok.io/korok/asset"
+	"korok.io/korok/asset/res"
+	"korok.io/korok/gfx/bk"
+
+	"errors"
+	"path"
+)
+
+// TextureManager loads the atlas pages with asset.Texture, the texture id
+// is stored in AtlasPage.RendererObject.
+type TextureManager struct {
+	// directory of the page images
+	Dir string
+}
+
+func (tm TextureManager) Load(page *AtlasPage) error {
+	file := path.Join(tm.Dir, page.Name)
+	asset.Texture.Load(file)
+	id, _ := asset.Texture.GetRaw(file)
+	if id == bk.InvalidId {
+		return errors.New("spine: failed to load texture: " + file)
+	}
+	page.RendererObject = id
+	return nil
+}
+
+func (tm TextureManager) Unload(page *AtlasPage) error {
+	asset.Texture.Unload(path.Join(tm.Dir, page.Name))
+	page.RendererObject = nil
+	return nil
+}
+
+// Load loads the atlas and the skeleton json file, page images are in the
+// same directory as the atlas file.
+func Load(atlasFile, skeletonFile string, scale float32) (*SkeletonData, *Atlas, error) {
+	af, err := res.Open(atlasFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer af.Close()
+	atlas, err := NewAtlas(af, TextureManager{path.Dir(atlasFile)})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sf, err := res.Open(skeletonFile)
+	if err != nil {
+		atlas.Dispose()
+		return nil, nil, err
+	}
+	defer sf.Close()
+	data, err := New(sf, scale, AtlasAttachmentLoader{atlas})
+	if err != nil {
+		atlas.Dispose()
+		return nil, nil, err
+	}
+	return data, atlas, nil
+}
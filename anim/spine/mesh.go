@@ -0,0 +1,137 @@
+package spine
+
+// MeshAttachment is a textured triangle mesh. The vertices of a weighted
+// mesh are bound to multiple bones, the format is:
+//
+//	Bones:   [boneCount, boneIndex...] for each vertex
+//	Weights: [x, y, weight] for each bone of the vertex
+type MeshAttachment struct {
+	name string
+
+	// unweighted vertices, x,y pairs in bone space
+	Vertices []float32
+
+	// weighted vertices
+	Bones   []int
+	Weights []float32
+
+	Triangles  []uint16
+	RegionUVs  []float32
+	Uvs        []float32
+	HullLength int
+
+	R, G, B, A    float32
+	Width, Height float32
+
+	RendererObject                       interface{}
+	RegionU, RegionV, RegionU2, RegionV2 float32
+	RegionRotate                         bool
+}
+
+func NewMeshAttachment(name string) *MeshAttachment {
+	return &MeshAttachment{
+		name: name,
+		R:    1, G: 1, B: 1, A: 1,
+	}
+}
+
+func (m *MeshAttachment) Name() string {
+	return m.name
+}
+
+// Weighted returns true if the vertices are bound to multiple bones.
+func (m *MeshAttachment) Weighted() bool {
+	return len(m.Bones) > 0
+}
+
+// VertexCount returns the number of floats of the world vertices, this
+// is also the size of the deform vertices of unweighted mesh.
+func (m *MeshAttachment) VertexCount() int {
+	return len(m.RegionUVs)
+}
+
+// deformCount returns the size of deform vertices, deform offsets of a
+// weighted mesh are applied to every bone influence.
+func (m *MeshAttachment) deformCount() int {
+	if m.Weighted() {
+		return len(m.Weights) / 3 * 2
+	}
+	return len(m.Vertices)
+}
+
+// UpdateUVs computes the uv of each vertex in the atlas page.
+func (m *MeshAttachment) UpdateUVs() {
+	var (
+		width  = m.RegionU2 - m.RegionU
+		height = m.RegionV2 - m.RegionV
+		n      = len(m.RegionUVs)
+	)
+	if len(m.Uvs) != n {
+		m.Uvs = make([]float32, n)
+	}
+	if m.RegionRotate {
+		for i := 0; i < n; i += 2 {
+			m.Uvs[i] = m.RegionU + m.RegionUVs[i+1]*width
+			m.Uvs[i+1] = m.RegionV + height - m.RegionUVs[i]*height
+		}
+	} else {
+		for i := 0; i < n; i += 2 {
+			m.Uvs[i] = m.RegionU + m.RegionUVs[i]*width
+			m.Uvs[i+1] = m.RegionV + m.RegionUVs[i+1]*height
+		}
+	}
+}
+
+// ComputeWorldVertices computes the world vertices of the mesh, deformed
+// by the slot's AttachmentVertices.
+func (m *MeshAttachment) ComputeWorldVertices(slot *Slot, world []float32) []float32 {
+	var (
+		sk     = slot.Skeleton()
+		x, y   = sk.X, sk.Y
+		deform = slot.AttachmentVertices
+	)
+	if n := m.VertexCount(); cap(world) < n {
+		world = make([]float32, n)
+	} else {
+		world = world[:n]
+	}
+	if len(deform) != m.deformCount() {
+		deform = nil
+	}
+
+	if !m.Weighted() {
+		bone := slot.Bone
+		x, y = x+bone.WorldX, y+bone.WorldY
+		vertices := m.Vertices
+		if deform != nil {
+			vertices = deform
+		}
+		for i := 0; i < len(vertices); i += 2 {
+			vx, vy := vertices[i], vertices[i+1]
+			world[i] = vx*bone.M00 + vy*bone.M01 + x
+			world[i+1] = vx*bone.M10 + vy*bone.M11 + y
+		}
+		return world
+	}
+
+	var (
+		bones   = sk.Bones
+		weights = m.Weights
+	)
+	for w, v, b, f := 0, 0, 0, 0; v < len(m.Bones); w += 2 {
+		var wx, wy float32
+		nn := m.Bones[v] + v
+		for v++; v <= nn; v, b = v+1, b+3 {
+			bone := bones[m.Bones[v]]
+			vx, vy, weight := weights[b], weights[b+1], weights[b+2]
+			if deform != nil {
+				vx, vy = vx+deform[f], vy+deform[f+1]
+				f += 2
+			}
+			wx += (vx*bone.M00 + vy*bone.M01 + bone.WorldX) * weight
+			wy += (vx*bone.M10 + vy*bone.M11 + bone.WorldY) * weight
+		}
+		world[w], world[w+1] = wx+x, wy+y
+	}
+	return world
+}
@@ -0,0 +1,246 @@
+package spine
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx"
+	"korok.io/korok/gfx/bk"
+	"korok.io/korok/math/f32"
+)
+
+const testMeshAtlas = `
+test.png
+format: RGBA8888
+size: 64,64
+filter: Linear,Linear
+repeat: none
+head
+  rotate: false
+  xy: 0, 0
+  size: 32, 32
+  orig: 32, 32
+  offset: 0, 0
+  index: -1
+mesh
+  rotate: false
+  xy: 32, 0
+  size: 32, 32
+  orig: 32, 32
+  offset: 0, 0
+  index: -1
+`
+
+const testMeshSkeleton = `{
+"bones": [
+	{"name": "root"},
+	{"name": "a", "parent": "root", "x": 10},
+	{"name": "b", "parent": "root", "x": -10}
+],
+"slots": [
+	{"name": "s1", "bone": "a", "attachment": "head"},
+	{"name": "s2", "bone": "root", "attachment": "mesh"},
+	{"name": "s3", "bone": "root", "attachment": "skin"}
+],
+"skins": {
+	"default": {
+		"s1": {"head": {"width": 32, "height": 32}},
+		"s2": {"mesh": {"type": "mesh", "uvs": [0, 0, 1, 0, 1, 1], "triangles": [0, 1, 2], "vertices": [0, 0, 10, 0, 10, 10]}},
+		"s3": {"skin": {"type": "skinnedmesh", "path": "mesh", "uvs": [0, 0, 1, 0, 1, 1], "triangles": [0, 1, 2],
+			"vertices": [1, 1, 0, 0, 1, 2, 1, 0, 0, 0.5, 2, 0, 0, 0.5, 1, 2, 5, 5, 1]}}
+	}
+},
+"events": {
+	"hit": {"int": 1}
+},
+"animations": {
+	"deform": {
+		"ffd": {"default": {
+			"s2": {"mesh": [{"time": 0}, {"time": 1, "offset": 2, "vertices": [5, 5]}]},
+			"s3": {"skin": [{"time": 0, "vertices": [1, 1]}]}
+		}},
+		"draworder": [{"time": 0.5, "offsets": [{"slot": "s1", "offset": 2}]}],
+		"events": [{"time": 0, "name": "hit"}, {"time": 0.5, "name": "hit", "int": 5}]
+	}
+}
+}`
+
+func newTestMeshData(t *testing.T) *SkeletonData {
+	atlas, err := NewAtlas(strings.NewReader(testMeshAtlas), testTextureLoader{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := New(strings.NewReader(testMeshSkeleton), 1, AtlasAttachmentLoader{atlas})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func equalVertices(a, b []float32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if d := a[i] - b[i]; d > 1e-4 || d < -1e-4 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMeshAttachment(t *testing.T) {
+	sk := NewSkeleton(newTestMeshData(t))
+	sk.SetToSetupPose()
+	sk.UpdateWorldTransform()
+
+	_, s2 := sk.FindSlot("s2")
+	mesh := s2.Attachment.(*MeshAttachment)
+	if v := mesh.ComputeWorldVertices(s2, nil); !equalVertices(v, []float32{0, 0, 10, 0, 10, 10}) {
+		t.Errorf("mesh vertices: %v", v)
+	}
+	if !equalVertices(mesh.Uvs, []float32{.5, 0, 1, 0, 1, .5}) {
+		t.Errorf("mesh uvs: %v", mesh.Uvs)
+	}
+
+	_, s3 := sk.FindSlot("s3")
+	skin := s3.Attachment.(*MeshAttachment)
+	if !skin.Weighted() {
+		t.Fatal("mesh should be weighted")
+	}
+	if v := skin.ComputeWorldVertices(s3, nil); !equalVertices(v, []float32{10, 0, 0, 0, -5, 5}) {
+		t.Errorf("weighted vertices: %v", v)
+	}
+}
+
+func TestDeformTimelines(t *testing.T) {
+	st := NewSkeletonTable(8)
+	eng := NewEngine()
+	eng.RequireTable([]interface{}{st})
+
+	sc := st.NewCompX(engi.Entity(1), newTestMeshData(t))
+	sk := sc.Skeleton()
+	sc.Play("deform", false)
+
+	eng.Update(.25)
+	if n := len(sc.Events()); n != 1 || sc.Events()[0].Int != 1 {
+		t.Errorf("event at 0 should be fired: %d", n)
+	}
+	if sk.DrawOrder[0].data.name != "s1" {
+		t.Error("draw order should not change before .5")
+	}
+
+	_, s2 := sk.FindSlot("s2")
+	mesh := s2.Attachment.(*MeshAttachment)
+	eng.Update(.25)
+	if v := mesh.ComputeWorldVertices(s2, nil); !equalVertices(v, []float32{0, 0, 12.5, 2.5, 10, 10}) {
+		t.Errorf("deformed vertices: %v", v)
+	}
+	_, s3 := sk.FindSlot("s3")
+	skin := s3.Attachment.(*MeshAttachment)
+	if v := skin.ComputeWorldVertices(s3, nil); !equalVertices(v, []float32{11, 1, 0, 0, -5, 5}) {
+		t.Errorf("deformed weighted vertices: %v", v)
+	}
+
+	if e := sc.Events(); len(e) != 1 || e[0].Int != 5 || e[0].Name() != "hit" {
+		t.Errorf("event at .5 should be fired: %v", e)
+	}
+	var order []string
+	for _, slot := range sk.DrawOrder {
+		order = append(order, slot.data.name)
+	}
+	if s := strings.Join(order, ","); s != "s2,s3,s1" {
+		t.Errorf("draw order: %s", s)
+	}
+
+	eng.Update(.25)
+	if len(sc.Events()) != 0 {
+		t.Error("no event after .5")
+	}
+}
+
+// skeleton with a mesh attachment of n vertices, the triangles are the
+// first tri vertices
+func newLargeMeshData(t *testing.T, n, tri int) *SkeletonData {
+	var b strings.Builder
+	b.WriteString(`{"bones": [{"name": "root"}], "slots": [{"name": "s", "bone": "root", "attachment": "mesh"}],`)
+	b.WriteString(`"skins": {"default": {"s": {"mesh": {"type": "mesh", "uvs": [`)
+	for i := 0; i < n*2; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("0")
+	}
+	b.WriteString(`], "triangles": [`)
+	for i := 0; i < tri; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString(strconv.Itoa(i))
+	}
+	b.WriteString(`], "vertices": [`)
+	for i := 0; i < n*2; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("1")
+	}
+	b.WriteString(`]}}}}}`)
+
+	atlas, err := NewAtlas(strings.NewReader(testMeshAtlas), testTextureLoader{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := New(strings.NewReader(b.String()), 1, AtlasAttachmentLoader{atlas})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestMeshBufferSplit(t *testing.T) {
+	defer bk.SetBackend(bk.CurrentBackend())
+	bk.SetBackend(bk.NewRecorder())
+	gfx.Init(1)
+	mr := gfx.NewMeshRender("", "")
+	m := &f32.Mat3{}
+	m.Initialize(0, 0, 0, 1, 1, 0, 0, 0, 0)
+
+	// 2 skeletons in a chunk
+	sk := NewSkeleton(newLargeMeshData(t, 30000, 30000))
+	sk.SetToSetupPose()
+	sk.UpdateWorldTransform()
+	mb := &meshBuffer{}
+	for i := 0; i < 3; i++ {
+		mb.drawSkeleton(sk, m, 0, mr)
+	}
+	if mb.chunk != 1 || len(mb.vertex) != 30000 {
+		t.Errorf("split: chunk %d, vertex %d", mb.chunk, len(mb.vertex))
+	}
+	mb.flush(mr)
+	if mb.chunk != 2 || len(mb.indexes) != 2 || len(mb.vertex) != 0 {
+		t.Errorf("flush: chunk %d, index buffer %d", mb.chunk, len(mb.indexes))
+	}
+
+	// too large to draw
+	mb.chunk = 0
+	large := NewSkeleton(newLargeMeshData(t, 0x10000, 3))
+	large.SetToSetupPose()
+	large.UpdateWorldTransform()
+	mb.drawSkeleton(large, m, 0, mr)
+	if len(mb.vertex) != 0 || len(mb.draws) != 0 {
+		t.Errorf("large attachment should be skipped, vertex %d", len(mb.vertex))
+	}
+
+	// the freed index buffers are reused
+	id := mb.indexes[1].id
+	mb.destroy()
+	if len(mb.indexes) != 0 {
+		t.Error("index buffers should be freed")
+	}
+	if nid, _ := bk.R.AllocIndexBuffer(bk.Memory{Size: 2}); nid != id {
+		t.Errorf("index buffer should be freed, got %d", nid)
+	}
+}
@@ -0,0 +1,368 @@
+package spine
+
+import (
+	"korok.io/korok/gfx"
+	"korok.io/korok/gfx/bk"
+	"korok.io/korok/math/f32"
+
+	"log"
+	"unsafe"
+)
+
+// SkeletonRenderFeature draws the region attachments of skeletons with
+// BatchRender, attachments are batched until the texture page changes.
+// Skeletons with mesh attachments are drawn with MeshRender, regions of
+// these skeletons are drawn as meshes too, to keep the draw order.
+type SkeletonRenderFeature struct {
+	id int
+
+	R  *gfx.BatchRender
+	MR *gfx.MeshRender
+	st *SkeletonTable
+	xt *gfx.TransformTable
+
+	meshBuffer
+}
+
+func (f *SkeletonRenderFeature) SetRender(render *gfx.BatchRender, mr *gfx.MeshRender) {
+	f.R, f.MR = render, mr
+}
+
+func (f *SkeletonRenderFeature) SetTable(st *SkeletonTable, xt *gfx.TransformTable) {
+	f.st, f.xt = st, xt
+}
+
+// 此处初始化所有的依赖
+func (f *SkeletonRenderFeature) Register(rs *gfx.RenderSystem) {
+	// init render
+	for _, r := range rs.RenderList {
+		switch br := r.(type) {
+		case *gfx.BatchRender:
+			f.R = br
+		case *gfx.MeshRender:
+			f.MR = br
+		}
+	}
+	// init table
+	for _, t := range rs.TableList {
+		switch table := t.(type) {
+		case *SkeletonTable:
+			f.st = table
+		case *gfx.TransformTable:
+			f.xt = table
+		}
+	}
+	// add new feature, use the index as id
+	f.id = rs.Accept(f)
+}
+
+func (f *SkeletonRenderFeature) Extract(v *gfx.View) {
+	var (
+		camera = v.Camera
+		xt     = f.xt
+		fi     = uint32(f.id) << 16
+	)
+	for i := range f.st.comps[:f.st.index] {
+		sc := &f.st.comps[i]
+		if !sc.visible || sc.skeleton == nil {
+			continue
+		}
+		b := sc.bounds
+		if b.w <= 0 || b.h <= 0 {
+			continue
+		}
+		xf := xt.Comp(sc.Entity)
+		if xf == nil {
+			continue
+		}
+		sz := f32.Vec2{b.w, b.h}
+		g := f32.Vec2{-b.x / b.w, -b.y / b.h}
+		if camera.InView(xf, sz, g) {
+			sid := gfx.PackSortId(sc.zOrder, 0)
+			val := fi + uint32(i)
+			v.RenderNodes = append(v.RenderNodes, gfx.SortObject{SortId: sid, Value: val})
+		}
+	}
+}
+
+func (f *SkeletonRenderFeature) Draw(nodes gfx.RenderNodes) {
+	var (
+		st, xt = f.st, f.xt
+		render = f.R
+		texId  = uint16(0)
+		begin  = false
+	)
+	bo := regionBatchObject{}
+	for _, node := range nodes {
+		sc := &st.comps[node.Value&0xFFFF]
+		sk := sc.skeleton
+		depth, _ := gfx.UnpackSortId(node.SortId)
+
+		srt := xt.Comp(sc.Entity).World()
+		bo.m.Initialize(srt.Position[0], srt.Position[1], srt.Rotation, srt.Scale[0], srt.Scale[1], 0, 0, 0, 0)
+		if sc.hasMesh {
+			f.meshBuffer.drawSkeleton(sk, &bo.m, depth, f.MR)
+			continue
+		}
+
+		for _, slot := range sk.DrawOrder {
+			region, ok := slot.Attachment.(*RegionAttachment)
+			if !ok {
+				continue
+			}
+			tex, ok := regionTexture(region)
+			if !ok {
+				continue
+			}
+			// texture changed, commit a batch
+			if !begin || tex != texId {
+				if begin {
+					render.End()
+				}
+				render.Begin(tex, depth)
+				texId, begin = tex, true
+			}
+			bo.verts = region.Update(slot)
+			bo.uvs = &region.Uvs
+			bo.color = gfx.PMAColorf(sk.r*slot.R, sk.g*slot.G, sk.b*slot.B, sk.a*slot.A).U32()
+			render.Draw(bo)
+		}
+		// a new batch for each skeleton, so the depth is right
+		if begin {
+			render.End()
+			begin = false
+		}
+	}
+	render.Flush()
+	f.meshBuffer.flush(f.MR)
+	f.meshBuffer.chunk = 0
+}
+
+// Flush frees the index buffers if all the skeletons are destroyed.
+func (f *SkeletonRenderFeature) Flush() {
+	if f.st != nil && f.st.index == 0 {
+		f.meshBuffer.destroy()
+	}
+}
+
+// Destroy frees the index buffers of the mesh attachments.
+func (f *SkeletonRenderFeature) Destroy() {
+	f.meshBuffer.destroy()
+}
+
+// texture id of the atlas page, see TextureLoader
+func regionTexture(r *RegionAttachment) (id uint16, ok bool) {
+	if region, ok := r.RendererObject.(*AtlasRegion); ok && region.Page != nil {
+		id, ok = region.Page.RendererObject.(uint16)
+		return id, ok
+	}
+	return
+}
+
+// vertex and index data of the skeletons with mesh attachments, all the
+// vertex are in a temp vertex buffer and uploaded once per frame. The
+// vertex and index are addressed by uint16, if there are more the data
+// is split into chunks, each chunk has its own index buffer.
+type meshBuffer struct {
+	vertex []gfx.PosTexColorVertex
+	index  []uint16
+	draws  []meshDraw
+
+	// world vertices of the attachment
+	world []float32
+
+	// index buffers of the chunks, and the chunks flushed this frame
+	indexes []meshIndex
+	chunk   int
+
+	// the mesh to draw a chunk
+	mesh gfx.Mesh
+}
+
+type meshIndex struct {
+	id   uint16
+	size int
+}
+
+type meshDraw struct {
+	tex   uint16
+	depth int16
+
+	firstVertex, numVertex int
+	firstIndex, numIndex   int
+}
+
+var quadIndex = []uint16{0, 1, 2, 2, 3, 0}
+
+// the vertex are transformed to world space in drawSkeleton
+var identity = f32.Ident4()
+
+func (mb *meshBuffer) drawSkeleton(sk *Skeleton, m *f32.Mat3, depth int16, render *gfx.MeshRender) {
+	var (
+		uvs        []float32
+		triangles  []uint16
+		r, g, b, a float32
+		draw       *meshDraw
+	)
+	for _, slot := range sk.DrawOrder {
+		var (
+			tex uint16
+			ok  bool
+		)
+		switch attachment := slot.Attachment.(type) {
+		case *RegionAttachment:
+			if tex, ok = regionTexture(attachment); !ok {
+				continue
+			}
+			quad := attachment.Update(slot)
+			mb.world = append(mb.world[:0], quad[:]...)
+			uvs, triangles = attachment.Uvs[:], quadIndex
+			r, g, b, a = 1, 1, 1, 1
+		case *MeshAttachment:
+			if tex, ok = meshTexture(attachment); !ok {
+				continue
+			}
+			mb.world = attachment.ComputeWorldVertices(slot, mb.world)
+			uvs, triangles = attachment.Uvs, attachment.Triangles
+			r, g, b, a = attachment.R, attachment.G, attachment.B, attachment.A
+		default:
+			continue
+		}
+
+		// vertex and index are addressed by uint16
+		n := len(mb.world) / 2
+		if n > 0xFFFF || len(triangles) > 0xFFFF {
+			log.Println("spine: attachment is too large to draw,", slot.data.name)
+			continue
+		}
+		if len(mb.vertex)+n > 0xFFFF || len(mb.index)+len(triangles) > 0xFFFF {
+			mb.flush(render)
+			draw = nil
+		}
+		// texture changed, start a new draw
+		if draw == nil || draw.tex != tex {
+			mb.draws = append(mb.draws, meshDraw{
+				tex:         tex,
+				depth:       depth,
+				firstVertex: len(mb.vertex),
+				firstIndex:  len(mb.index),
+			})
+			draw = &mb.draws[len(mb.draws)-1]
+		}
+
+		color := gfx.PMAColorf(sk.r*slot.R*r, sk.g*slot.G*g, sk.b*slot.B*b, sk.a*slot.A*a).U32()
+		for i := 0; i < n; i++ {
+			v := gfx.PosTexColorVertex{U: uvs[i*2], V: uvs[i*2+1], RGBA: color}
+			v.X, v.Y = m.Transform(mb.world[i*2], mb.world[i*2+1])
+			mb.vertex = append(mb.vertex, v)
+		}
+		base := uint16(draw.numVertex)
+		for _, i := range triangles {
+			mb.index = append(mb.index, base+i)
+		}
+		draw.numVertex += n
+		draw.numIndex += len(triangles)
+	}
+}
+
+func (mb *meshBuffer) flush(render *gfx.MeshRender) {
+	if len(mb.draws) == 0 || render == nil {
+		mb.reset()
+		return
+	}
+
+	// upload index, the index buffer of the chunk grows if not enough
+	if mb.chunk == len(mb.indexes) {
+		mb.indexes = append(mb.indexes, meshIndex{})
+	}
+	mi := &mb.indexes[mb.chunk]
+	mb.chunk++
+	if size := len(mb.index); size > mi.size {
+		if mi.size > 0 {
+			bk.R.Free(mi.id)
+			mi.id, mi.size = 0, 0
+		}
+		size = (size + 1023) &^ 1023
+		if size > 0xFFFF {
+			size = 0xFFFF
+		}
+		if id, _ := bk.R.AllocIndexBuffer(bk.Memory{Data: nil, Size: uint32(size) * 2}); id != bk.InvalidId {
+			mi.id, mi.size = id, size
+		}
+	}
+	// skip the chunk if fail to alloc the index buffer
+	if mi.size == 0 {
+		mb.reset()
+		return
+	}
+	if ok, ib := bk.R.IndexBuffer(mi.id); ok {
+		ib.Update(0, uint32(len(mb.index)*2), unsafe.Pointer(&mb.index[0]), false)
+	}
+
+	// upload vertex
+	vertexId, _, vb := gfx.Context.TempVertexBuffer(len(mb.vertex), 20)
+	vb.Update(0, uint32(len(mb.vertex)*20), unsafe.Pointer(&mb.vertex[0]), false)
+
+	mesh := &mb.mesh
+	mesh.IndexId, mesh.VertexId = mi.id, vertexId
+	for _, d := range mb.draws {
+		mesh.FirstVertex = uint16(d.firstVertex)
+		mesh.NumVertex = uint16(d.numVertex)
+		mesh.FirstIndex = uint16(d.firstIndex)
+		mesh.NumIndex = uint16(d.numIndex)
+		mesh.SetTexture(d.tex)
+		render.Draw(mesh, &identity, int32(d.depth))
+	}
+	mb.reset()
+}
+
+func (mb *meshBuffer) reset() {
+	mb.vertex = mb.vertex[:0]
+	mb.index = mb.index[:0]
+	mb.draws = mb.draws[:0]
+}
+
+// free the index buffers
+func (mb *meshBuffer) destroy() {
+	for _, mi := range mb.indexes {
+		if mi.size > 0 {
+			bk.R.Free(mi.id)
+		}
+	}
+	mb.indexes = mb.indexes[:0]
+	mb.chunk = 0
+	mb.reset()
+}
+
+func meshTexture(m *MeshAttachment) (id uint16, ok bool) {
+	if region, ok := m.RendererObject.(*AtlasRegion); ok && region.Page != nil {
+		id, ok = region.Page.RendererObject.(uint16)
+		return id, ok
+	}
+	return
+}
+
+type regionBatchObject struct {
+	verts [8]float32
+	uvs   *[8]float32
+	color uint32
+	m     f32.Mat3
+}
+
+// vertex order of the region attachment:
+//
+//	1 ---- 2
+//	|      |
+//	|      |
+//	0------3
+func (bo regionBatchObject) Fill(buf []gfx.PosTexColorVertex) {
+	for i := 0; i < 4; i++ {
+		buf[i].X, buf[i].Y = bo.m.Transform(bo.verts[i*2], bo.verts[i*2+1])
+		buf[i].U, buf[i].V = bo.uvs[i*2], bo.uvs[i*2+1]
+		buf[i].RGBA = bo.color
+	}
+}
+
+func (bo regionBatchObject) Size() int {
+	return 4
+}
@@ -5,6 +5,7 @@ type SkeletonData struct {
 	slots       []*SlotData
 	skins       []*Skin
 	animations  []*Animation
+	events      []*EventData
 	defaultSkin *Skin
 }
 
@@ -14,6 +15,7 @@ func NewSkeletonData() *SkeletonData {
 	data.slots = make([]*SlotData, 0)
 	data.skins = make([]*Skin, 0)
 	data.animations = make([]*Animation, 0)
+	data.events = make([]*EventData, 0)
 	return data
 }
 
@@ -53,6 +55,15 @@ func (s *SkeletonData) findAnimation(name string) (int, *Animation) {
 	return -1, nil
 }
 
+func (s *SkeletonData) findEvent(name string) (int, *EventData) {
+	for i, event := range s.events {
+		if event.name == name {
+			return i, event
+		}
+	}
+	return -1, nil
+}
+
 type Skeleton struct {
 	data         *SkeletonData
 	Bones        []*Bone
@@ -119,6 +130,7 @@ func (s *Skeleton) setSlotsToSetupPose() {
 	for _, slot := range s.Slots {
 		slot.SetToSetupPose()
 	}
+	copy(s.DrawOrder, s.Slots)
 }
 
 func (s *Skeleton) RootBone() *Bone {
@@ -25,6 +25,9 @@ type Slot struct {
 	R, G, B, A     float32
 	attachmentTime float32
 	Attachment     Attachment
+
+	// deformed vertices of the mesh attachment, see FfdTimeline
+	AttachmentVertices []float32
 }
 
 func NewSlot(slotData *SlotData, skeleton *Skeleton, bone *Bone) *Slot {
@@ -56,6 +59,9 @@ func (s *Slot) SetToSetupPose() {
 }
 
 func (s *Slot) SetAttachment(attachment Attachment) {
+	if s.Attachment != attachment {
+		s.AttachmentVertices = s.AttachmentVertices[:0]
+	}
 	s.Attachment = attachment
 	s.attachmentTime = s.skeleton.time
 }
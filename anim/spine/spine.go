@@ -12,6 +12,28 @@ import (
 type fileAnim struct {
 	Bones map[string]map[string][]map[string]interface{} `json:"bones"`
 	Slots map[string]map[string][]map[string]interface{} `json:"slots"`
+
+	// skin -> slot -> attachment -> frames, "deform" since spine 3.0
+	Ffd    map[string]map[string]map[string][]map[string]interface{} `json:"ffd"`
+	Deform map[string]map[string]map[string][]map[string]interface{} `json:"deform"`
+
+	DrawOrder []fileDrawOrder          `json:"drawOrder"`
+	Events    []map[string]interface{} `json:"events"`
+}
+
+type fileDrawOrder struct {
+	Time    float32 `json:"time"`
+	Offsets []struct {
+		Slot   string `json:"slot"`
+		Offset int    `json:"offset"`
+	} `json:"offsets"`
+}
+
+// 事件
+type fileEvent struct {
+	Int    int     `json:"int"`
+	Float  float32 `json:"float"`
+	String string  `json:"string"`
 }
 
 // 插槽 - 骨骼和蒙皮的关联映射
@@ -47,6 +69,14 @@ type fileAttachment struct {
 	ScaleY   interface{} `json:"scaleY"`
 	Width    interface{} `json:"width"`
 	Height   interface{} `json:"height"`
+
+	// mesh
+	Path      string    `json:"path"`
+	Color     string    `json:"color"`
+	UVs       []float32 `json:"uvs"`
+	Triangles []int     `json:"triangles"`
+	Vertices  []float32 `json:"vertices"`
+	Hull      int       `json:"hull"`
 }
 
 type fileRoot struct {
@@ -54,6 +84,7 @@ type fileRoot struct {
 	Slots      []fileSlot                                      `json:"slots"`
 	Skins      map[string]map[string]map[string]fileAttachment `json:"skins"`
 	Animations map[string]fileAnim                             `json:"animations"`
+	Events     map[string]fileEvent                            `json:"events"`
 }
 
 type AttachmentLoader interface {
@@ -65,7 +96,11 @@ type AtlasAttachmentLoader struct {
 }
 
 func (a AtlasAttachmentLoader) NewAttachment(skin *Skin, _type, name string) (Attachment, error) {
-	if _type != "region" && _type != "" {
+	switch _type {
+	case "region", "":
+	case "mesh", "skinnedmesh", "weightedmesh":
+		return a.newMeshAttachment(name)
+	default:
 		return nil, errors.New("spine: unknown attachment type: " + _type)
 	}
 	attachment := NewRegionAttachment(name)
@@ -84,6 +119,19 @@ func (a AtlasAttachmentLoader) NewAttachment(skin *Skin, _type, name string) (At
 	return attachment, nil
 }
 
+func (a AtlasAttachmentLoader) newMeshAttachment(name string) (Attachment, error) {
+	region := a.FindRegion(name)
+	if region == nil {
+		return nil, errors.New("spine: region not found in atlas: " + name + " (mesh)")
+	}
+	attachment := NewMeshAttachment(name)
+	attachment.RendererObject = region
+	attachment.RegionU, attachment.RegionV = region.U, region.V
+	attachment.RegionU2, attachment.RegionV2 = region.U2, region.V2
+	attachment.RegionRotate = region.Rotate
+	return attachment, nil
+}
+
 func New(r io.Reader, scale float32, loader AttachmentLoader) (*SkeletonData, error) {
 	var root fileRoot
 	err := json.NewDecoder(r).Decode(&root)
@@ -160,6 +208,15 @@ func New(r io.Reader, scale float32, loader AttachmentLoader) (*SkeletonData, er
 		skeletonData.slots = append(skeletonData.slots, slotData)
 	}
 
+	// Events
+	for name, event := range root.Events {
+		eventData := NewEventData(name)
+		eventData.Int = event.Int
+		eventData.Float = event.Float
+		eventData.String = event.String
+		skeletonData.events = append(skeletonData.events, eventData)
+	}
+
 	// Skins
 	for skinName, skinMap := range root.Skins {
 		skin := NewSkin(skinName)
@@ -171,12 +228,21 @@ func New(r io.Reader, scale float32, loader AttachmentLoader) (*SkeletonData, er
 					atName = at.Name
 				}
 
+				if at.Path != "" {
+					atName = at.Path
+				}
+
 				attachment, err := loader.NewAttachment(skin, at.Type, atName)
 				if err != nil {
 					return nil, err
 				}
-				if regionAttach, ok := attachment.(*RegionAttachment); ok {
-					readAttachment(regionAttach, at, scale)
+				switch attach := attachment.(type) {
+				case *RegionAttachment:
+					readAttachment(attach, at, scale)
+				case *MeshAttachment:
+					if err := readMesh(attach, at, scale); err != nil {
+						return nil, err
+					}
 				}
 				skin.AddAttachment(slotIndex, name, attachment)
 			}
@@ -295,6 +361,82 @@ func New(r io.Reader, scale float32, loader AttachmentLoader) (*SkeletonData, er
 				}
 			}
 		}
+		ffd := fileAnim.Ffd
+		if ffd == nil {
+			ffd = fileAnim.Deform
+		}
+		for skinName, slotMap := range ffd {
+			_, skin := skeletonData.findSkin(skinName)
+			if skin == nil {
+				return nil, errors.New("spine: ffd skin not found: " + skinName)
+			}
+			for slotName, meshMap := range slotMap {
+				slotIndex, _ := skeletonData.findSlot(slotName)
+				for meshName, values := range meshMap {
+					mesh, ok := skin.Attachment(slotIndex, meshName).(*MeshAttachment)
+					if !ok {
+						return nil, errors.New("spine: ffd mesh not found: " + meshName)
+					}
+					timeline := NewFfdTimeline(len(values))
+					timeline.slotIndex = slotIndex
+					timeline.attachment = mesh
+
+					for frameIndex, valueMap := range values {
+						time := float32(valueMap["time"].(float64))
+						timeline.setFrame(frameIndex, time, readFfdVertices(mesh, valueMap, scale))
+						if curve, ok := valueMap["curve"]; ok {
+							readCurve(timeline.curve, frameIndex, curve)
+						}
+					}
+					duration = float32(math.Max(float64(duration), float64(timeline.frames[timeline.frameCount()-1])))
+					timelines = append(timelines, timeline)
+				}
+			}
+		}
+
+		if values := fileAnim.DrawOrder; len(values) > 0 {
+			timeline := NewDrawOrderTimeline(len(values))
+			for frameIndex, value := range values {
+				drawOrder, err := readDrawOrder(skeletonData, value)
+				if err != nil {
+					return nil, err
+				}
+				timeline.setFrame(frameIndex, value.Time, drawOrder)
+			}
+			duration = float32(math.Max(float64(duration), float64(timeline.frames[timeline.frameCount()-1])))
+			timelines = append(timelines, timeline)
+		}
+
+		if values := fileAnim.Events; len(values) > 0 {
+			timeline := NewEventTimeline(len(values))
+			for frameIndex, valueMap := range values {
+				name, _ := valueMap["name"].(string)
+				_, eventData := skeletonData.findEvent(name)
+				if eventData == nil {
+					return nil, errors.New("spine: event not found: " + name)
+				}
+				event := &Event{
+					Data:   eventData,
+					Int:    eventData.Int,
+					Float:  eventData.Float,
+					String: eventData.String,
+				}
+				event.Time = float32(valueMap["time"].(float64))
+				if v, ok := valueMap["int"].(float64); ok {
+					event.Int = int(v)
+				}
+				if v, ok := valueMap["float"].(float64); ok {
+					event.Float = float32(v)
+				}
+				if v, ok := valueMap["string"].(string); ok {
+					event.String = v
+				}
+				timeline.setFrame(frameIndex, event)
+			}
+			duration = float32(math.Max(float64(duration), float64(timeline.frames[timeline.frameCount()-1])))
+			timelines = append(timelines, timeline)
+		}
+
 		anim := NewAnimation(animName, timelines, duration)
 		skeletonData.animations = append(skeletonData.animations, anim)
 	}
@@ -362,4 +504,118 @@ func readCurve(curve *Curve, frameIndex int, data interface{}) {
 		d := float32(t[3].(float64))
 		curve.SetCurve(frameIndex, a, b, c, d)
 	}
-}
\ No newline at end of file
+}
+
+// 读取网格, 带权重的网格的顶点格式为: boneCount, [boneIndex, x, y, weight]...
+func readMesh(mesh *MeshAttachment, at fileAttachment, scale float32) error {
+	uvs, vertices := at.UVs, at.Vertices
+	if at.Type == "mesh" && len(vertices) == len(uvs) {
+		mesh.Vertices = make([]float32, len(vertices))
+		for i, v := range vertices {
+			mesh.Vertices[i] = v * scale
+		}
+	} else {
+		for i := 0; i < len(vertices); {
+			boneCount := int(vertices[i])
+			nn := i + 1 + boneCount*4
+			if nn > len(vertices) {
+				return errors.New("spine: invalid weighted mesh: " + mesh.name)
+			}
+			mesh.Bones = append(mesh.Bones, boneCount)
+			for i++; i < nn; i += 4 {
+				mesh.Bones = append(mesh.Bones, int(vertices[i]))
+				mesh.Weights = append(mesh.Weights, vertices[i+1]*scale, vertices[i+2]*scale, vertices[i+3])
+			}
+		}
+		if len(mesh.Bones)-len(mesh.Weights)/3 != len(uvs)/2 {
+			return errors.New("spine: invalid weighted mesh: " + mesh.name)
+		}
+	}
+
+	mesh.Triangles = make([]uint16, len(at.Triangles))
+	for i, v := range at.Triangles {
+		mesh.Triangles[i] = uint16(v)
+	}
+	mesh.RegionUVs = uvs
+	mesh.UpdateUVs()
+	mesh.HullLength = at.Hull * 2
+
+	if color := at.Color; color != "" {
+		c, err := toColor(color)
+		if err != nil {
+			return errors.New("spine: failed to parse color: " + err.Error())
+		}
+		mesh.R, mesh.G, mesh.B, mesh.A = c[0], c[1], c[2], c[3]
+	}
+	if width, ok := at.Width.(float64); ok {
+		mesh.Width = float32(width) * scale
+	}
+	if height, ok := at.Height.(float64); ok {
+		mesh.Height = float32(height) * scale
+	}
+	return nil
+}
+
+// 读取网格变形, 不带权重的网格保存顶点位置, 带权重的网格保存偏移
+func readFfdVertices(mesh *MeshAttachment, valueMap map[string]interface{}, scale float32) []float32 {
+	vertices := make([]float32, mesh.deformCount())
+	if !mesh.Weighted() {
+		copy(vertices, mesh.Vertices)
+	}
+	values, ok := valueMap["vertices"].([]interface{})
+	if !ok {
+		return vertices
+	}
+	start, _ := valueMap["offset"].(float64)
+	for i, v := range values {
+		if j := int(start) + i; j < len(vertices) {
+			vertices[j] += float32(v.(float64)) * scale
+		}
+	}
+	return vertices
+}
+
+// 读取绘制顺序, 返回每个位置对应的插槽索引
+func readDrawOrder(data *SkeletonData, value fileDrawOrder) ([]int, error) {
+	if len(value.Offsets) == 0 {
+		return nil, nil
+	}
+	slotCount := len(data.slots)
+	drawOrder := make([]int, slotCount)
+	for i := range drawOrder {
+		drawOrder[i] = -1
+	}
+	unchanged := make([]int, 0, slotCount)
+	originalIndex := 0
+	for _, offset := range value.Offsets {
+		slotIndex, _ := data.findSlot(offset.Slot)
+		if slotIndex < 0 {
+			return nil, errors.New("spine: draw order slot not found: " + offset.Slot)
+		}
+		// Collect unchanged items.
+		for originalIndex != slotIndex {
+			unchanged = append(unchanged, originalIndex)
+			originalIndex++
+		}
+		// Set changed items.
+		if i := originalIndex + offset.Offset; i >= 0 && i < slotCount {
+			drawOrder[i] = originalIndex
+		}
+		originalIndex++
+	}
+	// Collect remaining unchanged items.
+	for ; originalIndex < slotCount; originalIndex++ {
+		unchanged = append(unchanged, originalIndex)
+	}
+	// Fill in unchanged items.
+	for i := slotCount - 1; i >= 0; i-- {
+		if drawOrder[i] == -1 {
+			n := len(unchanged) - 1
+			if n < 0 {
+				return nil, errors.New("spine: invalid draw order")
+			}
+			drawOrder[i], unchanged = unchanged[n], unchanged[:n]
+		}
+	}
+	return drawOrder, nil
+}
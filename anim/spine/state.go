@@ -0,0 +1,423 @@
+package spine
+
+import (
+	"math"
+)
+
+// AnimationStateData stores the mix durations between animations.
+type AnimationStateData struct {
+	SkeletonData *SkeletonData
+
+	// mix duration if not set for the animation pair
+	DefaultMix float32
+
+	mixes map[mixKey]float32
+}
+
+type mixKey struct {
+	from, to *Animation
+}
+
+func NewAnimationStateData(data *SkeletonData) *AnimationStateData {
+	return &AnimationStateData{
+		SkeletonData: data,
+		mixes:        make(map[mixKey]float32),
+	}
+}
+
+// SetMix sets the mix duration from one animation to another, returns
+// false if the animation is not found.
+func (d *AnimationStateData) SetMix(from, to string, duration float32) bool {
+	_, a := d.SkeletonData.findAnimation(from)
+	_, b := d.SkeletonData.findAnimation(to)
+	if a == nil || b == nil {
+		return false
+	}
+	d.SetMixAnimations(a, b, duration)
+	return true
+}
+
+func (d *AnimationStateData) SetMixAnimations(from, to *Animation, duration float32) {
+	d.mixes[mixKey{from, to}] = duration
+}
+
+// Mix returns the mix duration from one animation to another.
+func (d *AnimationStateData) Mix(from, to *Animation) float32 {
+	if duration, ok := d.mixes[mixKey{from, to}]; ok {
+		return duration
+	}
+	return d.DefaultMix
+}
+
+// AnimationListener receives the animation events of AnimationState.
+// OnStart is called when the entry becomes the current entry of a track,
+// OnEnd is called when the entry is replaced or cleared, OnComplete is
+// called every time a loop is done.
+type AnimationListener interface {
+	OnStart(track int, entry *TrackEntry)
+	OnEnd(track int, entry *TrackEntry)
+	OnComplete(track int, entry *TrackEntry, loopCount int)
+	OnEvent(track int, entry *TrackEntry, event *Event)
+}
+
+// TrackEntry is an animation playing or queued in a track.
+type TrackEntry struct {
+	next, previous *TrackEntry
+
+	Animation *Animation
+	Loop      bool
+
+	// Time is the time in the animation, LastTime is the time of last
+	// frame. EndTime is the duration of the animation by default.
+	Time, LastTime, EndTime float32
+	TimeScale               float32
+
+	// seconds to wait after the previous entry is started, see AddAnimation
+	Delay float32
+
+	// cross-fade from the previous entry
+	MixTime, MixDuration float32
+
+	// Mix is the alpha of the track, it's used to layer the tracks
+	Mix float32
+
+	// Additive adds the bone transforms relative to the setup pose to
+	// the lower tracks instead of replacing them.
+	Additive bool
+
+	// listener of this entry only
+	Listener AnimationListener
+}
+
+func newTrackEntry(anim *Animation, loop bool) *TrackEntry {
+	return &TrackEntry{
+		Animation: anim,
+		Loop:      loop,
+		LastTime:  -1, // events at time 0 are fired
+		EndTime:   anim.duration,
+		TimeScale: 1,
+		Mix:       1,
+	}
+}
+
+// Complete returns true if the entry reaches the end of the animation.
+func (e *TrackEntry) Complete() bool {
+	return e.Time >= e.EndTime
+}
+
+// AnimationState applies the animations of multiple tracks to the skeleton,
+// higher tracks are applied over the lower tracks. Each track plays an
+// animation and the queued animations, and cross-fades between them.
+type AnimationState struct {
+	Data      *AnimationStateData
+	TimeScale float32
+
+	tracks    []*TrackEntry
+	listeners []AnimationListener
+
+	// events fired in the last Apply
+	events []*Event
+
+	// bone pose of the lower tracks, used by the additive track
+	pose []bonePose
+}
+
+type bonePose struct {
+	x, y, rotation, scaleX, scaleY float32
+}
+
+func NewAnimationState(data *AnimationStateData) *AnimationState {
+	return &AnimationState{
+		Data:      data,
+		TimeScale: 1,
+	}
+}
+
+func (s *AnimationState) AddListener(l AnimationListener) {
+	s.listeners = append(s.listeners, l)
+}
+
+func (s *AnimationState) RemoveListener(l AnimationListener) {
+	for i, v := range s.listeners {
+		if v == l {
+			s.listeners = append(s.listeners[:i], s.listeners[i+1:]...)
+			return
+		}
+	}
+}
+
+// Update advances the time of the tracks and starts the queued entries.
+func (s *AnimationState) Update(dt float32) {
+	dt *= s.TimeScale
+	for i, current := range s.tracks {
+		if current == nil {
+			continue
+		}
+		current.Time += dt * current.TimeScale
+		if previous := current.previous; previous != nil {
+			previousDelta := dt * previous.TimeScale
+			previous.Time += previousDelta
+			current.MixTime += previousDelta
+		}
+
+		if next := current.next; next != nil {
+			next.Time = current.LastTime - next.Delay
+			if next.Time >= 0 {
+				s.setCurrent(i, next)
+			}
+		}
+	}
+}
+
+// Apply poses the skeleton with the tracks, fires the events and the
+// complete callbacks.
+func (s *AnimationState) Apply(skeleton *Skeleton) {
+	s.events = s.events[:0]
+	for i, current := range s.tracks {
+		if current == nil {
+			continue
+		}
+		var (
+			anim     = current.Animation
+			time     = current.Time
+			lastTime = current.LastTime
+			endTime  = current.EndTime
+			loop     = current.Loop
+		)
+		if !loop && time > endTime {
+			time = endTime
+		}
+
+		if current.Additive {
+			s.applyAdditive(skeleton, anim, time, loop, current.Mix)
+		} else if previous := current.previous; previous == nil {
+			if current.Mix >= 1 {
+				anim.Apply(skeleton, time, loop)
+			} else {
+				anim.Mix(skeleton, time, loop, current.Mix)
+			}
+		} else {
+			previousTime := previous.Time
+			if !previous.Loop && previousTime > previous.EndTime {
+				previousTime = previous.EndTime
+			}
+			previous.Animation.Apply(skeleton, previousTime, previous.Loop)
+
+			alpha := current.MixTime / current.MixDuration * current.Mix
+			if alpha >= 1 {
+				alpha = 1
+				current.previous = nil
+			}
+			anim.Mix(skeleton, time, loop, alpha)
+		}
+
+		// fire events
+		n := len(s.events)
+		s.events = anim.FireEvents(lastTime, time, loop, s.events)
+		for _, event := range s.events[n:] {
+			if l := current.Listener; l != nil {
+				l.OnEvent(i, current, event)
+			}
+			for _, l := range s.listeners {
+				l.OnEvent(i, current, event)
+			}
+		}
+
+		// check if the animation or a loop iteration is completed
+		if endTime > 0 {
+			var complete bool
+			if loop {
+				complete = lastTime >= 0 && mod(lastTime, endTime) > mod(current.Time, endTime)
+			} else {
+				complete = lastTime < endTime && current.Time >= endTime
+			}
+			if complete {
+				count := int(current.Time / endTime)
+				if l := current.Listener; l != nil {
+					l.OnComplete(i, current, count)
+				}
+				for _, l := range s.listeners {
+					l.OnComplete(i, current, count)
+				}
+			}
+		}
+		current.LastTime = current.Time
+	}
+}
+
+// adds the bone transforms of the animation relative to the setup pose
+func (s *AnimationState) applyAdditive(skeleton *Skeleton, anim *Animation, time float32, loop bool, alpha float32) {
+	bones := skeleton.Bones
+	if cap(s.pose) < len(bones) {
+		s.pose = make([]bonePose, len(bones))
+	}
+	pose := s.pose[:len(bones)]
+	for i, b := range bones {
+		pose[i] = bonePose{b.X, b.Y, b.Rotation, b.ScaleX, b.ScaleY}
+	}
+	skeleton.setBonesToSetupPose()
+	anim.Apply(skeleton, time, loop)
+	for i, b := range bones {
+		p, d := &pose[i], b.Data
+		b.X = p.x + (b.X-d.x)*alpha
+		b.Y = p.y + (b.Y-d.y)*alpha
+		b.Rotation = p.rotation + (b.Rotation-d.rotation)*alpha
+		b.ScaleX = p.scaleX + (b.ScaleX-d.scaleX)*alpha
+		b.ScaleY = p.scaleY + (b.ScaleY-d.scaleY)*alpha
+	}
+}
+
+// Events returns the events fired in the last Apply.
+func (s *AnimationState) Events() []*Event {
+	return s.events
+}
+
+// ClearTracks removes all the tracks, the skeleton keeps the current pose.
+func (s *AnimationState) ClearTracks() {
+	for i := range s.tracks {
+		s.ClearTrack(i)
+	}
+	s.tracks = s.tracks[:0]
+}
+
+// ClearTrack removes the current and the queued entries of the track.
+func (s *AnimationState) ClearTrack(track int) {
+	if track >= len(s.tracks) {
+		return
+	}
+	current := s.tracks[track]
+	if current == nil {
+		return
+	}
+	s.fireEnd(track, current)
+	s.tracks[track] = nil
+}
+
+func (s *AnimationState) expand(track int) *TrackEntry {
+	if track < len(s.tracks) {
+		return s.tracks[track]
+	}
+	for len(s.tracks) <= track {
+		s.tracks = append(s.tracks, nil)
+	}
+	return nil
+}
+
+func (s *AnimationState) setCurrent(track int, entry *TrackEntry) {
+	current := s.expand(track)
+	if current != nil {
+		previous := current.previous
+		current.previous = nil
+		s.fireEnd(track, current)
+
+		entry.MixDuration = s.Data.Mix(current.Animation, entry.Animation)
+		if entry.MixDuration > 0 {
+			entry.MixTime = 0
+			// If a mix is in progress, mix from the closest animation.
+			if previous != nil && current.MixTime/current.MixDuration < 0.5 {
+				entry.previous = previous
+			} else {
+				entry.previous = current
+			}
+		}
+	}
+	s.tracks[track] = entry
+
+	if l := entry.Listener; l != nil {
+		l.OnStart(track, entry)
+	}
+	for _, l := range s.listeners {
+		l.OnStart(track, entry)
+	}
+}
+
+func (s *AnimationState) fireEnd(track int, entry *TrackEntry) {
+	if l := entry.Listener; l != nil {
+		l.OnEnd(track, entry)
+	}
+	for _, l := range s.listeners {
+		l.OnEnd(track, entry)
+	}
+}
+
+// SetAnimation plays the animation in the track, the queued entries are
+// removed, returns nil if the animation is not found.
+func (s *AnimationState) SetAnimation(track int, name string, loop bool) *TrackEntry {
+	_, anim := s.Data.SkeletonData.findAnimation(name)
+	if anim == nil {
+		return nil
+	}
+	return s.SetAnimationData(track, anim, loop)
+}
+
+func (s *AnimationState) SetAnimationData(track int, anim *Animation, loop bool) *TrackEntry {
+	if current := s.expand(track); current != nil {
+		current.next = nil
+	}
+	entry := newTrackEntry(anim, loop)
+	s.setCurrent(track, entry)
+	return entry
+}
+
+// AddAnimation queues the animation after the last entry of the track.
+// The entry is started delay seconds after the previous entry is started,
+// if delay <= 0, it's started when the previous entry ends minus the mix
+// duration. Returns nil if the animation is not found.
+func (s *AnimationState) AddAnimation(track int, name string, loop bool, delay float32) *TrackEntry {
+	_, anim := s.Data.SkeletonData.findAnimation(name)
+	if anim == nil {
+		return nil
+	}
+	return s.AddAnimationData(track, anim, loop, delay)
+}
+
+func (s *AnimationState) AddAnimationData(track int, anim *Animation, loop bool, delay float32) *TrackEntry {
+	entry := newTrackEntry(anim, loop)
+
+	last := s.expand(track)
+	if last != nil {
+		for last.next != nil {
+			last = last.next
+		}
+		last.next = entry
+	} else {
+		s.setCurrent(track, entry)
+	}
+
+	if delay <= 0 {
+		if last != nil {
+			delay += last.EndTime - s.Data.Mix(last.Animation, anim)
+		} else {
+			delay = 0
+		}
+	}
+	entry.Delay = delay
+	return entry
+}
+
+// Current returns the current entry of the track, or nil.
+func (s *AnimationState) Current(track int) *TrackEntry {
+	if track < len(s.tracks) {
+		return s.tracks[track]
+	}
+	return nil
+}
+
+// Tracks returns the number of tracks.
+func (s *AnimationState) Tracks() int {
+	return len(s.tracks)
+}
+
+// Empty returns true if no animation is playing.
+func (s *AnimationState) Empty() bool {
+	for _, t := range s.tracks {
+		if t != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func mod(a, b float32) float32 {
+	return float32(math.Mod(float64(a), float64(b)))
+}
@@ -0,0 +1,125 @@
+package spine
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type testListener struct {
+	log []string
+}
+
+func (l *testListener) OnStart(track int, entry *TrackEntry) {
+	l.log = append(l.log, fmt.Sprintf("start %d %s", track, entry.Animation.Name()))
+}
+
+func (l *testListener) OnEnd(track int, entry *TrackEntry) {
+	l.log = append(l.log, fmt.Sprintf("end %d %s", track, entry.Animation.Name()))
+}
+
+func (l *testListener) OnComplete(track int, entry *TrackEntry, loopCount int) {
+	l.log = append(l.log, fmt.Sprintf("complete %d %s %d", track, entry.Animation.Name(), loopCount))
+}
+
+func (l *testListener) OnEvent(track int, entry *TrackEntry, event *Event) {
+	l.log = append(l.log, fmt.Sprintf("event %d %s %d", track, event.Name(), event.Int))
+}
+
+func newTestState(data *SkeletonData) (*Skeleton, *AnimationState) {
+	sk := NewSkeleton(data)
+	sk.SetToSetupPose()
+	return sk, NewAnimationState(NewAnimationStateData(data))
+}
+
+func stepState(sk *Skeleton, state *AnimationState, dt float32) {
+	state.Update(dt)
+	sk.setBonesToSetupPose()
+	state.Apply(sk)
+}
+
+func TestAnimationStateQueue(t *testing.T) {
+	sk, state := newTestState(newTestData(t))
+	l := &testListener{}
+	state.AddListener(l)
+
+	state.SetAnimation(0, "walk", false)
+	state.AddAnimation(0, "idle", true, 0)
+	stepState(sk, state, .5)
+	if e := state.Current(0); e.Animation.Name() != "walk" {
+		t.Errorf("current: %s", e.Animation.Name())
+	}
+	stepState(sk, state, .6)
+	stepState(sk, state, .1)
+	if e := state.Current(0); e.Animation.Name() != "idle" || e.previous != nil {
+		t.Errorf("idle should be started without mixing: %s", e.Animation.Name())
+	}
+
+	want := "start 0 walk,complete 0 walk 1,end 0 walk,start 0 idle"
+	if s := strings.Join(l.log, ","); s != want {
+		t.Errorf("listener: %s", s)
+	}
+}
+
+func TestAnimationStateMix(t *testing.T) {
+	sk, state := newTestState(newTestData(t))
+	state.Data.DefaultMix = .2
+	if !state.Data.SetMix("idle", "walk", .4) || state.Data.SetMix("idle", "run", 1) {
+		t.Error("set mix of unknown animation")
+	}
+	_, arm := sk.FindBone("arm")
+
+	state.SetAnimation(0, "idle", true)
+	stepState(sk, state, .1)
+	entry := state.SetAnimation(0, "walk", true)
+	if entry.MixDuration != .4 {
+		t.Errorf("mix duration: %f", entry.MixDuration)
+	}
+	stepState(sk, state, .2)
+	if arm.X != 60 {
+		t.Errorf("half mixed: %f", arm.X)
+	}
+	stepState(sk, state, .2)
+	if arm.X != 110 || entry.previous != nil {
+		t.Errorf("mix should be done: %f", arm.X)
+	}
+
+	// default mix
+	if entry = state.SetAnimation(0, "idle", true); entry.MixDuration != .2 {
+		t.Errorf("default mix: %f", entry.MixDuration)
+	}
+}
+
+func TestAnimationStateAdditive(t *testing.T) {
+	sk, state := newTestState(newTestData(t))
+	_, arm := sk.FindBone("arm")
+
+	state.SetAnimation(0, "idle", true)
+	entry := state.SetAnimation(1, "walk", true)
+	entry.Additive, entry.Mix = true, .5
+	stepState(sk, state, .1)
+	if arm.X != 60 {
+		t.Errorf("additive layer: %f", arm.X)
+	}
+
+	state.ClearTrack(1)
+	stepState(sk, state, .1)
+	if arm.X != 10 {
+		t.Errorf("layer should be cleared: %f", arm.X)
+	}
+}
+
+func TestAnimationStateEvents(t *testing.T) {
+	sk, state := newTestState(newTestMeshData(t))
+	l := &testListener{}
+	entry := state.SetAnimation(0, "deform", true)
+	entry.Listener = l
+
+	for i := 0; i < 5; i++ {
+		stepState(sk, state, .3)
+	}
+	want := "event 0 hit 1,event 0 hit 5,event 0 hit 1,complete 0 deform 1,event 0 hit 5"
+	if s := strings.Join(l.log, ","); s != want {
+		t.Errorf("listener: %s", s)
+	}
+}
@@ -0,0 +1,301 @@
+package asset
+
+import (
+	"korok.io/korok/asset/res"
+	"korok.io/korok/audio/sine"
+	"korok.io/korok/gfx/font"
+
+	"image"
+	"log"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+type assetType uint8
+
+const (
+	assetTexture assetType = iota
+	assetAtlas
+	assetBitmapFont
+	assetTrueType
+	assetAudio
+)
+
+// 一个异步加载任务
+type loadTask struct {
+	typ              assetType
+	name, file, desc string
+	stream           bool
+	ttf              font.TTFConfig
+
+	// 资源已经加载, 只需增加引用计数
+	loaded bool
+
+	// 解码后的数据
+	img   image.Image
+	atlas *atlas
+	font  *font.Decoded
+	pcm   *sine.PCM
+	err   error
+}
+
+// Batch loads a group of assets asynchronously. The files are read and
+// decoded in worker goroutines, then the decoded data is uploaded (to bk
+// or the audio device) in the render thread by Upload, a few assets per
+// frame. The loaded assets are managed by the global managers, they're
+// same as the assets loaded by the Load methods.
+type Batch struct {
+	tasks   []*loadTask
+	decoded chan *loadTask
+
+	nDecoded  int32
+	nUploaded int
+	started   bool
+}
+
+func NewBatch() *Batch {
+	return &Batch{}
+}
+
+// Texture adds a texture to the batch, see TextureManager.Load.
+func (b *Batch) Texture(file string) *Batch {
+	return b.add(&loadTask{typ: assetTexture, file: file})
+}
+
+// Atlas adds an atlas to the batch, see TextureManager.LoadAtlas.
+func (b *Batch) Atlas(file, desc string) *Batch {
+	return b.add(&loadTask{typ: assetAtlas, file: file, desc: desc})
+}
+
+// BitmapFont adds a bitmap font to the batch, see FontManager.LoadBitmap.
+func (b *Batch) BitmapFont(name string, img, fc string) *Batch {
+	return b.add(&loadTask{typ: assetBitmapFont, name: name, file: img, desc: fc})
+}
+
+// TrueType adds a true-type font to the batch, see FontManager.LoadTrueType.
+func (b *Batch) TrueType(name string, file string, lc font.TTFConfig) *Batch {
+	return b.add(&loadTask{typ: assetTrueType, name: name, file: file, ttf: lc})
+}
+
+// Audio adds a sound to the batch, see AudioManager.Load. A stream
+// sound is decoded while playing, so it's not decoded here.
+func (b *Batch) Audio(file string, stream bool) *Batch {
+	return b.add(&loadTask{typ: assetAudio, file: file, stream: stream})
+}
+
+func (b *Batch) add(t *loadTask) *Batch {
+	if b.started {
+		log.Println("batch is started, can't add asset:", t.file)
+		return b
+	}
+	b.tasks = append(b.tasks, t)
+	return b
+}
+
+// Start starts n worker goroutines to decode the assets, n <= 0 means
+// the number of CPUs. It must be called in the render thread.
+func (b *Batch) Start(n int) {
+	if b.started {
+		return
+	}
+	b.started = true
+
+	size := len(b.tasks)
+	b.decoded = make(chan *loadTask, size)
+	jobs := make(chan *loadTask, size)
+	for _, t := range b.tasks {
+		t.loaded = t.isLoaded()
+		jobs <- t
+	}
+	close(jobs)
+
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	if n > size {
+		n = size
+	}
+	for i := 0; i < n; i++ {
+		go func() {
+			for t := range jobs {
+				if !t.loaded {
+					t.decode()
+				}
+				atomic.AddInt32(&b.nDecoded, 1)
+				b.decoded <- t
+			}
+		}()
+	}
+}
+
+// Upload uploads the decoded assets until the budget is used up, at
+// least one decoded asset is uploaded in each call, so a zero budget
+// means one asset per call. It returns true if all assets are loaded.
+// It must be called in the render thread.
+func (b *Batch) Upload(budget time.Duration) bool {
+	if !b.started {
+		b.Start(0)
+	}
+	start := time.Now()
+	for b.nUploaded < len(b.tasks) {
+		select {
+		case t := <-b.decoded:
+			t.upload()
+			b.nUploaded++
+		default:
+			return false
+		}
+		if time.Since(start) >= budget {
+			break
+		}
+	}
+	return b.Done()
+}
+
+// Progress returns the loading progress in [0, 1], decoding and
+// uploading take half each.
+func (b *Batch) Progress() float32 {
+	size := len(b.tasks)
+	if size == 0 {
+		return 1
+	}
+	n := int(atomic.LoadInt32(&b.nDecoded)) + b.nUploaded
+	return float32(n) / float32(size*2)
+}
+
+// Done returns true if all assets are uploaded.
+func (b *Batch) Done() bool {
+	return b.nUploaded == len(b.tasks)
+}
+
+// Unload unloads all the assets in the batch.
+func (b *Batch) Unload() {
+	for _, t := range b.tasks {
+		switch t.typ {
+		case assetTexture, assetAtlas:
+			Texture.Unload(t.file)
+		case assetBitmapFont, assetTrueType:
+			Font.Unload(t.name)
+		case assetAudio:
+			Audio.Unload(t.file)
+		}
+	}
+}
+
+func (t *loadTask) isLoaded() (ok bool) {
+	switch t.typ {
+	case assetTexture, assetAtlas:
+		_, ok = Texture.repo[t.file]
+	case assetBitmapFont, assetTrueType:
+		_, ok = Font.repo[t.name]
+	case assetAudio:
+		_, ok = Audio.repo[t.file]
+	}
+	return
+}
+
+// 在工作 goroutine 中读取并解码
+func (t *loadTask) decode() {
+	switch t.typ {
+	case assetTexture:
+		t.img, t.err = decodeTexture(t.file)
+	case assetAtlas:
+		t.img, t.atlas, t.err = decodeAtlas(t.file, t.desc)
+	case assetBitmapFont:
+		ir, err := res.Open(t.file)
+		if err != nil {
+			t.err = err
+			return
+		}
+		defer ir.Close()
+		fcr, err := res.Open(t.desc)
+		if err != nil {
+			t.err = err
+			return
+		}
+		defer fcr.Close()
+		t.font, t.err = font.DecodeBitmap(ir, fcr, 1)
+	case assetTrueType:
+		r, err := res.Open(t.file)
+		if err != nil {
+			t.err = err
+			return
+		}
+		defer r.Close()
+		t.font, t.err = font.DecodeTrueType(r, t.ttf)
+	case assetAudio:
+		if typ := audioType(t.file); typ != sine.None && !t.stream {
+			t.pcm, t.err = sine.Decode(t.file, typ)
+		}
+	}
+}
+
+// 在渲染线程中上传, 如果资源已被加载只增加引用计数
+func (t *loadTask) upload() {
+	if t.err != nil {
+		log.Println(t.err)
+		return
+	}
+	switch t.typ {
+	case assetTexture:
+		if Texture.ref(t.file) {
+			return
+		}
+		if t.img == nil {
+			Texture.Load(t.file)
+			return
+		}
+		id, err := allocTexture(t.img)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		Texture.repo[t.file] = idCount{id, 1}
+	case assetAtlas:
+		if Texture.ref(t.file) {
+			return
+		}
+		if t.img == nil {
+			Texture.LoadAtlas(t.file, t.desc)
+			return
+		}
+		id, err := allocTexture(t.img)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		Texture.newAtlas(t.file, id, t.atlas)
+		Texture.repo[t.file] = idCount{id, 1}
+	case assetBitmapFont, assetTrueType:
+		if Font.ref(t.name) {
+			return
+		}
+		if t.font == nil {
+			if t.typ == assetBitmapFont {
+				Font.LoadBitmap(t.name, t.file, t.desc)
+			} else {
+				Font.LoadTrueType(t.name, t.file, t.ttf)
+			}
+			return
+		}
+		fnt, err := t.font.Upload()
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		Font.repo[t.name] = refCount{fnt, 1}
+	case assetAudio:
+		if Audio.ref(t.file) {
+			return
+		}
+		if t.pcm == nil {
+			Audio.Load(t.file, t.stream)
+			return
+		}
+		id, _ := sine.R.LoadPCM(t.pcm)
+		Audio.repo[t.file] = idCount{id, 1}
+	}
+	// release the decoded data
+	t.img, t.atlas, t.font, t.pcm = nil, nil, nil, nil
+}
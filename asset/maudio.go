@@ -45,6 +45,15 @@ func (am *AudioManager) Unload(file string) {
 	}
 }
 
+// 增加已加载音频的引用计数
+func (am *AudioManager) ref(file string) bool {
+	if v, ok := am.repo[file]; ok {
+		am.repo[file] = idCount{v.rid, v.cnt + 1}
+		return true
+	}
+	return false
+}
+
 func (am *AudioManager) Get(file string) (id uint16, ok bool){
 	if v, ook := am.repo[file]; ook {
 		id = v.rid
@@ -59,6 +68,10 @@ func audioType(name string) sine.FileType {
 		return sine.WAV
 	case strings.HasSuffix(name, ".ogg"):
 		return sine.VORB
+	case strings.HasSuffix(name, ".flac"):
+		return sine.FLAC
+	case strings.HasSuffix(name, ".mp3"):
+		return sine.MP3
 	default:
 		return sine.None
 	}
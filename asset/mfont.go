@@ -74,6 +74,15 @@ func (fm *FontManager) LoadTrueType(name string, file string, lc font.TTFConfig)
 	fmt.Println("load true-type font sucess...", name)
 }
 
+// 增加已加载字体的引用计数
+func (fm *FontManager) ref(name string) bool {
+	if v, ok := fm.repo[name]; ok {
+		fm.repo[name] = refCount{v.ref, v.cnt + 1}
+		return true
+	}
+	return false
+}
+
 func (fm *FontManager) Unload(name string) {
 	if v, ok := fm.repo[name]; ok {
 		if v.cnt > 1 {
@@ -95,3 +104,13 @@ func (fm *FontManager) Get(name string) (fnt font.Font, ok bool) {
 	}
 	return
 }
+
+// Name returns the name of a loaded font.
+func (fm *FontManager) Name(fnt font.Font) (name string, ok bool) {
+	for k, v := range fm.repo {
+		if v.ref == fnt {
+			return k, true
+		}
+	}
+	return
+}
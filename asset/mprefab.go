@@ -0,0 +1,69 @@
+package asset
+
+import (
+	"korok.io/korok/asset/res"
+
+	"io/ioutil"
+	"log"
+)
+
+// 预制体文件管理, 预制体使用和场景相同的文件格式(见 game.SaveScene),
+// 这里只保存文件数据, 由 game.DB.Instantiate 解析并创建 Entity.
+type PrefabManager struct {
+	repo map[string]refCount
+}
+
+func NewPrefabManager() *PrefabManager {
+	return &PrefabManager{
+		repo: make(map[string]refCount),
+	}
+}
+
+// Load loads a prefab file, the file name is the name of prefab.
+func (pm *PrefabManager) Load(file string) {
+	if rc, ok := pm.repo[file]; ok {
+		pm.repo[file] = refCount{rc.ref, rc.cnt + 1}
+	} else {
+		data, err := pm.load(file)
+		if err != nil {
+			log.Println(err)
+		} else {
+			pm.repo[file] = refCount{data, 1}
+		}
+	}
+}
+
+// Add adds a prefab from memory.
+func (pm *PrefabManager) Add(name string, data []byte) {
+	if rc, ok := pm.repo[name]; ok {
+		pm.repo[name] = refCount{data, rc.cnt + 1}
+	} else {
+		pm.repo[name] = refCount{data, 1}
+	}
+}
+
+func (pm *PrefabManager) Unload(file string) {
+	if rc, ok := pm.repo[file]; ok {
+		if rc.cnt > 1 {
+			pm.repo[file] = refCount{rc.ref, rc.cnt - 1}
+		} else {
+			delete(pm.repo, file)
+		}
+	}
+}
+
+func (pm *PrefabManager) Get(name string) (data []byte, exist bool) {
+	if rc, ok := pm.repo[name]; ok {
+		data, exist = rc.ref.([]byte)
+	}
+	return
+}
+
+func (pm *PrefabManager) load(file string) (data []byte, err error) {
+	reader, err := res.Open(file)
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
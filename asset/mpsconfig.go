@@ -86,7 +86,7 @@ func (pcm *ParticleConfigManager) load(file string) (ref interface{}, err error)
 		g.TangentialAcc = effect.Var{cfg.TangentialAccel, cfg.TangentialAccelVar}
 		g.RotationIsDir = cfg.RotationIsDir
 	} else {
-		r := effect.RadiusConfig{}
+		r := &effect.RadiusConfig{}
 		ref = r
 		config = &r.Config
 		r.Radius = effect.Range{
@@ -71,15 +71,7 @@ func (tm *TextureManager) LoadAtlas(file, desc string) {
 			log.Println(err)
 			return
 		}
-		size := len(data.Frames)
-
-		// new atlas
-		at := gfx.R.NewAtlas(id, size, file)
-
-		// fill
-		for _, f := range data.Frames {
-			at.AddItem(float32(f.Frame.X), float32(f.Frame.Y), float32(f.Frame.W), float32(f.Frame.H), f.Filename, f.Rotated)
-		}
+		tm.newAtlas(file, id, data)
 		rid = id
 	}
 	tm.repo[file] = idCount{rid, cnt + 1}
@@ -136,19 +128,52 @@ func (tm *TextureManager) Atlas(file string) (at *gfx.Atlas, ok bool) {
 	return
 }
 
+// Name returns the file name of a loaded Texture, for SubTexture, it
+// returns the file name of the atlas and the name of the SubTexture.
+func (tm *TextureManager) Name(tex gfx.Tex2D) (file, sub string, ok bool) {
+	if st, ok1 := tex.(gfx.SubTex); ok1 {
+		file, sub, ok = gfx.R.Name(st)
+		return
+	}
+	if tex == nil {
+		return
+	}
+	id := tex.Tex()
+	for k, v := range tm.repo {
+		if v.rid == id {
+			return k, "", true
+		}
+	}
+	return
+}
+
 func (tm *TextureManager) loadTexture(file string) (uint16, error) {
+	img, err := decodeTexture(file)
+	if err != nil {
+		return bk.InvalidId, err
+	}
+	return allocTexture(img)
+}
+
+// 读取并解码图片, 可以在其它 goroutine 中调用
+func decodeTexture(file string) (image.Image, error) {
 	log.Println("load file:" + file)
 	// 1. load file
 	imgFile, err := res.Open(file)
 	if err != nil {
-		return bk.InvalidId, fmt.Errorf("texture %q not found: %v", file, err)
+		return nil, fmt.Errorf("texture %q not found: %v", file, err)
 	}
+	defer imgFile.Close()
 	// 2. decode image
 	img, _, err := image.Decode(imgFile)
 	if err != nil {
-		return bk.InvalidId, err
+		return nil, err
 	}
-	// 3. create raw texture
+	return img, nil
+}
+
+// 3. create raw texture, must be called in render thread
+func allocTexture(img image.Image) (uint16, error) {
 	if id, _ := bk.R.AllocTexture(img); id != bk.InvalidId {
 		return id, nil
 	}
@@ -157,18 +182,29 @@ func (tm *TextureManager) loadTexture(file string) (uint16, error) {
 
 // 加载纹理图集
 func (tm *TextureManager) loadAtlas(img, desc string) (id uint16, at *atlas, e error) {
-	id, err := tm.loadTexture(img)
+	pix, at, err := decodeAtlas(img, desc)
 	if err != nil {
 		e = err
 		return
 	}
-	file, err := res.Open(desc)
-	defer file.Close()
+	id, e = allocTexture(pix)
+	return
+}
 
+// 读取并解码纹理图集, 可以在其它 goroutine 中调用
+func decodeAtlas(img, desc string) (pix image.Image, at *atlas, e error) {
+	pix, err := decodeTexture(img)
 	if err != nil {
 		e = err
 		return
 	}
+	file, err := res.Open(desc)
+	if err != nil {
+		e = err
+		return
+	}
+	defer file.Close()
+
 	d, err := ioutil.ReadAll(file)
 	if err != nil {
 		e = err
@@ -179,6 +215,28 @@ func (tm *TextureManager) loadAtlas(img, desc string) (id uint16, at *atlas, e e
 	return
 }
 
+// 用图集的描述创建 Atlas
+func (tm *TextureManager) newAtlas(file string, id uint16, data *atlas) {
+	size := len(data.Frames)
+
+	// new atlas
+	at := gfx.R.NewAtlas(id, size, file)
+
+	// fill
+	for _, f := range data.Frames {
+		at.AddItem(float32(f.Frame.X), float32(f.Frame.Y), float32(f.Frame.W), float32(f.Frame.H), f.Filename, f.Rotated)
+	}
+}
+
+// 增加已加载资源的引用计数
+func (tm *TextureManager) ref(file string) bool {
+	if v, ok := tm.repo[file]; ok {
+		tm.repo[file] = idCount{v.rid, v.cnt + 1}
+		return true
+	}
+	return false
+}
+
 // Field int `json:"myName"`
 // The file format is TexturePacker's generic json-array format.
 // TexturePacker: https://www.codeandweb.com/texturepacker
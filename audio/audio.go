@@ -2,6 +2,8 @@ package audio
 
 import (
 	"korok.io/korok/audio/sine"
+
+	"time"
 )
 
 type MusicPlayer struct {
@@ -11,14 +13,22 @@ type MusicPlayer struct {
 type ChanId int
 
 var (
-	music *sine.StreamPlayer
+	music *MusicController
 	effects *sine.SoundPool
+
+	musicVolume, effectVolume float32 = 1, 1
+
+	// time of last AdvanceFrame
+	lastFrame time.Time
 )
 
 func Init() (err error) {
 	sine.Init(DefaultDecoderFactory)
-	music   = sine.NewStreamPlayer()
+	a, b   := sine.NewStreamPlayer(), sine.NewStreamPlayer()
+	music   = newMusicController(a, b)
 	effects = sine.NewSoundPool()
+	a.SetBus(BusMusic)
+	b.SetBus(BusMusic)
 	return
 }
 
@@ -26,23 +36,42 @@ func Destroy() {
 	sine.Destroy()
 }
 
+// AdvanceFrame updates the players and buses, the frame time is
+// measured from the last call.
 func AdvanceFrame() {
-	music.Tick()
+	now := time.Now()
+	dt := float32(0)
+	if !lastFrame.IsZero() {
+		dt = float32(now.Sub(lastFrame).Seconds())
+	}
+	lastFrame = now
+	Update(dt)
+}
+
+// Update updates the players and buses with the frame time, dt is
+// in seconds.
+func Update(dt float32) {
+	music.Update(dt)
 	effects.Tick()
+	updateBuses(dt)
 }
 
 ////////////////////// Music ////////////////////
 
 func PlayMusic(id uint16) (sp MusicPlayer, ook bool){
-	if sound, ok := sine.R.Sound(id); ok {
-		if d, ok := sound.Data.(*sine.StreamData); ok {
-			music.Play(d)
-			sp, ook = MusicPlayer{music}, true
-		}
+	if music.Play(Track{Id: id}, 0) {
+		p, _ := music.player().(*sine.StreamPlayer)
+		sp, ook = MusicPlayer{p}, true
 	}
 	return
 }
 
+// Music returns the music controller, which supports playlist, loop
+// points and crossfade.
+func Music() *MusicController {
+	return music
+}
+
 func PauseMusic() {
 	music.Pause()
 }
@@ -52,21 +81,41 @@ func ResumeMusic() {
 }
 
 func StopMusic() {
-	music.Stop()
+	music.Stop(0)
 }
 
+// SetMusicVolume sets the volume of music, it's scaled by the music bus.
 func SetMusicVolume(v float32) {
-	music.SetVolume(v)
+	musicVolume = v
+	applyMusic()
 }
 
 func MusicVolume() float32 {
-	return music.Volume()
+	return musicVolume
 }
 
 ////////////////////// Effect ////////////////////
 
+// PlayEffect plays the sound on the sfx bus.
 func PlayEffect(id uint16, priority int) (cid ChanId){
-	return ChanId(effects.Play(id, priority))
+	return PlaySound(id, priority, BusSfx)
+}
+
+// PlaySound plays the sound on the bus, the sound is played with the
+// sfx bus if the bus not exist.
+func PlaySound(id uint16, priority int, bus string) (cid ChanId) {
+	b, ok := buses[bus]
+	if !ok {
+		b = buses[BusSfx]
+	}
+	if cid = player.Play(id, priority); cid == 0 {
+		return
+	}
+	ch := &channel{bus: b, volume: 1}
+	channels[cid] = ch
+	player.SetBus(cid, b.name)
+	applyChannel(cid, ch)
+	return
 }
 
 func PauseEffect(cid ChanId) {
@@ -78,24 +127,52 @@ func ResumeEffect(cid ChanId) {
 }
 
 func StopEffect(cid ChanId) {
-	effects.StopChan(int(cid))
+	player.Stop(cid)
+	delete(channels, cid)
 }
 
+// EffectChannelVolume returns the volume of the channel, not scaled
+// by the bus.
 func EffectChannelVolume(cid ChanId) (v float32, ok bool){
-	return effects.GetChanVolume(int(cid))
+	if ch, ok := channels[cid]; ok {
+		return ch.volume, true
+	}
+	return 0, false
 }
 
+// SetEffectChannelVolume sets the volume of the channel, the volume
+// is scaled by the bus of the channel.
 func SetEffectChannelVolume(cid ChanId, v float32) {
-	effects.SetChanVolume(int(cid), v)
+	if ch, ok := channels[cid]; ok {
+		ch.volume = v
+		applyChannel(cid, ch)
+	}
+}
+
+// SetEffectChannelPan sets the stereo position of the channel, pan is
+// in [-1, 1], -1 is left and 1 is right.
+func SetEffectChannelPan(cid ChanId, pan float32) {
+	if ch, ok := channels[cid]; ok {
+		ch.pan = pan
+		applyChannel(cid, ch)
+	}
 }
 
-// Overall volume setting.
+// EffectPlaying returns true if the channel is still playing.
+func EffectPlaying(cid ChanId) bool {
+	return player.Playing(cid)
+}
+
+// Overall volume setting, all the channels are scaled by it.
 func EffectVolume() float32 {
-	return effects.Volume()
+	return effectVolume
 }
 
 func SetEffectVolume(v float32) {
-	effects.SetVolume(v)
+	effectVolume = v
+	for cid, ch := range channels {
+		applyChannel(cid, ch)
+	}
 }
 
 
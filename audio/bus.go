@@ -0,0 +1,321 @@
+package audio
+
+import (
+	"korok.io/korok/audio/sine"
+
+	"log"
+)
+
+// names of the default buses, music/sfx/voice/ui are children of master.
+const (
+	BusMaster = "master"
+	BusMusic  = "music"
+	BusSfx    = "sfx"
+	BusVoice  = "voice"
+	BusUI     = "ui"
+)
+
+// Bus groups sounds to control their volume together. The volume of a
+// sound is scaled by its bus and all the parent buses. Effects(like
+// sine.LowPass, sine.Reverb) process the mixed audio of the bus, they
+// only work with the software mixer(build with tag 'mixer').
+type Bus struct {
+	name   string
+	parent *Bus
+
+	volume float32
+	mute   bool
+
+	// volume scaled by snapshots
+	duck float32
+
+	effects []sine.Filter
+}
+
+var buses = make(map[string]*Bus)
+
+// NewBus creates a bus under the parent, the parent is master if nil.
+// Returns the old bus if the name is used.
+func NewBus(name string, parent *Bus) *Bus {
+	if b, ok := buses[name]; ok {
+		return b
+	}
+	if parent == nil {
+		parent = buses[BusMaster]
+	}
+	b := &Bus{name: name, parent: parent, volume: 1, duck: 1}
+	buses[name] = b
+	if parent != nil {
+		sine.SetBusParent(name, parent.name)
+	}
+	return b
+}
+
+// GetBus returns the bus of the name.
+func GetBus(name string) (b *Bus, ok bool) {
+	b, ok = buses[name]
+	return
+}
+
+func (b *Bus) Name() string {
+	return b.name
+}
+
+func (b *Bus) Parent() *Bus {
+	return b.parent
+}
+
+// SetVolume sets the volume of the bus, in [0, 1].
+func (b *Bus) SetVolume(v float32) {
+	b.volume = v
+}
+
+func (b *Bus) Volume() float32 {
+	return b.volume
+}
+
+func (b *Bus) SetMute(mute bool) {
+	b.mute = mute
+}
+
+func (b *Bus) Mute() bool {
+	return b.mute
+}
+
+// Gain returns the final volume of the bus, scaled by the parent
+// buses and the active snapshots.
+func (b *Bus) Gain() float32 {
+	g := float32(1)
+	for p := b; p != nil; p = p.parent {
+		if p.mute {
+			return 0
+		}
+		g *= p.volume * p.duck
+	}
+	return g
+}
+
+// AddEffect appends the effect to the end of the chain.
+func (b *Bus) AddEffect(f sine.Filter) {
+	b.effects = append(b.effects, f)
+	sine.SetBusFilters(b.name, b.effects...)
+}
+
+func (b *Bus) RemoveEffect(f sine.Filter) {
+	for i, e := range b.effects {
+		if e == f {
+			b.effects = append(b.effects[:i], b.effects[i+1:]...)
+			break
+		}
+	}
+	sine.SetBusFilters(b.name, b.effects...)
+}
+
+func (b *Bus) ClearEffects() {
+	b.effects = b.effects[:0]
+	sine.SetBusFilters(b.name)
+}
+
+func (b *Bus) Effects() []sine.Filter {
+	return b.effects
+}
+
+// is the bus the ancestor(or itself) of x
+func (b *Bus) contains(x *Bus) bool {
+	for p := x; p != nil; p = p.parent {
+		if p == b {
+			return true
+		}
+	}
+	return false
+}
+
+// Snapshot scales the volume of buses when it's active, the scale
+// fades in Attack seconds when activated and fades out in Release
+// seconds when deactivated.
+type Snapshot struct {
+	Name string
+
+	// bus name -> volume scale
+	Volumes map[string]float32
+
+	Attack, Release float32
+
+	// 0 - 1
+	weight         float32
+	active, ducked bool
+}
+
+var snapshots []*Snapshot
+
+// AddSnapshot adds the snapshot, replaces the old one with same name.
+func AddSnapshot(s *Snapshot) {
+	for i, old := range snapshots {
+		if old.Name == s.Name {
+			snapshots[i] = s
+			return
+		}
+	}
+	snapshots = append(snapshots, s)
+}
+
+// RemoveSnapshot removes the snapshot of the name.
+func RemoveSnapshot(name string) {
+	for i, s := range snapshots {
+		if s.Name == name {
+			snapshots = append(snapshots[:i], snapshots[i+1:]...)
+			return
+		}
+	}
+}
+
+func findSnapshot(name string) *Snapshot {
+	for _, s := range snapshots {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+func ActivateSnapshot(name string) {
+	if s := findSnapshot(name); s != nil {
+		s.active = true
+	} else {
+		log.Println("snapshot not found:", name)
+	}
+}
+
+func DeactivateSnapshot(name string) {
+	if s := findSnapshot(name); s != nil {
+		s.active = false
+	}
+}
+
+// Weight returns how much the snapshot is applied, in [0, 1].
+func (s *Snapshot) Weight() float32 {
+	return s.weight
+}
+
+func (s *Snapshot) update(dt float32) {
+	target, t := float32(0), s.Release
+	if s.active || s.ducked {
+		target, t = 1, s.Attack
+	}
+	if t <= 0 {
+		s.weight = target
+		return
+	}
+	if step := dt / t; s.weight < target {
+		if s.weight += step; s.weight > target {
+			s.weight = target
+		}
+	} else if s.weight > target {
+		if s.weight -= step; s.weight < target {
+			s.weight = target
+		}
+	}
+}
+
+type ducking struct {
+	trigger  string
+	snapshot string
+}
+
+var duckings []ducking
+
+// Duck activates the snapshot when any sound of the trigger bus(or its
+// children) is playing. The default ducking lowers music with voice.
+func Duck(trigger, snapshot string) {
+	duckings = append(duckings, ducking{trigger, snapshot})
+}
+
+// ClearDucking removes all the ducking rules.
+func ClearDucking() {
+	duckings = duckings[:0]
+}
+
+// a playing sound of effect pool
+type channel struct {
+	bus         *Bus
+	volume, pan float32
+}
+
+var channels = make(map[ChanId]*channel)
+
+// updates snapshots and the volume of playing sounds
+func updateBuses(dt float32) {
+	for cid := range channels {
+		if !player.Playing(cid) {
+			delete(channels, cid)
+		}
+	}
+	for _, s := range snapshots {
+		s.ducked = false
+	}
+	for _, d := range duckings {
+		if s := findSnapshot(d.snapshot); s != nil && busPlaying(d.trigger) {
+			s.ducked = true
+		}
+	}
+	for _, b := range buses {
+		b.duck = 1
+	}
+	for _, s := range snapshots {
+		s.update(dt)
+		if s.weight == 0 {
+			continue
+		}
+		for name, v := range s.Volumes {
+			if b, ok := buses[name]; ok {
+				b.duck *= 1 + (v-1)*s.weight
+			}
+		}
+	}
+	for cid, ch := range channels {
+		applyChannel(cid, ch)
+	}
+	applyMusic()
+}
+
+func busPlaying(name string) bool {
+	b, ok := buses[name]
+	if !ok {
+		return false
+	}
+	for _, ch := range channels {
+		if b.contains(ch.bus) {
+			return true
+		}
+	}
+	if music != nil && b.contains(buses[BusMusic]) {
+		return music.Playing() && !music.paused
+	}
+	return false
+}
+
+func applyChannel(cid ChanId, ch *channel) {
+	player.Set(cid, ch.volume*effectVolume*ch.bus.Gain(), ch.pan)
+}
+
+func applyMusic() {
+	if music != nil {
+		for i := range music.slots {
+			music.slots[i].apply()
+		}
+	}
+}
+
+func init() {
+	master := NewBus(BusMaster, nil)
+	for _, name := range []string{BusMusic, BusSfx, BusVoice, BusUI} {
+		NewBus(name, master)
+	}
+	AddSnapshot(&Snapshot{
+		Name:    BusVoice,
+		Volumes: map[string]float32{BusMusic: .3},
+		Attack:  .2,
+		Release: .5,
+	})
+	Duck(BusVoice, BusVoice)
+}
@@ -0,0 +1,101 @@
+package audio
+
+import (
+	"testing"
+)
+
+func near(a, b float32) bool {
+	return a > b-1e-4 && a < b+1e-4
+}
+
+func TestBusGain(t *testing.T) {
+	master, _ := GetBus(BusMaster)
+	sfx, _ := GetBus(BusSfx)
+	defer func() {
+		master.SetVolume(1)
+		sfx.SetVolume(1)
+		sfx.SetMute(false)
+	}()
+
+	steps := NewBus("steps", sfx)
+	if steps.Parent() != sfx {
+		t.Fatal("parent should be sfx")
+	}
+	if b := NewBus("steps", nil); b != steps {
+		t.Error("bus should be reused")
+	}
+
+	master.SetVolume(.5)
+	sfx.SetVolume(.5)
+	steps.SetVolume(.8)
+	if g := steps.Gain(); !near(g, .2) {
+		t.Errorf("gain: expect .2, got %v", g)
+	}
+	sfx.SetMute(true)
+	if g := steps.Gain(); g != 0 {
+		t.Errorf("muted gain: expect 0, got %v", g)
+	}
+}
+
+func TestDucking(t *testing.T) {
+	fp := &fakePlayer{channels: make(map[ChanId]*fakeChannel)}
+	player = fp
+	defer func() { player = poolPlayer{} }()
+
+	music, _ := GetBus(BusMusic)
+	sfx := PlaySound(1, 0, BusSfx)
+	SetEffectChannelVolume(sfx, .5)
+	if ch := fp.channels[sfx]; ch.bus != BusSfx || !near(ch.volume, .5) {
+		t.Fatal("sfx channel:", ch.bus, ch.volume)
+	}
+
+	// voice lowers music in .2 seconds
+	voice := PlaySound(2, 0, BusVoice)
+	updateBuses(.1)
+	if g := music.Gain(); !near(g, .65) {
+		t.Errorf("attack: expect .65, got %v", g)
+	}
+	updateBuses(.1)
+	if g := music.Gain(); !near(g, .3) {
+		t.Errorf("ducked: expect .3, got %v", g)
+	}
+
+	// sfx bus is not ducked
+	if ch := fp.channels[sfx]; !near(ch.volume, .5) {
+		t.Errorf("sfx volume: expect .5, got %v", ch.volume)
+	}
+
+	// recover in .5 seconds after voice stopped
+	fp.channels[voice].playing = false
+	updateBuses(.25)
+	if g := music.Gain(); !near(g, .65) {
+		t.Errorf("release: expect .65, got %v", g)
+	}
+	updateBuses(.25)
+	if g := music.Gain(); !near(g, 1) {
+		t.Errorf("recovered: expect 1, got %v", g)
+	}
+	if _, ok := channels[voice]; ok {
+		t.Error("stopped channel should be removed")
+	}
+
+	// manual snapshot
+	AddSnapshot(&Snapshot{Name: "pause", Volumes: map[string]float32{BusSfx: 0}})
+	defer RemoveSnapshot("pause")
+	ActivateSnapshot("pause")
+	updateBuses(.1)
+	if ch := fp.channels[sfx]; ch.volume != 0 {
+		t.Errorf("snapshot volume: expect 0, got %v", ch.volume)
+	}
+	DeactivateSnapshot("pause")
+	updateBuses(.1)
+	if ch := fp.channels[sfx]; !near(ch.volume, .5) {
+		t.Errorf("snapshot volume: expect .5, got %v", ch.volume)
+	}
+	StopEffect(sfx)
+
+	RemoveSnapshot("pause")
+	if findSnapshot("pause") != nil {
+		t.Error("snapshot should be removed")
+	}
+}
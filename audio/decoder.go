@@ -4,6 +4,8 @@ import (
 	"korok.io/korok/audio/sine"
 	"korok.io/korok/audio/wav"
 	"korok.io/korok/audio/ogg"
+	"korok.io/korok/audio/flac"
+	"korok.io/korok/audio/mp3"
 
 	"fmt"
 )
@@ -17,6 +19,10 @@ func (df *decoderFactory) NewDecoder(name string, fileType sine.FileType) (sine.
 		return NewWavDecoder(name)
 	case sine.VORB:
 		return NewVorbisDecoder(name)
+	case sine.FLAC:
+		return NewFlacDecoder(name)
+	case sine.MP3:
+		return NewMp3Decoder(name)
 	}
 
 	return nil, fmt.Errorf("not support file type: %d", fileType)
@@ -29,3 +35,11 @@ func NewWavDecoder(name string) (sine.Decoder, error) {
 func NewVorbisDecoder(name string) (sine.Decoder, error) {
 	return ogg.NewVorbisDecoder(name)
 }
+
+func NewFlacDecoder(name string) (sine.Decoder, error) {
+	return flac.NewDecoder(name)
+}
+
+func NewMp3Decoder(name string) (sine.Decoder, error) {
+	return mp3.NewDecoder(name)
+}
@@ -0,0 +1,172 @@
+package flac
+
+import (
+	"github.com/mewkiz/flac"
+	"github.com/mewkiz/flac/frame"
+	"korok.io/korok/asset/res"
+
+	"io"
+	"log"
+	"unsafe"
+)
+
+// Decoder decodes FLAC audio, the samples are converted to 16-bit. Only
+// the first two channels are decoded if there are more channels.
+type Decoder struct {
+	numChannels int32
+	sampleRate  int32
+	bitDepth    int32
+
+	i16buffer []int16
+	size      int
+
+	name     string
+	file     res.File
+	stream   *flac.Stream
+	reachEnd bool
+}
+
+func (d *Decoder) NumOfChan() int32 {
+	return d.numChannels
+}
+
+func (d *Decoder) BitDepth() int32 {
+	return d.bitDepth
+}
+
+func (d *Decoder) SampleRate() int32 {
+	return d.sampleRate
+}
+
+func (d *Decoder) Buffer() []byte {
+	if d.size == 0 {
+		return nil
+	}
+	return ((*[1 << 30]byte)(unsafe.Pointer(&d.i16buffer[0])))[:d.size*2]
+}
+
+func (d *Decoder) ReachEnd() bool {
+	return d.reachEnd
+}
+
+// Length returns the number of sample frames, -1 if unknown.
+func (d *Decoder) Length() int64 {
+	if n := d.stream.Info.NSamples; n > 0 {
+		return int64(n)
+	}
+	return -1
+}
+
+// DON'T change decoder state!
+func (*Decoder) FullDecode(file res.File) (data []byte, numChan, bitDepth, freq int32, err error) {
+	defer file.Close()
+	stream, err := flac.New(file)
+	if err != nil {
+		return
+	}
+	numChan, bitDepth, freq = channels(stream), 16, int32(stream.Info.SampleRate)
+
+	var i16s []int16
+	for {
+		f, e := stream.ParseNext()
+		if e == io.EOF {
+			break
+		} else if e != nil {
+			err = e
+			return
+		}
+		i16s = interleave(f, int(numChan), int(stream.Info.BitsPerSample), i16s)
+	}
+	if len(i16s) > 0 {
+		data = ((*[1 << 30]byte)(unsafe.Pointer(&i16s[0])))[:len(i16s)*2]
+	}
+	return
+}
+
+// decodes one frame
+func (d *Decoder) Decode() int {
+	f, err := d.stream.ParseNext()
+	if err != nil {
+		if err != io.EOF {
+			log.Println("flac decode err:", err)
+		}
+		d.reachEnd = true
+		d.size = 0
+		return 0
+	}
+	d.i16buffer = interleave(f, int(d.numChannels), int(d.stream.Info.BitsPerSample), d.i16buffer[:0])
+	d.size = len(d.i16buffer)
+	return d.size
+}
+
+func (d *Decoder) head() error {
+	if f := d.file; f != nil {
+		f.Close()
+	}
+	f, err := res.Open(d.name)
+	if err != nil {
+		return err
+	}
+	return d.open(f)
+}
+
+func (d *Decoder) open(f res.File) error {
+	stream, err := flac.New(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	d.file = f
+	d.stream = stream
+	d.numChannels = channels(stream)
+	d.sampleRate = int32(stream.Info.SampleRate)
+	d.bitDepth = 16
+	d.size = 0
+	d.reachEnd = false
+	return nil
+}
+
+func (d *Decoder) Rewind() {
+	if err := d.head(); err != nil {
+		log.Println("flac rewind err:", err)
+	}
+}
+
+func NewDecoder(name string) (d *Decoder, err error) {
+	d = new(Decoder)
+	d.name = name
+	d.i16buffer = make([]int16, 0, 16384)
+	err = d.head()
+	return
+}
+
+func channels(stream *flac.Stream) int32 {
+	if n := stream.Info.NChannels; n < 2 {
+		return 1
+	}
+	return 2
+}
+
+// appends the samples of the frame to buf as interleaved 16-bit samples
+func interleave(f *frame.Frame, numChan, bps int, buf []int16) []int16 {
+	if len(f.Subframes) < numChan {
+		return buf
+	}
+	if bps == 0 {
+		bps = int(f.BitsPerSample)
+	}
+	for i, n := 0, len(f.Subframes[0].Samples); i < n; i++ {
+		for ch := 0; ch < numChan; ch++ {
+			buf = append(buf, to16(f.Subframes[ch].Samples[i], bps))
+		}
+	}
+	return buf
+}
+
+func to16(s int32, bps int) int16 {
+	if bps > 16 {
+		return int16(s >> uint(bps-16))
+	}
+	return int16(s << uint(16-bps))
+}
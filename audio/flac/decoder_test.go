@@ -0,0 +1,111 @@
+package flac
+
+import (
+	"github.com/mewkiz/flac"
+	"github.com/mewkiz/flac/frame"
+	"github.com/mewkiz/flac/meta"
+
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writes a 24-bit stereo flac file with two frames
+func writeFlac(t *testing.T, name string, left, right []int32) {
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &meta.StreamInfo{
+		BlockSizeMin:  16,
+		BlockSizeMax:  4096,
+		SampleRate:    48000,
+		NChannels:     2,
+		BitsPerSample: 24,
+	}
+	enc, err := flac.NewEncoder(f, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	half := len(left) / 2
+	for _, r := range [][2]int{{0, half}, {half, len(left)}} {
+		n := r[1] - r[0]
+		fr := &frame.Frame{
+			Header: frame.Header{
+				HasFixedBlockSize: false,
+				BlockSize:         uint16(n),
+				SampleRate:        48000,
+				Channels:          frame.ChannelsLR,
+				BitsPerSample:     24,
+			},
+			Subframes: []*frame.Subframe{
+				{SubHeader: frame.SubHeader{Pred: frame.PredVerbatim}, Samples: left[r[0]:r[1]], NSamples: n},
+				{SubHeader: frame.SubHeader{Pred: frame.PredVerbatim}, Samples: right[r[0]:r[1]], NSamples: n},
+			},
+		}
+		if err := enc.WriteFrame(fr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flac")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	left, right := make([]int32, 64), make([]int32, 64)
+	for i := range left {
+		left[i] = int32(i) << 8
+		right[i] = -int32(i) << 8
+	}
+	name := filepath.Join(dir, "a.flac")
+	writeFlac(t, name, left, right)
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, numChan, depth, freq, err := (&Decoder{}).FullDecode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numChan != 2 || depth != 16 || freq != 48000 || len(data) != 64*4 {
+		t.Fatalf("full decode: chan %d depth %d freq %d size %d", numChan, depth, freq, len(data))
+	}
+	for i := 0; i < 64; i++ {
+		l := int16(binary.LittleEndian.Uint16(data[i*4:]))
+		r := int16(binary.LittleEndian.Uint16(data[i*4+2:]))
+		if l != int16(i) || r != -int16(i) {
+			t.Fatalf("sample %d: got (%d, %d)", i, l, r)
+		}
+	}
+
+	// streaming, one frame each time
+	if f, err = os.Open(name); err != nil {
+		t.Fatal(err)
+	}
+	d := &Decoder{}
+	if err := d.open(f); err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for !d.ReachEnd() {
+		n := d.Decode()
+		if n > 0 && len(d.Buffer()) != n*2 {
+			t.Fatalf("buffer size: %d, decoded %d", len(d.Buffer()), n)
+		}
+		total += n
+	}
+	if total != 128 {
+		t.Errorf("stream decode: expect 128 samples, got %d", total)
+	}
+	d.file.Close()
+}
@@ -0,0 +1,124 @@
+package mp3
+
+import (
+	"github.com/hajimehoshi/go-mp3"
+	"korok.io/korok/asset/res"
+
+	"io"
+	"io/ioutil"
+	"log"
+)
+
+// Decoder decodes MPEG-1/2 Layer III audio, the output is always 16-bit
+// stereo.
+type Decoder struct {
+	numChannels int32
+	sampleRate  int32
+	bitDepth    int32
+
+	buffer []byte
+	// samples in buffer
+	size int
+
+	name     string
+	file     res.File
+	reader   *mp3.Decoder
+	reachEnd bool
+}
+
+func (d *Decoder) NumOfChan() int32 {
+	return d.numChannels
+}
+
+func (d *Decoder) BitDepth() int32 {
+	return d.bitDepth
+}
+
+func (d *Decoder) SampleRate() int32 {
+	return d.sampleRate
+}
+
+func (d *Decoder) Buffer() []byte {
+	return d.buffer[:d.size*2]
+}
+
+func (d *Decoder) ReachEnd() bool {
+	return d.reachEnd
+}
+
+// Length returns the number of sample frames, -1 if unknown.
+func (d *Decoder) Length() int64 {
+	if n := d.reader.Length(); n >= 0 {
+		return n / 4
+	}
+	return -1
+}
+
+// DON'T change decoder state!
+func (*Decoder) FullDecode(file res.File) (data []byte, numChan, bitDepth, freq int32, err error) {
+	defer file.Close()
+	r, err := mp3.NewDecoder(file)
+	if err != nil {
+		return
+	}
+	if data, err = ioutil.ReadAll(r); err != nil {
+		return
+	}
+	numChan, bitDepth, freq = 2, 16, int32(r.SampleRate())
+	return
+}
+
+func (d *Decoder) Decode() int {
+	n, err := io.ReadFull(d.reader, d.buffer)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		d.reachEnd = true
+	} else if err != nil {
+		log.Println("mp3 decode err:", err)
+		d.reachEnd = true
+	}
+	// keep frames aligned, 4 bytes per frame, 2 bytes per sample
+	d.size = (n &^ 3) / 2
+	return d.size
+}
+
+func (d *Decoder) head() error {
+	if f := d.file; f != nil {
+		f.Close()
+	}
+	f, err := res.Open(d.name)
+	if err != nil {
+		return err
+	}
+	return d.open(f)
+}
+
+func (d *Decoder) open(f res.File) error {
+	r, err := mp3.NewDecoder(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	d.file = f
+	d.reader = r
+	d.numChannels = 2
+	d.sampleRate = int32(r.SampleRate())
+	d.bitDepth = 16
+	d.size = 0
+	d.reachEnd = false
+	return nil
+}
+
+func (d *Decoder) Rewind() {
+	if err := d.head(); err != nil {
+		log.Println("mp3 rewind err:", err)
+	}
+}
+
+func NewDecoder(name string) (d *Decoder, err error) {
+	d = new(Decoder)
+	d.name = name
+	d.buffer = make([]byte, 16384)
+	err = d.head()
+	return
+}
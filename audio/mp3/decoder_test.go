@@ -0,0 +1,68 @@
+package mp3
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writes MPEG-1 Layer III frames of silence, 44100Hz 128kbps stereo,
+// every frame has 1152 sample frames.
+func writeMp3(t *testing.T, name string, frames int) {
+	data := make([]byte, 0, frames*417)
+	for i := 0; i < frames; i++ {
+		f := make([]byte, 417)
+		f[0], f[1], f[2], f[3] = 0xFF, 0xFB, 0x90, 0x00
+		data = append(data, f...)
+	}
+	if err := ioutil.WriteFile(name, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "a.mp3")
+	writeMp3(t, name, 10)
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, numChan, depth, freq, err := (&Decoder{}).FullDecode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numChan != 2 || depth != 16 || freq != 44100 || len(data) != 1152*10*4 {
+		t.Fatalf("full decode: chan %d depth %d freq %d size %d", numChan, depth, freq, len(data))
+	}
+
+	// streaming, Decode returns the number of samples
+	if f, err = os.Open(name); err != nil {
+		t.Fatal(err)
+	}
+	d := &Decoder{buffer: make([]byte, 1000)}
+	if err := d.open(f); err != nil {
+		t.Fatal(err)
+	}
+	if n := d.Length(); n != 1152*10 {
+		t.Errorf("length: %d", n)
+	}
+	total := 0
+	for !d.ReachEnd() {
+		n := d.Decode()
+		if len(d.Buffer()) != n*2 || n%2 != 0 {
+			t.Fatalf("buffer size: %d, decoded %d", len(d.Buffer()), n)
+		}
+		total += n
+	}
+	if total != 1152*10*2 {
+		t.Errorf("stream decode: expect %d samples, got %d", 1152*10*2, total)
+	}
+	d.file.Close()
+}
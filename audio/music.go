@@ -0,0 +1,317 @@
+package audio
+
+import (
+	"korok.io/korok/audio/sine"
+)
+
+// Track is a music to play. The music plays the intro [0, LoopStart)
+// once, then repeats the loop body [LoopStart, LoopEnd) without gap.
+type Track struct {
+	Id uint16
+
+	// loop points in sample frames, LoopEnd 0 means the end of music
+	LoopStart, LoopEnd int64
+
+	// times to repeat the loop body, <0 means forever, 0 plays once
+	Loop int
+}
+
+// MusicController plays the music tracks. Tracks in the queue are
+// played one by one, and two tracks can be crossfaded when switching.
+type MusicController struct {
+	slots [2]musicSlot
+	cur   int
+
+	queue  []Track
+	paused bool
+
+	// Crossfade is the fade duration(in seconds) between queued tracks,
+	// the next track starts Crossfade seconds before the current one ends
+	// if the length of stream is known.
+	Crossfade float32
+
+	// OnTrackEnd is called when a track is played to the end.
+	OnTrackEnd func(t Track)
+}
+
+// a playing track, fading in or out
+type musicSlot struct {
+	player  streamPlayer
+	track   Track
+	playing bool
+	// played to the end, it's fading out
+	ended bool
+	// nil if the length of stream is unknown
+	dec *trackDecoder
+
+	// fade gain
+	gain, target, speed float32
+}
+
+// the StreamPlayer used by music
+type streamPlayer interface {
+	Play(stream *sine.StreamData)
+	Stop()
+	Pause()
+	Resume()
+	State() uint32
+	SetVolume(v float32)
+	Tick()
+}
+
+// returns the stream of the music
+var streamOf = func(id uint16) (*sine.StreamData, bool) {
+	if sound, ok := sine.R.Sound(id); ok {
+		if d, ok := sound.Data.(*sine.StreamData); ok {
+			return d, true
+		}
+	}
+	return nil, false
+}
+
+func newMusicController(a, b streamPlayer) *MusicController {
+	mc := &MusicController{}
+	mc.slots[0].player = a
+	mc.slots[1].player = b
+	return mc
+}
+
+// Play plays the track, the current track is faded out in fade seconds
+// and the new track is faded in at the same time.
+func (mc *MusicController) Play(t Track, fade float32) bool {
+	stream, ok := streamOf(t.Id)
+	if !ok {
+		return false
+	}
+	cur := &mc.slots[mc.cur]
+	if cur.playing && cur.track.Id == t.Id {
+		// the stream can't be played by two players
+		fade = 0
+	}
+	switch {
+	case fade <= 0:
+		mc.stopAll()
+	case cur.playing:
+		cur.fadeTo(0, fade)
+		mc.cur = 1 - mc.cur
+		mc.slots[mc.cur].stop()
+	}
+
+	if t.Loop != 0 {
+		d := sine.NewLoopDecoder(stream.Decoder(), t.LoopStart, t.LoopEnd, t.Loop)
+		stream = sine.NewStreamData(d)
+	}
+	s := &mc.slots[mc.cur]
+	s.track, s.playing, s.ended = t, true, false
+	s.dec = nil
+	if d, ok := stream.Decoder().(sine.SizedDecoder); ok && d.Length() >= 0 {
+		s.dec = &trackDecoder{SizedDecoder: d}
+		stream = sine.NewStreamData(s.dec)
+	}
+	s.gain, s.target = 1, 1
+	if fade > 0 {
+		s.gain = 0
+		s.fadeTo(1, fade)
+	}
+	s.apply()
+	s.player.Play(stream)
+	if mc.paused {
+		s.player.Pause()
+	}
+	return true
+}
+
+// Queue appends the tracks to the playlist, the first one is played if
+// no music is playing.
+func (mc *MusicController) Queue(tracks ...Track) {
+	mc.queue = append(mc.queue, tracks...)
+	if !mc.slots[mc.cur].playing {
+		mc.Next(0)
+	}
+}
+
+// ClearQueue removes all the tracks in the playlist.
+func (mc *MusicController) ClearQueue() {
+	mc.queue = mc.queue[:0]
+}
+
+// Queued returns the tracks not played in the playlist.
+func (mc *MusicController) Queued() []Track {
+	return mc.queue
+}
+
+// Next plays the next track in the playlist with crossfade, stops the
+// music if the playlist is empty.
+func (mc *MusicController) Next(fade float32) {
+	for len(mc.queue) > 0 {
+		t := mc.queue[0]
+		mc.queue = mc.queue[1:]
+		if mc.Play(t, fade) {
+			return
+		}
+	}
+	mc.Stop(fade)
+}
+
+// Stop fades out and stops the music.
+func (mc *MusicController) Stop(fade float32) {
+	if s := &mc.slots[mc.cur]; s.playing && fade > 0 {
+		s.fadeTo(0, fade)
+	} else {
+		mc.stopAll()
+	}
+}
+
+func (mc *MusicController) Pause() {
+	mc.paused = true
+	for i := range mc.slots {
+		if s := &mc.slots[i]; s.playing {
+			s.player.Pause()
+		}
+	}
+}
+
+func (mc *MusicController) Resume() {
+	mc.paused = false
+	for i := range mc.slots {
+		if s := &mc.slots[i]; s.playing {
+			s.player.Resume()
+		}
+	}
+}
+
+// Current returns the playing track.
+func (mc *MusicController) Current() (t Track, ok bool) {
+	if s := &mc.slots[mc.cur]; s.playing {
+		t, ok = s.track, true
+	}
+	return
+}
+
+// Playing returns true if any track is playing(or paused).
+func (mc *MusicController) Playing() bool {
+	return mc.slots[0].playing || mc.slots[1].playing
+}
+
+func (mc *MusicController) player() streamPlayer {
+	return mc.slots[mc.cur].player
+}
+
+func (mc *MusicController) stopAll() {
+	for i := range mc.slots {
+		s := &mc.slots[i]
+		if s.playing && s.ended {
+			mc.trackEnd(s)
+		}
+		s.stop()
+	}
+}
+
+// Update feeds the players and updates the fading, dt is in seconds.
+func (mc *MusicController) Update(dt float32) {
+	for i := range mc.slots {
+		mc.slots[i].player.Tick()
+	}
+	if mc.paused {
+		return
+	}
+	// start the next track before the current one ends. Without crossfade,
+	// the next track is played after the player stops, so the buffers
+	// queued in the player are not dropped.
+	if s := &mc.slots[mc.cur]; mc.Crossfade > 0 && s.playing && !s.ended && s.target > 0 && len(mc.queue) > 0 {
+		if r := s.remain(); r >= 0 && r <= mc.Crossfade {
+			s.ended = true
+			mc.Next(mc.Crossfade)
+		}
+	}
+	for i := range mc.slots {
+		s := &mc.slots[i]
+		if !s.playing {
+			continue
+		}
+		if s.update(dt) {
+			s.stop()
+			if s.ended {
+				mc.trackEnd(s)
+			}
+			continue
+		}
+		if s.player.State() == sine.Stopped {
+			s.stop()
+			mc.trackEnd(s)
+			if i == mc.cur && !s.ended {
+				mc.Next(mc.Crossfade)
+			}
+		}
+	}
+}
+
+func (mc *MusicController) trackEnd(s *musicSlot) {
+	if cb := mc.OnTrackEnd; cb != nil {
+		cb(s.track)
+	}
+}
+
+func (s *musicSlot) fadeTo(gain, duration float32) {
+	s.target = gain
+	if duration > 0 {
+		s.speed = 1 / duration
+	} else {
+		s.gain = gain
+	}
+}
+
+// returns true if faded out
+func (s *musicSlot) update(dt float32) bool {
+	if s.gain < s.target {
+		if s.gain += s.speed * dt; s.gain > s.target {
+			s.gain = s.target
+		}
+	} else if s.gain > s.target {
+		if s.gain -= s.speed * dt; s.gain < s.target {
+			s.gain = s.target
+		}
+	}
+	s.apply()
+	return s.gain == 0 && s.target == 0
+}
+
+func (s *musicSlot) apply() {
+	s.player.SetVolume(s.gain * musicVolume * buses[BusMusic].Gain())
+}
+
+// returns the seconds to the end, <0 if unknown
+func (s *musicSlot) remain() float32 {
+	if s.dec == nil || s.dec.SampleRate() <= 0 {
+		return -1
+	}
+	return float32(s.dec.Length()-s.dec.pos) / float32(s.dec.SampleRate())
+}
+
+func (s *musicSlot) stop() {
+	if s.playing {
+		s.player.Stop()
+		s.playing = false
+	}
+}
+
+// trackDecoder counts the decoded frames to know when the track ends,
+// the players decode ahead, so it's a little earlier than the playing
+// position.
+type trackDecoder struct {
+	sine.SizedDecoder
+	pos int64
+}
+
+func (d *trackDecoder) Decode() int {
+	n := d.SizedDecoder.Decode()
+	if fs := int64(d.NumOfChan() * d.BitDepth() / 8); fs > 0 {
+		d.pos += int64(len(d.Buffer())) / fs
+	}
+	return n
+}
+
+func (d *trackDecoder) Rewind() {
+	d.SizedDecoder.Rewind()
+	d.pos = 0
+}
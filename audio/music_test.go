@@ -0,0 +1,208 @@
+package audio
+
+import (
+	"korok.io/korok/asset/res"
+	"korok.io/korok/audio/sine"
+
+	"testing"
+)
+
+type fakeStream struct {
+	stream *sine.StreamData
+	state  uint32
+	volume float32
+
+	// ticks to play the queued buffers after the stream is decoded
+	buffered int
+}
+
+func (fs *fakeStream) Play(stream *sine.StreamData) {
+	fs.stream, fs.state = stream, sine.Playing
+}
+
+func (fs *fakeStream) Stop()   { fs.state = sine.Stopped }
+func (fs *fakeStream) Pause()  { fs.state = sine.Paused }
+func (fs *fakeStream) Resume() { fs.state = sine.Playing }
+
+// decodes the sized stream one chunk each tick, stops at the end after
+// the queued buffers are played
+func (fs *fakeStream) Tick() {
+	if fs.state != sine.Playing || fs.stream == nil {
+		return
+	}
+	d, ok := fs.stream.Decoder().(sine.SizedDecoder)
+	if !ok || d.Length() < 0 {
+		return
+	}
+	if !d.ReachEnd() && d.Decode() > 0 && !d.ReachEnd() {
+		return
+	}
+	if fs.buffered > 0 {
+		fs.buffered--
+		return
+	}
+	fs.state = sine.Stopped
+}
+
+func (fs *fakeStream) State() uint32 {
+	return fs.state
+}
+
+func (fs *fakeStream) SetVolume(v float32) {
+	fs.volume = v
+}
+
+func TestMusicController(t *testing.T) {
+	streams := map[uint16]*sine.StreamData{1: {}, 2: {}, 3: {}}
+	defer func(fn func(uint16) (*sine.StreamData, bool)) { streamOf = fn }(streamOf)
+	streamOf = func(id uint16) (*sine.StreamData, bool) {
+		d, ok := streams[id]
+		return d, ok
+	}
+	a, b := &fakeStream{}, &fakeStream{}
+	mc := newMusicController(a, b)
+
+	var ended []uint16
+	mc.OnTrackEnd = func(t Track) {
+		ended = append(ended, t.Id)
+	}
+
+	mc.Queue(Track{Id: 1}, Track{Id: 2}, Track{Id: 3})
+	if cur, _ := mc.Current(); cur.Id != 1 || a.stream != streams[1] || a.volume != 1 {
+		t.Fatal("first track should be played:", cur.Id)
+	}
+
+	// track end, play next
+	a.state = sine.Stopped
+	mc.Update(.1)
+	if cur, _ := mc.Current(); cur.Id != 2 || len(ended) != 1 || ended[0] != 1 {
+		t.Fatal("second track should be played:", cur.Id, ended)
+	}
+
+	// crossfade to next in 1 second
+	mc.Next(1)
+	if cur, _ := mc.Current(); cur.Id != 3 || b.stream != streams[3] {
+		t.Fatal("third track should be played on the other player")
+	}
+	mc.Update(.5)
+	if !near(a.volume, .5) || !near(b.volume, .5) {
+		t.Errorf("crossfade: got %v, %v", a.volume, b.volume)
+	}
+	mc.Update(.5)
+	if a.state != sine.Stopped || !near(b.volume, 1) {
+		t.Errorf("crossfade end: state %x, volume %v", a.state, b.volume)
+	}
+	if len(ended) != 1 {
+		t.Error("faded out track is not ended:", ended)
+	}
+
+	// loop points
+	mc.Play(Track{Id: 1, LoopStart: 10, Loop: -1}, 0)
+	if _, ok := b.stream.Decoder().(*sine.LoopDecoder); !ok || b.state != sine.Playing {
+		t.Error("loop track should be played with LoopDecoder")
+	}
+
+	// fade out
+	mc.Stop(2)
+	mc.Update(1)
+	if !mc.Playing() {
+		t.Error("music should be fading out")
+	}
+	mc.Update(1)
+	if mc.Playing() {
+		t.Error("music should be stopped")
+	}
+}
+
+// mono 8-bit stream, decodes one frame each time
+type sizedDecoder struct {
+	frames, pos int
+	buf         [1]byte
+}
+
+func (d *sizedDecoder) FullDecode(file res.File) ([]byte, int32, int32, int32, error) {
+	return nil, 1, 8, 10, nil
+}
+
+func (d *sizedDecoder) Decode() int {
+	if d.pos >= d.frames {
+		return 0
+	}
+	d.pos++
+	return 1
+}
+
+func (d *sizedDecoder) NumOfChan() int32  { return 1 }
+func (d *sizedDecoder) BitDepth() int32   { return 8 }
+func (d *sizedDecoder) SampleRate() int32 { return 10 }
+func (d *sizedDecoder) Buffer() []byte    { return d.buf[:] }
+func (d *sizedDecoder) ReachEnd() bool    { return d.pos >= d.frames }
+func (d *sizedDecoder) Rewind()           { d.pos = 0 }
+func (d *sizedDecoder) Length() int64     { return int64(d.frames) }
+
+func TestMusicCrossfadeAtEnd(t *testing.T) {
+	// 2 seconds each
+	streams := map[uint16]*sine.StreamData{
+		1: sine.NewStreamData(&sizedDecoder{frames: 20}),
+		2: sine.NewStreamData(&sizedDecoder{frames: 20}),
+	}
+	defer func(fn func(uint16) (*sine.StreamData, bool)) { streamOf = fn }(streamOf)
+	streamOf = func(id uint16) (*sine.StreamData, bool) {
+		d, ok := streams[id]
+		return d, ok
+	}
+	a, b := &fakeStream{}, &fakeStream{}
+	mc := newMusicController(a, b)
+	mc.Crossfade = .5
+	var ended []uint16
+	mc.OnTrackEnd = func(t Track) {
+		ended = append(ended, t.Id)
+	}
+
+	mc.Queue(Track{Id: 1}, Track{Id: 2})
+	for i := 0; i < 15; i++ {
+		mc.Update(.1)
+	}
+	if cur, _ := mc.Current(); cur.Id != 2 {
+		t.Fatal("next track should start before the end:", cur.Id)
+	}
+	mc.Update(.2)
+	if a.state != sine.Playing || b.state != sine.Playing {
+		t.Fatalf("both tracks should play in crossfade: %x, %x", a.state, b.state)
+	}
+	if a.volume <= 0 || a.volume >= 1 || b.volume <= 0 || b.volume >= 1 {
+		t.Errorf("crossfade volume: %v, %v", a.volume, b.volume)
+	}
+	if len(ended) != 0 {
+		t.Error("track is ended before fade out:", ended)
+	}
+	for i := 0; i < 3; i++ {
+		mc.Update(.1)
+	}
+	if a.state != sine.Stopped || !near(b.volume, 1) || len(ended) != 1 || ended[0] != 1 {
+		t.Errorf("crossfade end: state %x, volume %v, ended %v", a.state, b.volume, ended)
+	}
+
+	// without crossfade, the next track is played after the queued
+	// buffers of current track are played
+	mc.Crossfade = 0
+	ended = ended[:0]
+	streams[3] = sine.NewStreamData(&sizedDecoder{frames: 20})
+	streams[4] = sine.NewStreamData(&sizedDecoder{frames: 20})
+	mc.Play(Track{Id: 3}, 0)
+	mc.Queue(Track{Id: 4})
+	cur := mc.player().(*fakeStream)
+	cur.buffered = 3
+	for i := 0; i < 20; i++ {
+		mc.Update(.1)
+	}
+	if c, _ := mc.Current(); c.Id != 3 || cur.state != sine.Playing || len(ended) != 0 {
+		t.Fatalf("track should play the queued buffers: track %d, state %x, ended %v", c.Id, cur.state, ended)
+	}
+	for i := 0; i < 3; i++ {
+		mc.Update(.1)
+	}
+	if c, _ := mc.Current(); c.Id != 4 || len(ended) != 1 || ended[0] != 3 {
+		t.Errorf("next track should be played after the end: track %d, ended %v", c.Id, ended)
+	}
+}
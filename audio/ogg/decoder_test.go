@@ -0,0 +1,46 @@
+package ogg
+
+import (
+	"os"
+	"testing"
+)
+
+// testdata/test.ogg is from github.com/jfreymuth/oggvorbis, 1 second
+// at 44100Hz.
+func TestDecode(t *testing.T) {
+	f, err := os.Open("testdata/test.ogg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, numChan, depth, freq, err := (&Decoder{}).FullDecode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if depth != 16 || freq != 44100 || len(data) != 44100*int(numChan)*2 {
+		t.Fatalf("full decode: chan %d depth %d freq %d size %d", numChan, depth, freq, len(data))
+	}
+
+	// streaming, Decode returns the number of samples
+	if f, err = os.Open("testdata/test.ogg"); err != nil {
+		t.Fatal(err)
+	}
+	d := &Decoder{f32buffer: make([]float32, 1000), i16buffer: make([]int16, 1000)}
+	if err := d.open(f); err != nil {
+		t.Fatal(err)
+	}
+	if n := d.Length(); n != 44100 {
+		t.Errorf("length: %d", n)
+	}
+	total := 0
+	for !d.ReachEnd() {
+		n := d.Decode()
+		if len(d.Buffer()) != n*2 {
+			t.Fatalf("buffer size: %d, decoded %d", len(d.Buffer()), n)
+		}
+		total += n
+	}
+	if total != 44100*int(numChan) {
+		t.Errorf("stream decode: expect %d samples, got %d", 44100*numChan, total)
+	}
+	d.file.Close()
+}
@@ -62,6 +62,14 @@ func (*Decoder) FullDecode(file res.File) (data []byte, numChan, bitDepth, freq
 	return
 }
 
+// Length returns the number of sample frames, -1 if unknown.
+func (d *Decoder) Length() int64 {
+	if n := d.reader.Length(); n > 0 {
+		return n
+	}
+	return -1
+}
+
 func (d *Decoder) Decode() int {
 	size, err := d.reader.Read(d.f32buffer)
 	if err != nil && err != io.EOF {
@@ -84,8 +92,13 @@ func (d *Decoder) head() error {
 	if err != nil {
 		return err
 	}
+	return d.open(f)
+}
+
+func (d *Decoder) open(f res.File) error {
 	r, err := oggvorbis.NewReader(f)
 	if err != nil {
+		f.Close()
 		return err
 	}
 
@@ -94,6 +107,7 @@ func (d *Decoder) head() error {
 	d.numChannels = int32(r.Channels())
 	d.sampleRate = int32(r.SampleRate())
 	d.bitDepth = 16
+	d.size = 0
 	d.reachEnd = false
 	return nil
 }
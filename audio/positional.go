@@ -1,13 +1,69 @@
 package audio
 
-// TODO
-// positional audio component/system
+import (
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx"
 
+	"math"
+)
+
+// RolloffModel decides how the volume is attenuated by the distance
+// between the source and the listener.
+type RolloffModel uint8
+
+const (
+	// no attenuation
+	RolloffNone RolloffModel = iota
+
+	// 1 - (d-min)/(max-min)
+	RolloffLinear
+
+	// min / (min + factor*(d-min))
+	RolloffInverse
+
+	// (d/min) ^ -factor
+	RolloffExponential
+)
+
+// Attenuation computes the gain of the distance with the rolloff model,
+// the distance is clamped to [min, max].
+func Attenuation(model RolloffModel, d, min, max, factor float32) float32 {
+	if min <= 0 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	if d < min {
+		d = min
+	}
+	if d > max {
+		d = max
+	}
+	switch model {
+	case RolloffLinear:
+		if max == min {
+			return 1
+		}
+		return 1 - (d-min)/(max-min)
+	case RolloffInverse:
+		return min / (min + factor*(d-min))
+	case RolloffExponential:
+		return float32(math.Pow(float64(d/min), float64(-factor)))
+	}
+	return 1
+}
+
+// SourceComp plays a sound at the position of the entity, the volume
+// and pan are updated each frame by the SourceSystem. An entity without
+// Transform is not positional.
 type SourceComp struct {
+	engi.Entity
+
 	// 音频资源
 	Id uint16
 	// 优先级
-	P  uint16
+	P uint16
 
 	// 静音
 	Mute bool
@@ -17,5 +73,281 @@ type SourceComp struct {
 
 	// 音量 [0, 1]
 	Volume float32
+
+	// 衰减模型
+	Rolloff RolloffModel
+	// the volume is not attenuated in MinDistance, and stops attenuating
+	// beyond MaxDistance.
+	MinDistance, MaxDistance float32
+	RolloffFactor            float32
+
+	// state
+	play, playing bool
+	cid           ChanId
+}
+
+// Play plays the sound in next update, the source is stopped if the
+// sound fails to play.
+func (sc *SourceComp) Play() {
+	sc.play = true
+}
+
+// Stop stops the sound.
+func (sc *SourceComp) Stop() {
+	sc.play = false
+	if sc.playing {
+		StopEffect(sc.cid)
+		sc.playing = false
+	}
+}
+
+// Playing returns true if the sound is playing(or will be played).
+func (sc *SourceComp) Playing() bool {
+	return sc.play
+}
+
+// SourceTable
+type SourceTable struct {
+	comps      []SourceComp
+	_map       map[uint32]int
+	index, cap int
+}
+
+func NewSourceTable(cap int) *SourceTable {
+	return &SourceTable{
+		cap:  cap,
+		_map: make(map[uint32]int),
+	}
+}
+
+func (st *SourceTable) NewComp(entity engi.Entity) (sc *SourceComp) {
+	if size := len(st.comps); st.index >= size {
+		st.comps = sourceResize(st.comps, size+64)
+	}
+	ei := entity.Index()
+	if v, ok := st._map[ei]; ok {
+		sc = &st.comps[v]
+		return
+	}
+	sc = &st.comps[st.index]
+	sc.Entity = entity
+	sc.Volume = 1
+	sc.Rolloff = RolloffInverse
+	sc.MinDistance, sc.MaxDistance = 100, 1000
+	sc.RolloffFactor = 1
+	st._map[ei] = st.index
+	st.index++
+	return
+}
+
+func (st *SourceTable) Alive(entity engi.Entity) bool {
+	if v, ok := st._map[entity.Index()]; ok {
+		return st.comps[v].Entity == entity
+	}
+	return false
+}
+
+func (st *SourceTable) Comp(entity engi.Entity) (sc *SourceComp) {
+	if v, ok := st._map[entity.Index()]; ok {
+		sc = &st.comps[v]
+	}
+	return
+}
+
+// Delete stops the playing sound of the entity.
+func (st *SourceTable) Delete(entity engi.Entity) {
+	ei := entity.Index()
+	if v, ok := st._map[ei]; ok {
+		st.comps[v].Stop()
+		if tail := st.index - 1; v != tail && tail > 0 {
+			st.comps[v] = st.comps[tail]
+			// remap index
+			tComp := st.comps[tail]
+			ei := tComp.Entity.Index()
+			st._map[ei] = v
+			st.comps[tail] = SourceComp{}
+		} else {
+			st.comps[tail] = SourceComp{}
+		}
+		st.index -= 1
+		delete(st._map, ei)
+	}
+}
+
+func (st *SourceTable) Size() (size, cap int) {
+	return st.index, st.cap
+}
+
+// EntityAt returns the entity of the i-th comp, i in [0, size)
+func (st *SourceTable) EntityAt(i int) engi.Entity {
+	return st.comps[i].Entity
+}
+
+func (st *SourceTable) Destroy() {
+	for i := 0; i < st.index; i++ {
+		st.comps[i].Stop()
+	}
+	st.comps = make([]SourceComp, 0)
+	st._map = make(map[uint32]int)
+	st.index = 0
+}
+
+func sourceResize(slice []SourceComp, size int) []SourceComp {
+	newSlice := make([]SourceComp, size)
+	copy(newSlice, slice)
+	return newSlice
+}
+
+// SourceSystem plays the SourceComp, the volume is attenuated and the
+// sound is panned by the distance from the listener. The listener is an
+// entity with Transform, or the Camera if not set.
+type SourceSystem struct {
+	st *SourceTable
+	xt *gfx.TransformTable
+
+	listener engi.Entity
+	camera   *gfx.Camera
+
+	// PanDistance is the horizontal distance where the sound is
+	// panned to the left(or right) totally.
+	PanDistance float32
+}
+
+func NewSourceSystem() *SourceSystem {
+	return &SourceSystem{listener: engi.Ghost, PanDistance: 500}
+}
+
+func (ss *SourceSystem) RequireTable(tables []interface{}) {
+	for _, t := range tables {
+		switch table := t.(type) {
+		case *SourceTable:
+			ss.st = table
+		case *gfx.TransformTable:
+			ss.xt = table
+		}
+	}
+}
+
+// SetListener sets the listener entity, engi.Ghost means the Camera.
+func (ss *SourceSystem) SetListener(e engi.Entity) {
+	ss.listener = e
+}
+
+func (ss *SourceSystem) Listener() engi.Entity {
+	return ss.listener
+}
+
+// SetCamera sets the default listener.
+func (ss *SourceSystem) SetCamera(c *gfx.Camera) {
+	ss.camera = c
+}
+
+func (ss *SourceSystem) Update(dt float32) {
+	if ss.st == nil {
+		return
+	}
+	lx, ly, ok := ss.listenerPosition()
+	for i, n := 0, ss.st.index; i < n; i++ {
+		sc := &ss.st.comps[i]
+		if !sc.play {
+			continue
+		}
+		if sc.playing && !EffectPlaying(sc.cid) {
+			sc.playing = false
+			if !sc.Loop {
+				sc.play = false
+				continue
+			}
+		}
+		if !sc.playing {
+			// invalid sound or no free channel, don't retry every frame
+			if sc.cid = PlayEffect(sc.Id, int(sc.P)); sc.cid == 0 {
+				sc.play = false
+				continue
+			}
+			sc.playing = true
+		}
+		volume, pan := sc.Volume, float32(0)
+		if sc.Mute {
+			volume = 0
+		} else if xf := ss.transform(sc.Entity); xf != nil && ok {
+			p := xf.World().Position
+			dx, dy := p[0]-lx, p[1]-ly
+			d := float32(math.Sqrt(float64(dx*dx + dy*dy)))
+			volume *= Attenuation(sc.Rolloff, d, sc.MinDistance, sc.MaxDistance, sc.RolloffFactor)
+			pan = ss.pan(dx)
+		}
+		if ch, ok := channels[sc.cid]; ok {
+			ch.volume, ch.pan = volume, pan
+			applyChannel(sc.cid, ch)
+		}
+	}
+}
+
+func (ss *SourceSystem) pan(dx float32) float32 {
+	if ss.PanDistance <= 0 {
+		return 0
+	}
+	pan := dx / ss.PanDistance
+	if pan < -1 {
+		pan = -1
+	} else if pan > 1 {
+		pan = 1
+	}
+	return pan
+}
+
+func (ss *SourceSystem) transform(e engi.Entity) *gfx.Transform {
+	if ss.xt == nil {
+		return nil
+	}
+	return ss.xt.Comp(e)
+}
+
+func (ss *SourceSystem) listenerPosition() (x, y float32, ok bool) {
+	if ss.listener != engi.Ghost {
+		if xf := ss.transform(ss.listener); xf != nil {
+			p := xf.World().Position
+			return p[0], p[1], true
+		}
+	}
+	if c := ss.camera; c != nil {
+		x, y = c.Position()
+		return x, y, true
+	}
+	return
+}
+
+// the sound pool used by effects, volume and pan are set without bus
+type effectPlayer interface {
+	Play(id uint16, priority int) ChanId
+	Stop(cid ChanId)
+	Playing(cid ChanId) bool
+	Set(cid ChanId, volume, pan float32)
+	SetBus(cid ChanId, bus string)
+}
+
+type poolPlayer struct{}
+
+func (poolPlayer) Play(id uint16, priority int) ChanId {
+	return ChanId(effects.Play(id, priority))
+}
+
+func (poolPlayer) Stop(cid ChanId) {
+	effects.StopChan(int(cid))
+}
+
+func (poolPlayer) Playing(cid ChanId) bool {
+	return effects.ChanPlaying(int(cid))
+}
+
+func (poolPlayer) Set(cid ChanId, volume, pan float32) {
+	effects.SetChanVolume(int(cid), volume)
+	effects.SetChanPan(int(cid), pan)
+}
+
+func (poolPlayer) SetBus(cid ChanId, bus string) {
+	effects.SetChanBus(int(cid), bus)
 }
 
+var player effectPlayer = poolPlayer{}
@@ -0,0 +1,136 @@
+package audio
+
+import (
+	"testing"
+
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx"
+	"korok.io/korok/math/f32"
+)
+
+type fakeChannel struct {
+	id          uint16
+	playing     bool
+	volume, pan float32
+	bus         string
+}
+
+// records the channels instead of playing
+type fakePlayer struct {
+	channels map[ChanId]*fakeChannel
+	next     ChanId
+	calls    int
+}
+
+func (fp *fakePlayer) Play(id uint16, priority int) ChanId {
+	fp.calls++
+	// invalid sound
+	if id == 0 {
+		return 0
+	}
+	fp.next++
+	fp.channels[fp.next] = &fakeChannel{id: id, playing: true}
+	return fp.next
+}
+
+func (fp *fakePlayer) Stop(cid ChanId) {
+	if ch, ok := fp.channels[cid]; ok {
+		ch.playing = false
+	}
+}
+
+func (fp *fakePlayer) Playing(cid ChanId) bool {
+	ch, ok := fp.channels[cid]
+	return ok && ch.playing
+}
+
+func (fp *fakePlayer) Set(cid ChanId, volume, pan float32) {
+	if ch, ok := fp.channels[cid]; ok {
+		ch.volume, ch.pan = volume, pan
+	}
+}
+
+func (fp *fakePlayer) SetBus(cid ChanId, bus string) {
+	if ch, ok := fp.channels[cid]; ok {
+		ch.bus = bus
+	}
+}
+
+func TestAttenuation(t *testing.T) {
+	cases := []struct {
+		model     RolloffModel
+		d, expect float32
+	}{
+		{RolloffNone, 500, 1},
+		{RolloffLinear, 50, 1},
+		{RolloffLinear, 550, .5},
+		{RolloffLinear, 2000, 0},
+		{RolloffInverse, 200, .5},
+		{RolloffExponential, 200, .25},
+	}
+	for _, c := range cases {
+		factor := float32(1)
+		if c.model == RolloffExponential {
+			factor = 2
+		}
+		if v := Attenuation(c.model, c.d, 100, 1000, factor); v < c.expect-1e-4 || v > c.expect+1e-4 {
+			t.Errorf("model %d, distance %v: expect %v, got %v", c.model, c.d, c.expect, v)
+		}
+	}
+}
+
+func TestSourceSystem(t *testing.T) {
+	fp := &fakePlayer{channels: make(map[ChanId]*fakeChannel)}
+	player = fp
+	defer func() { player = poolPlayer{} }()
+
+	em := engi.NewEntityManager()
+	st, xt := NewSourceTable(16), gfx.NewTransformTable(16)
+	ss := NewSourceSystem()
+	ss.RequireTable([]interface{}{st, xt})
+
+	listener := em.New()
+	xt.NewComp(listener)
+	ss.SetListener(listener)
+
+	e := em.New()
+	xt.NewComp(e).SetPosition(f32.Vec2{-200, 0})
+	sc := st.NewComp(e)
+	sc.Id, sc.Volume = 3, .8
+	sc.Play()
+
+	ss.Update(1.0 / 60)
+	ch, ok := fp.channels[sc.cid]
+	if !ok || ch.id != 3 {
+		t.Fatal("sound should be played")
+	}
+	if ch.volume < .399 || ch.volume > .401 || ch.pan >= 0 {
+		t.Error("wrong volume or pan:", ch.volume, ch.pan)
+	}
+
+	// loop sound is played again after stopped
+	sc.Loop = true
+	ch.playing = false
+	ss.Update(1.0 / 60)
+	if !fp.Playing(sc.cid) || sc.cid == 1 {
+		t.Error("loop sound should be played again")
+	}
+
+	// failed sound is not retried
+	bad := st.NewComp(em.New())
+	bad.Loop = true
+	bad.Play()
+	calls := fp.calls
+	ss.Update(1.0 / 60)
+	ss.Update(1.0 / 60)
+	if fp.calls != calls+1 || bad.Playing() {
+		t.Errorf("failed sound is retried: %d calls", fp.calls-calls)
+	}
+
+	// stop channel when destroyed
+	cid := sc.cid
+	st.Delete(e)
+	if fp.Playing(cid) {
+		t.Error("channel should be stopped when entity is deleted")
+	}
+}
@@ -1,6 +1,7 @@
 package sine
 
 import (
+	"errors"
 	"log"
 
 	"korok.io/korok/asset/res"
@@ -12,10 +13,9 @@ const (
 	None FileType = iota
 	WAV
 	VORB
-
-	// NOT IMPLEMENT YET
-	OPUS
+	OPUS // NOT IMPLEMENT YET
 	FLAC
+	MP3
 )
 
 type SourceType uint8
@@ -82,30 +82,59 @@ func (am *AudioManger) LoadSound(name string, ft FileType, sType SourceType) (id
 }
 
 func (am *AudioManger) LoadStatic(name string, ft FileType) (id uint16, sd *StaticData) {
-	d, err := factory.NewDecoder(name, ft)
+	pcm, err := Decode(name, ft)
 	if err != nil {
 		log.Println(err)
 		return
 	}
+	id, sd = am.allocStaticData(pcm.Format, pcm.Data, pcm.Freq)
+	return
+}
+
+// PCM is the full decoded data of a static sound.
+type PCM struct {
+	Format uint32
+	Data   []byte
+	Freq   int32
+}
+
+// Decode full decodes the audio file, it doesn't touch the audio
+// device, so it's safe to call it in other goroutine.
+func Decode(name string, ft FileType) (pcm *PCM, err error) {
+	if factory == nil {
+		return nil, errors.New("audio decoder factory is not set")
+	}
+	d, err := factory.NewDecoder(name, ft)
+	if err != nil {
+		return
+	}
 	file, err := res.Open(name)
 	if err != nil {
-		log.Println(err)
 		return
 	}
+	defer file.Close()
+	return fullDecode(d, file)
+}
+
+func fullDecode(d Decoder, file res.File) (pcm *PCM, err error) {
 	data, numChan, bitDepth, freq, err := d.FullDecode(file)
 	if err != nil {
-		log.Println("fail to full decode audio data")
-		return
+		return nil, errors.New("fail to full decode audio data")
 	}
 	format := getFormat(numChan, bitDepth)
 	if format == FormatNone {
-		log.Println("invalid audio format")
-		return
+		return nil, errors.New("invalid audio format")
 	}
+	pcm = &PCM{formatCodes[format], data, freq}
+	return
+}
 
-	fc := formatCodes[format]
-	fc = FormatMono16
-	id, sd = am.allocStaticData(fc, data, freq)
+// LoadPCM creates a static Sound with the decoded data.
+func (am *AudioManger) LoadPCM(pcm *PCM) (id uint16, sound *Sound) {
+	id, sound = am.indexPool, &am.soundPool[am.indexPool]
+	am.indexPool++
+	sound.Type = Static
+	_, sound.Data = am.allocStaticData(pcm.Format, pcm.Data, pcm.Freq)
 	return
 }
 
@@ -0,0 +1,40 @@
+package sine
+
+import (
+	"korok.io/korok/audio/mp3"
+
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFullDecodeFormat(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// MPEG-1 Layer III frames of silence, 44100Hz stereo
+	frame := make([]byte, 417)
+	frame[0], frame[1], frame[2], frame[3] = 0xFF, 0xFB, 0x90, 0x00
+	name := filepath.Join(dir, "a.mp3")
+	if err := ioutil.WriteFile(name, bytes.Repeat(frame, 4), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	pcm, err := fullDecode(&mp3.Decoder{}, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pcm.Format != FormatStereo16 || pcm.Freq != 44100 {
+		t.Errorf("format: got %x %d, want %x 44100", pcm.Format, pcm.Freq, FormatStereo16)
+	}
+}
@@ -1,4 +1,4 @@
-//+build !android,!js,!windows
+//+build !android,!js,!windows,!mixer
 
 package sine
 
@@ -84,6 +84,12 @@ void SineBufferPlayer_setVolume(SineBufferPlayer *p, ALfloat v) {
 	alSourcef(p->idSource, AL_GAIN, v);
 }
 
+// pan a mono source by putting it on the unit circle around the listener
+void SineBufferPlayer_setPan(SineBufferPlayer *p, ALfloat x, ALfloat z) {
+	alSourcei(p->idSource, AL_SOURCE_RELATIVE, AL_TRUE);
+	alSource3f(p->idSource, AL_POSITION, x, 0, z);
+}
+
 typedef struct SineStreamPlayer {
 	ALuint idSource;
 	ALuint buffers[8]; //max buffer size
@@ -170,6 +176,7 @@ void SineStreamPlayer_setVolume(SineStreamPlayer *p, ALfloat v) {
 import "C"
 import (
 	"log"
+	"math"
 	"unsafe"
 )
 
@@ -264,6 +271,12 @@ func (p *BufferPlayer) SetVolume(v float32) {
 	C.SineBufferPlayer_setVolume(&p.player, C.ALfloat(v))
 }
 
+// SetPan sets the stereo position in [-1, 1], -1 is left and 1 is right.
+func (p *BufferPlayer) SetPan(pan float32) {
+	z := -math.Sqrt(float64(1 - pan*pan))
+	C.SineBufferPlayer_setPan(&p.player, C.ALfloat(pan), C.ALfloat(z))
+}
+
 func (p *BufferPlayer) SetLoop(loop int) {
 
 }
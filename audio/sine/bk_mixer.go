@@ -0,0 +1,304 @@
+//+build mixer
+
+package sine
+
+import (
+	"log"
+	"time"
+)
+
+// The software mixer backend, build with tag 'mixer'. The audio is mixed
+// by Mixer and written to the Sink(a NullSink by default) instead of the
+// audio device. With SetRealtime(true), the frames are rendered in a
+// goroutine at real speed after Init, otherwise call Advance to render
+// the frames, it's useful for tests and offline rendering.
+
+// SetSink sets the sink of the mixer, it should be called before Init.
+func SetSink(sink Sink) {
+	engine.sink = sink
+	if m := engine.mixer; m != nil {
+		m.SetSink(sink)
+	}
+}
+
+// SetRealtime enables rendering in a goroutine, it should be called
+// before Init.
+func SetRealtime(realtime bool) {
+	engine.realtime = realtime
+}
+
+// CurrentMixer returns the mixer of the engine.
+func CurrentMixer() *Mixer {
+	return engine.Mixer()
+}
+
+// Advance renders the frames of the duration to the sink.
+func Advance(d time.Duration) error {
+	m := engine.Mixer()
+	frames := int(int64(m.SampleRate()) * int64(d) / int64(time.Second))
+	return m.Render(frames)
+}
+
+// SetBusFilters sets the filter chain of the bus.
+func SetBusFilters(bus string, filters ...Filter) {
+	engine.Mixer().Bus(bus).SetFilters(filters...)
+}
+
+// SetBusParent routes the bus to the parent bus, "" means the output.
+func SetBusParent(bus, parent string) {
+	m := engine.Mixer()
+	if parent == "" {
+		m.Bus(bus).SetParent(nil)
+	} else {
+		m.Bus(bus).SetParent(m.Bus(parent))
+	}
+}
+
+// StaticData is small audio sampler, which will be load into memory directly.
+type StaticData struct {
+	pcm      []int16
+	channels int
+	freq     int32
+}
+
+func (d *StaticData) Create(fmt uint32, bits []byte, freq int32) {
+	d.pcm, d.channels = toPCM16(formatEnum(fmt), bits)
+	d.freq = freq
+}
+
+// StreamData will decode pcm-data at runtime. It's used to play big audio files(like .ogg).
+type StreamData struct {
+	decoder Decoder
+}
+
+func (d *StreamData) Create(file string, ft FileType) {
+	decoder, err := factory.NewDecoder(file, ft)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	d.decoder = decoder
+}
+
+func (d *StreamData) format() FormatEnum {
+	return getFormat(d.decoder.NumOfChan(), d.decoder.BitDepth())
+}
+
+type Engine struct {
+	mixer    *Mixer
+	sink     Sink
+	realtime bool
+	quit     chan struct{}
+}
+
+// Mixer returns the mixer, it's created if not exist.
+func (eng *Engine) Mixer() *Mixer {
+	if eng.mixer == nil {
+		eng.mixer = NewMixer(DefaultSampleRate, eng.sink)
+	}
+	return eng.mixer
+}
+
+func (eng *Engine) Initialize() {
+	m := eng.Mixer()
+	if !eng.realtime || eng.quit != nil {
+		return
+	}
+	eng.quit = make(chan struct{})
+	go func(quit chan struct{}) {
+		const interval = 10 * time.Millisecond
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		last, rendered := time.Now(), int64(0)
+		for {
+			select {
+			case <-quit:
+				return
+			case now := <-ticker.C:
+				// frames should be rendered since start
+				total := int64(now.Sub(last)) * int64(m.SampleRate()) / int64(time.Second)
+				if err := m.Render(int(total - rendered)); err != nil {
+					log.Println("mixer render err:", err)
+				}
+				rendered = total
+			}
+		}
+	}(eng.quit)
+}
+
+func (eng *Engine) Destroy() {
+	if eng.quit != nil {
+		close(eng.quit)
+		eng.quit = nil
+	}
+	if m := eng.mixer; m != nil {
+		if err := m.Close(); err != nil {
+			log.Println("mixer close err:", err)
+		}
+	}
+}
+
+// BufferPlayer can play audio loaded as StaticData.
+type BufferPlayer struct {
+	voice *Voice
+}
+
+func (p *BufferPlayer) initialize(engine *Engine) {
+	p.voice = engine.Mixer().NewVoice()
+}
+
+func (p *BufferPlayer) Play(data *StaticData) {
+	p.voice.Play(data.pcm, data.channels, data.freq)
+}
+
+func (p *BufferPlayer) Stop() {
+	p.voice.Stop()
+}
+
+func (p *BufferPlayer) Pause() {
+	p.voice.Pause()
+}
+
+func (p *BufferPlayer) Resume() {
+	p.voice.Resume()
+}
+
+func (p *BufferPlayer) Volume() float32 {
+	return p.voice.Volume()
+}
+
+func (p *BufferPlayer) SetVolume(v float32) {
+	p.voice.SetVolume(v)
+}
+
+func (p *BufferPlayer) SetPan(pan float32) {
+	p.voice.SetPan(pan)
+}
+
+// SetLoop sets the times to repeat, <0 means forever.
+func (p *BufferPlayer) SetLoop(loop int) {
+	p.voice.SetLoop(loop)
+}
+
+// SetBus routes the player to the bus, "" means the output.
+func (p *BufferPlayer) SetBus(bus string) {
+	p.voice.SetBus(busOf(p.voice.m, bus))
+}
+
+func (p *BufferPlayer) State() uint32 {
+	return p.voice.State()
+}
+
+// StreamPlayer can play audio loaded as StreamData.
+type StreamPlayer struct {
+	voice   *Voice
+	decoder Decoder
+	format  FormatEnum
+	playing bool
+}
+
+// max buffers queued in the voice
+const streamBuffers = 4
+
+func (p *StreamPlayer) initialize(engine *Engine) {
+	p.voice = engine.Mixer().NewVoice()
+}
+
+func (p *StreamPlayer) Play(stream *StreamData) {
+	p.voice.Stop()
+	p.decoder = stream.decoder
+	if p.decoder == nil {
+		return
+	}
+	p.format = stream.format()
+	p.playing = true
+	p.fill()
+	p.voice.Start()
+}
+
+func (p *StreamPlayer) Stop() {
+	p.voice.Stop()
+	p.playing = false
+	if d := p.decoder; d != nil {
+		d.Rewind()
+	}
+	p.decoder = nil
+}
+
+func (p *StreamPlayer) Pause() {
+	p.voice.Pause()
+}
+
+func (p *StreamPlayer) Resume() {
+	p.voice.Resume()
+}
+
+func (p *StreamPlayer) State() uint32 {
+	return p.voice.State()
+}
+
+func (p *StreamPlayer) Volume() float32 {
+	return p.voice.Volume()
+}
+
+func (p *StreamPlayer) SetVolume(v float32) {
+	p.voice.SetVolume(v)
+}
+
+// SetBus routes the player to the bus, "" means the output.
+func (p *StreamPlayer) SetBus(bus string) {
+	p.voice.SetBus(busOf(p.voice.m, bus))
+}
+
+func (p *StreamPlayer) Tick() {
+	p.fill()
+}
+
+func (p *StreamPlayer) fill() {
+	d := p.decoder
+	if d == nil || !p.playing {
+		return
+	}
+	for p.voice.Queued() < streamBuffers && !d.ReachEnd() {
+		if n := d.Decode(); n == 0 {
+			break
+		}
+		pcm, channels := toPCM16(p.format, d.Buffer())
+		p.voice.Queue(pcm, channels, d.SampleRate())
+	}
+	// restart if the voice is drained before the data is fed
+	if p.voice.State() == Stopped && p.voice.Queued() > 0 {
+		p.voice.Start()
+	}
+}
+
+func busOf(m *Mixer, name string) *MixBus {
+	if name == "" {
+		return nil
+	}
+	return m.Bus(name)
+}
+
+func formatEnum(code uint32) FormatEnum {
+	for i, c := range formatCodes {
+		if c == code {
+			return FormatEnum(i)
+		}
+	}
+	return FormatNone
+}
+
+const (
+	FormatMono8    = 0x1100
+	FormatMono16   = 0x1101
+	FormatStereo8  = 0x1102
+	FormatStereo16 = 0x1103
+)
+
+// state, same as OpenAL
+const (
+	Initial = 0x1011
+	Playing = 0x1012
+	Paused  = 0x1013
+	Stopped = 0x1014
+)
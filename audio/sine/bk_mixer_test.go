@@ -0,0 +1,158 @@
+//+build mixer
+
+package sine
+
+import (
+	"testing"
+)
+
+// engine with a 100Hz mixer, so the rendered frames are easy to count
+func newTestEngine(sink Sink) *Engine {
+	return &Engine{mixer: NewMixer(100, sink)}
+}
+
+func mono16(samples ...int16) []byte {
+	bits := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		bits[i*2], bits[i*2+1] = byte(s), byte(uint16(s)>>8)
+	}
+	return bits
+}
+
+func TestBufferPlayer(t *testing.T) {
+	sink := &memSink{}
+	eng := newTestEngine(sink)
+	p := &BufferPlayer{}
+	p.initialize(eng)
+
+	data := &StaticData{}
+	data.Create(FormatMono16, mono16(1000, 2000), 100)
+	p.Play(data)
+	p.SetVolume(.5)
+	eng.mixer.Render(2)
+	if want := []int16{500, 500, 1000, 1000}; !equal(sink.frames, want) {
+		t.Errorf("play: got %v, want %v", sink.frames, want)
+	}
+
+	// pause and resume, loop forever
+	sink.frames = sink.frames[:0]
+	p.SetVolume(1)
+	p.SetLoop(-1)
+	p.Play(data)
+	p.Pause()
+	if s := p.State(); s != Paused {
+		t.Errorf("state: got %x, want Paused", s)
+	}
+	eng.mixer.Render(1)
+	p.Resume()
+	eng.mixer.Render(3)
+	if want := []int16{0, 0, 1000, 1000, 2000, 2000, 1000, 1000}; !equal(sink.frames, want) {
+		t.Errorf("pause: got %v, want %v", sink.frames, want)
+	}
+
+	// bus
+	sink.frames = sink.frames[:0]
+	eng.mixer.Bus("sfx").SetFilters(gainFilter(.5))
+	p.SetBus("sfx")
+	eng.mixer.Render(1)
+	if want := []int16{1000, 1000}; !equal(sink.frames, want) {
+		t.Errorf("bus: got %v, want %v", sink.frames, want)
+	}
+
+	p.Stop()
+	if s := p.State(); s != Stopped {
+		t.Errorf("state: got %x, want Stopped", s)
+	}
+}
+
+func TestStreamPlayerFill(t *testing.T) {
+	sink := &memSink{}
+	eng := newTestEngine(sink)
+	p := &StreamPlayer{}
+	p.initialize(eng)
+
+	d := &countDecoder{frames: 12, chunk: 2}
+	p.Play(&StreamData{decoder: d})
+	if n := p.voice.Queued(); n != streamBuffers {
+		t.Fatalf("queued: got %d, want %d", n, streamBuffers)
+	}
+
+	// drain the queued buffers, the voice stops before new data is fed
+	eng.mixer.Render(10)
+	if s := p.State(); s != Stopped {
+		t.Errorf("state: got %x, want Stopped", s)
+	}
+	p.Tick()
+	if s := p.State(); s != Playing {
+		t.Errorf("state: got %x, want Playing", s)
+	}
+	eng.mixer.Render(4)
+
+	var want []int16
+	for i := 0; i < 8; i++ {
+		want = append(want, (int16(i)-128)<<8, (int16(i)-128)<<8)
+	}
+	want = append(want, 0, 0, 0, 0)
+	for i := 8; i < 12; i++ {
+		want = append(want, (int16(i)-128)<<8, (int16(i)-128)<<8)
+	}
+	if !equal(sink.frames, want) {
+		t.Errorf("stream: got %v, want %v", sink.frames, want)
+	}
+
+	// stop rewinds the decoder
+	p.Stop()
+	if d.pos != 0 || p.State() != Stopped || p.voice.Queued() != 0 {
+		t.Errorf("stop: pos %d, state %x, queued %d", d.pos, p.State(), p.voice.Queued())
+	}
+	p.Tick()
+	if n := p.voice.Queued(); n != 0 {
+		t.Errorf("stopped player should not fill, queued %d", n)
+	}
+}
+
+func TestSoundPool(t *testing.T) {
+	sink := &memSink{}
+	eng := newTestEngine(sink)
+	am := NewAudioManager()
+	short, _ := am.LoadPCM(&PCM{FormatMono16, mono16(100), 100})
+	long, _ := am.LoadPCM(&PCM{FormatMono16, mono16(10, 10, 10, 10), 100})
+	sp := (&SoundPool{}).initialize(am, eng, 2)
+
+	a := sp.Play(short, 1)
+	b := sp.Play(long, 1)
+	if a == 0 || b == 0 || a == b {
+		t.Fatalf("play: got channel %d, %d", a, b)
+	}
+	// all the channels are used by higher priority
+	if id := sp.Play(long, 0); id != 0 {
+		t.Errorf("low priority should fail, got channel %d", id)
+	}
+	// steal a channel
+	c := sp.Play(long, 2)
+	if c == 0 {
+		t.Fatal("high priority should steal a channel")
+	}
+	if sp.ChanPlaying(b) || !sp.ChanPlaying(a) || !sp.ChanPlaying(c) {
+		t.Errorf("playing: %v %v %v", sp.ChanPlaying(a), sp.ChanPlaying(b), sp.ChanPlaying(c))
+	}
+
+	sp.SetChanVolume(c, .5)
+	if v, ok := sp.GetChanVolume(c); !ok || v != .5 {
+		t.Errorf("chan volume: got %v %v", v, ok)
+	}
+	eng.mixer.Render(1)
+	if want := []int16{105, 105}; !equal(sink.frames, want) {
+		t.Errorf("mix: got %v, want %v", sink.frames, want)
+	}
+
+	// the short sound is finished, its channel is released by Tick
+	eng.mixer.Render(1)
+	sp.Tick()
+	if sp.ChanPlaying(a) {
+		t.Error("channel should be released")
+	}
+	if id := sp.Play(short, 0); id == 0 {
+		t.Error("released channel should be reused")
+	}
+}
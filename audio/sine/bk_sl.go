@@ -1,4 +1,4 @@
-// +build android,!js
+// +build android,!js,!mixer
 
 package sine
 
@@ -175,6 +175,15 @@ SLresult SineBufferPlayer_getVolume(SineBufferPlayer *p, SLmillibel *v) {
 	return (*p->volume)->GetVolumeLevel(p->volume, v);
 }
 
+// unit: permille, -1000 is left and 1000 is right
+SLresult SineBufferPlayer_setPan(SineBufferPlayer *p, SLpermille pan) {
+	SLresult ret = (*p->volume)->EnableStereoPosition(p->volume, SL_BOOLEAN_TRUE);
+	if (ret != SL_RESULT_SUCCESS) {
+		return ret;
+	}
+	return (*p->volume)->SetStereoPosition(p->volume, pan);
+}
+
 //////////////////// StreamPlayer /////////////////////
 
 typedef struct SineBuffer {
@@ -448,6 +457,13 @@ func (p *BufferPlayer) SetVolume(v float32) {
 	}
 }
 
+// SetPan sets the stereo position in [-1, 1], -1 is left and 1 is right.
+func (p *BufferPlayer) SetPan(pan float32) {
+	if ret := C.SineBufferPlayer_setPan(&p.player, C.SLpermille(pan*1000)); ret != OK {
+		log.Print("buffer-player set pan err:", ret)
+	}
+}
+
 func (p *BufferPlayer) SetLoop(n int) {
 
 }
@@ -14,7 +14,7 @@
 
 // vanishs 修改于github.com/hajimehoshi/oto
 
-//+build js
+//+build js,!mixer
 
 package sine
 
@@ -274,6 +274,9 @@ func (p *BufferPlayer) Volume() float32 {
 func (p *BufferPlayer) SetVolume(v float32) {
 }
 
+func (p *BufferPlayer) SetPan(pan float32) {
+}
+
 func (p *BufferPlayer) SetLoop(loop int) {
 
 }
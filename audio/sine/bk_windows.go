@@ -14,7 +14,7 @@
 
 // vanishs 修改于github.com/hajimehoshi/oto
 
-//+build windows
+//+build windows,!mixer
 
 package sine
 
@@ -436,6 +436,9 @@ func (p *BufferPlayer) Volume() float32 {
 func (p *BufferPlayer) SetVolume(v float32) {
 }
 
+func (p *BufferPlayer) SetPan(pan float32) {
+}
+
 func (p *BufferPlayer) SetLoop(loop int) {
 
 }
@@ -0,0 +1,125 @@
+package sine
+
+import (
+	"log"
+	"sort"
+)
+
+// Filter processes the frames of a MixBus, the frames are interleaved
+// stereo samples in the range of 16-bit pcm.
+type Filter interface {
+	Process(frames []float32, rate int32)
+}
+
+// MixBus is a sub-mix of the Mixer. The voices routed to the bus are
+// mixed together, processed by the filters, then mixed to the parent
+// bus(or the output if no parent).
+type MixBus struct {
+	m       *Mixer
+	name    string
+	parent  *MixBus
+	filters []Filter
+
+	acc []float32
+}
+
+// Bus returns the bus of the name, it's created if not exist.
+func (m *Mixer) Bus(name string) *MixBus {
+	m.Lock()
+	defer m.Unlock()
+	b, ok := m.buses[name]
+	if !ok {
+		b = &MixBus{m: m, name: name}
+		m.buses[name] = b
+		m.order = nil
+	}
+	return b
+}
+
+func (b *MixBus) Name() string {
+	return b.name
+}
+
+// SetParent sets the parent bus, nil means the output of mixer. It fails
+// if the parent is a descendant of the bus.
+func (b *MixBus) SetParent(parent *MixBus) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	for p := parent; p != nil; p = p.parent {
+		if p == b {
+			log.Println("mix bus cycle:", b.name, parent.name)
+			return
+		}
+	}
+	b.parent = parent
+	b.m.order = nil
+}
+
+func (b *MixBus) Parent() *MixBus {
+	b.m.Lock()
+	defer b.m.Unlock()
+	return b.parent
+}
+
+// SetFilters replaces the filter chain of the bus, the filters are
+// processed in order.
+func (b *MixBus) SetFilters(filters ...Filter) {
+	b.m.Lock()
+	b.filters = append(b.filters[:0], filters...)
+	b.m.Unlock()
+}
+
+func (b *MixBus) clear(n int) {
+	if cap(b.acc) < n {
+		b.acc = make([]float32, n)
+	}
+	b.acc = b.acc[:n]
+	for i := range b.acc {
+		b.acc[i] = 0
+	}
+}
+
+func (b *MixBus) depth() (d int) {
+	for p := b.parent; p != nil; p = p.parent {
+		d++
+	}
+	return
+}
+
+// process the buses from leaves to root, so a bus is processed after all
+// its children have been mixed into it.
+func (m *Mixer) mixBuses(out []float32) {
+	if len(m.buses) == 0 {
+		return
+	}
+	if m.order == nil {
+		m.sortBuses()
+	}
+	for _, b := range m.order {
+		for _, f := range b.filters {
+			f.Process(b.acc, m.rate)
+		}
+		dst := out
+		if b.parent != nil {
+			dst = b.parent.acc
+		}
+		for i, s := range b.acc {
+			dst[i] += s
+		}
+	}
+}
+
+// sort the buses by depth, it's only called when the graph is changed.
+func (m *Mixer) sortBuses() {
+	buses := make([]*MixBus, 0, len(m.buses))
+	for _, b := range m.buses {
+		buses = append(buses, b)
+	}
+	sort.Slice(buses, func(i, j int) bool {
+		if di, dj := buses[i].depth(), buses[j].depth(); di != dj {
+			return di > dj
+		}
+		return buses[i].name < buses[j].name
+	})
+	m.order = buses
+}
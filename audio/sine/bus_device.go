@@ -0,0 +1,17 @@
+//+build !mixer
+
+package sine
+
+// The audio device backends can't process the audio with Filter, the
+// buses are only used to control volume. Build with tag 'mixer' to use
+// the software mixer which supports filters.
+
+// SetBusFilters does nothing on the device backends.
+func SetBusFilters(bus string, filters ...Filter) {}
+
+// SetBusParent does nothing on the device backends.
+func SetBusParent(bus, parent string) {}
+
+func (p *BufferPlayer) SetBus(bus string) {}
+
+func (p *StreamPlayer) SetBus(bus string) {}
@@ -7,7 +7,8 @@ type Decoder interface {
 	// helper method for in-memory decode
 	FullDecode(file res.File) (d []byte, numChan, bitDepth, freq int32, err error)
 
-	// stream decode
+	// stream decode, Decode returns the number of samples(not frames or
+	// bytes) decoded into Buffer, 0 if nothing is decoded
 	Decode() int
 	NumOfChan() int32
 	BitDepth() int32
@@ -17,6 +18,13 @@ type Decoder interface {
 	Rewind()
 }
 
+// SizedDecoder is a Decoder which knows the length of stream.
+type SizedDecoder interface {
+	Decoder
+	// Length returns the number of sample frames, <0 if unknown.
+	Length() int64
+}
+
 // decoder factory, we use'll used it to
 // create new decoder by file-type
 type DecoderFactory interface {
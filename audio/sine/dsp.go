@@ -0,0 +1,227 @@
+package sine
+
+import (
+	"math"
+)
+
+// LowPass is a one-pole low-pass filter, the frequencies above Cutoff
+// are attenuated by 6dB per octave.
+type LowPass struct {
+	// cutoff frequency in Hz
+	Cutoff float32
+
+	y [2]float32
+}
+
+func NewLowPass(cutoff float32) *LowPass {
+	return &LowPass{Cutoff: cutoff}
+}
+
+func (f *LowPass) Process(frames []float32, rate int32) {
+	a := onePole(f.Cutoff, rate)
+	for i := 0; i+1 < len(frames); i += 2 {
+		f.y[0] += a * (frames[i] - f.y[0])
+		f.y[1] += a * (frames[i+1] - f.y[1])
+		frames[i], frames[i+1] = f.y[0], f.y[1]
+	}
+}
+
+// HighPass is a one-pole high-pass filter, the frequencies below Cutoff
+// are attenuated by 6dB per octave.
+type HighPass struct {
+	// cutoff frequency in Hz
+	Cutoff float32
+
+	y [2]float32
+}
+
+func NewHighPass(cutoff float32) *HighPass {
+	return &HighPass{Cutoff: cutoff}
+}
+
+func (f *HighPass) Process(frames []float32, rate int32) {
+	a := onePole(f.Cutoff, rate)
+	for i := 0; i+1 < len(frames); i += 2 {
+		f.y[0] += a * (frames[i] - f.y[0])
+		f.y[1] += a * (frames[i+1] - f.y[1])
+		frames[i] -= f.y[0]
+		frames[i+1] -= f.y[1]
+	}
+}
+
+// coefficient of one-pole filter
+func onePole(cutoff float32, rate int32) float32 {
+	if cutoff <= 0 || rate <= 0 {
+		return 0
+	}
+	return float32(1 - math.Exp(-2*math.Pi*float64(cutoff)/float64(rate)))
+}
+
+// Reverb is a simple Schroeder reverb(like Freeverb), 4 comb filters
+// and 2 all-pass filters per channel.
+type Reverb struct {
+	// room size in [0, 1], longer tail with larger room
+	RoomSize float32
+	// high frequency damping in [0, 1]
+	Damping float32
+	// wet and dry gain
+	Wet, Dry float32
+
+	rate   int32
+	combs  [2][4]comb
+	passes [2][2]allPass
+}
+
+func NewReverb(room, damping, wet float32) *Reverb {
+	return &Reverb{RoomSize: room, Damping: damping, Wet: wet, Dry: 1}
+}
+
+// delay lines in samples at 44100Hz, from Freeverb
+var (
+	combTuning    = [4]int{1116, 1188, 1277, 1356}
+	allPassTuning = [2]int{556, 441}
+)
+
+// right channel is spread to make stereo
+const reverbSpread = 23
+
+func (r *Reverb) setup(rate int32) {
+	r.rate = rate
+	scale := float64(rate) / 44100
+	for ch := 0; ch < 2; ch++ {
+		for i, n := range combTuning {
+			r.combs[ch][i] = comb{buf: make([]float32, int(float64(n+ch*reverbSpread)*scale)+1)}
+		}
+		for i, n := range allPassTuning {
+			r.passes[ch][i] = allPass{buf: make([]float32, int(float64(n+ch*reverbSpread)*scale)+1)}
+		}
+	}
+}
+
+func (r *Reverb) Process(frames []float32, rate int32) {
+	if r.rate != rate {
+		r.setup(rate)
+	}
+	feedback := .7 + .28*clamp01(r.RoomSize)
+	damp := .4 * clamp01(r.Damping)
+	for i := 0; i+1 < len(frames); i += 2 {
+		// mono input, scaled to avoid overflow in the combs
+		in := (frames[i] + frames[i+1]) * .015
+		for ch := 0; ch < 2; ch++ {
+			out := float32(0)
+			for k := range r.combs[ch] {
+				out += r.combs[ch][k].process(in, feedback, damp)
+			}
+			for k := range r.passes[ch] {
+				out = r.passes[ch][k].process(out)
+			}
+			frames[i+ch] = frames[i+ch]*r.Dry + out*r.Wet
+		}
+	}
+}
+
+type comb struct {
+	buf   []float32
+	pos   int
+	store float32
+}
+
+func (c *comb) process(in, feedback, damp float32) float32 {
+	out := c.buf[c.pos]
+	c.store = out*(1-damp) + c.store*damp
+	c.buf[c.pos] = in + c.store*feedback
+	if c.pos++; c.pos == len(c.buf) {
+		c.pos = 0
+	}
+	return out
+}
+
+type allPass struct {
+	buf []float32
+	pos int
+}
+
+func (a *allPass) process(in float32) float32 {
+	delayed := a.buf[a.pos]
+	a.buf[a.pos] = in + delayed*.5
+	if a.pos++; a.pos == len(a.buf) {
+		a.pos = 0
+	}
+	return delayed - in
+}
+
+// Compressor reduces the volume above the Threshold by the Ratio, it
+// follows the peak of both channels.
+type Compressor struct {
+	// threshold in dB of full scale, eg: -12
+	Threshold float32
+	// eg: 4 means 4:1
+	Ratio float32
+	// attack and release time in seconds
+	Attack, Release float32
+	// makeup gain in dB
+	Makeup float32
+
+	env float32
+}
+
+func NewCompressor(threshold, ratio float32) *Compressor {
+	return &Compressor{Threshold: threshold, Ratio: ratio, Attack: .005, Release: .1}
+}
+
+func (c *Compressor) Process(frames []float32, rate int32) {
+	attack, release := timeCoef(c.Attack, rate), timeCoef(c.Release, rate)
+	makeup := dbToGain(c.Makeup)
+	for i := 0; i+1 < len(frames); i += 2 {
+		peak := abs(frames[i])
+		if r := abs(frames[i+1]); r > peak {
+			peak = r
+		}
+		peak /= 32768
+		if peak > c.env {
+			c.env += attack * (peak - c.env)
+		} else {
+			c.env += release * (peak - c.env)
+		}
+		gain := makeup
+		if c.Ratio > 1 && c.env > 0 {
+			if over := gainToDb(c.env) - c.Threshold; over > 0 {
+				gain *= dbToGain(over/c.Ratio - over)
+			}
+		}
+		frames[i] *= gain
+		frames[i+1] *= gain
+	}
+}
+
+// coefficient of envelope follower, 1 means no smoothing
+func timeCoef(t float32, rate int32) float32 {
+	if t <= 0 || rate <= 0 {
+		return 1
+	}
+	return float32(1 - math.Exp(-1/(float64(t)*float64(rate))))
+}
+
+func dbToGain(db float32) float32 {
+	return float32(math.Pow(10, float64(db)/20))
+}
+
+func gainToDb(g float32) float32 {
+	return float32(20 * math.Log10(float64(g)))
+}
+
+func abs(f float32) float32 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
+
+func clamp01(f float32) float32 {
+	if f < 0 {
+		return 0
+	} else if f > 1 {
+		return 1
+	}
+	return f
+}
@@ -0,0 +1,161 @@
+package sine
+
+// NewStreamData creates a StreamData with the decoder, it's not managed
+// by the AudioManager.
+func NewStreamData(d Decoder) *StreamData {
+	return &StreamData{decoder: d}
+}
+
+// Decoder returns the decoder of the stream.
+func (d *StreamData) Decoder() Decoder {
+	return d.decoder
+}
+
+// LoopDecoder plays the intro [0, start) once, then repeats the loop
+// body [start, end) without gap. The positions are in sample frames,
+// end <= 0 means the end of the stream.
+type LoopDecoder struct {
+	Decoder
+
+	start, end int64
+	// times to repeat the loop body, <0 means forever
+	loop, loops int
+
+	// frames decoded
+	pos int64
+	// frames output since the last loop back
+	pass     int64
+	buf      []byte
+	reachEnd bool
+}
+
+func NewLoopDecoder(d Decoder, start, end int64, loop int) *LoopDecoder {
+	if start < 0 {
+		start = 0
+	}
+	if end > 0 && end <= start {
+		end = 0
+	}
+	return &LoopDecoder{Decoder: d, start: start, end: end, loop: loop, loops: loop}
+}
+
+// Length returns the total frames to play, <0 if it loops forever or
+// the length of stream is unknown.
+func (d *LoopDecoder) Length() int64 {
+	sd, ok := d.Decoder.(SizedDecoder)
+	if !ok || d.loops < 0 {
+		return -1
+	}
+	n := sd.Length()
+	if n < 0 {
+		return -1
+	}
+	end := d.end
+	if end <= 0 || end > n {
+		end = n
+	}
+	if end <= d.start {
+		return end
+	}
+	return end + (end-d.start)*int64(d.loops)
+}
+
+// Loop returns the remained times to repeat.
+func (d *LoopDecoder) Loop() int {
+	return d.loop
+}
+
+func (d *LoopDecoder) frameSize() int64 {
+	size := int64(d.NumOfChan() * d.BitDepth() / 8)
+	if size == 0 {
+		size = 1
+	}
+	return size
+}
+
+// bytes of a sample
+func (d *LoopDecoder) sampleSize() int {
+	if size := int(d.BitDepth() / 8); size > 0 {
+		return size
+	}
+	return 1
+}
+
+func (d *LoopDecoder) Decode() int {
+	d.buf = d.buf[:0]
+	fs := d.frameSize()
+	for len(d.buf) == 0 && !d.reachEnd {
+		if (d.end > 0 && d.pos >= d.end) || d.Decoder.ReachEnd() {
+			d.loopBack()
+			continue
+		}
+		if n := d.Decoder.Decode(); n == 0 {
+			if !d.Decoder.ReachEnd() {
+				d.reachEnd = true
+			}
+			continue
+		}
+		buf := d.Decoder.Buffer()
+		frames := int64(len(buf)) / fs
+		if d.end > 0 && d.pos+frames > d.end {
+			frames = d.end - d.pos
+		}
+		d.buf = append(d.buf, buf[:frames*fs]...)
+		d.pos += frames
+		d.pass += frames
+	}
+	return len(d.buf) / d.sampleSize()
+}
+
+// rewinds and skips to the loop start
+func (d *LoopDecoder) loopBack() {
+	// stop if nothing is decoded in a whole pass, or it spins forever
+	if d.loop == 0 || d.pass == 0 {
+		d.reachEnd = true
+		return
+	}
+	if d.loop > 0 {
+		d.loop--
+	}
+	d.Decoder.Rewind()
+	d.pos, d.pass = 0, 0
+	fs := d.frameSize()
+	for d.pos < d.start {
+		if n := d.Decoder.Decode(); n == 0 {
+			d.reachEnd = true
+			return
+		}
+		buf := d.Decoder.Buffer()
+		frames := int64(len(buf)) / fs
+		if to := d.pos + frames; to > d.start {
+			if d.end > 0 && to > d.end {
+				to = d.end
+			}
+			d.buf = append(d.buf, buf[(d.start-d.pos)*fs:(to-d.pos)*fs]...)
+			d.pass += to - d.start
+			d.pos = to
+			break
+		}
+		d.pos += frames
+		if d.Decoder.ReachEnd() {
+			d.reachEnd = true
+			return
+		}
+	}
+}
+
+func (d *LoopDecoder) Buffer() []byte {
+	return d.buf
+}
+
+func (d *LoopDecoder) ReachEnd() bool {
+	return d.reachEnd
+}
+
+func (d *LoopDecoder) Rewind() {
+	d.Decoder.Rewind()
+	d.pos, d.pass = 0, 0
+	d.buf = d.buf[:0]
+	d.loop = d.loops
+	d.reachEnd = false
+}
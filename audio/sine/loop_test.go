@@ -0,0 +1,103 @@
+package sine
+
+import (
+	"korok.io/korok/asset/res"
+
+	"testing"
+)
+
+// mono 8-bit decoder, each frame is its index
+type countDecoder struct {
+	frames, chunk int
+	pos           int
+	buf           []byte
+}
+
+func (d *countDecoder) FullDecode(file res.File) ([]byte, int32, int32, int32, error) {
+	return nil, 1, 8, 100, nil
+}
+
+func (d *countDecoder) Decode() int {
+	d.buf = d.buf[:0]
+	for i := 0; i < d.chunk && d.pos < d.frames; i++ {
+		d.buf = append(d.buf, byte(d.pos))
+		d.pos++
+	}
+	return len(d.buf)
+}
+
+func (d *countDecoder) NumOfChan() int32  { return 1 }
+func (d *countDecoder) BitDepth() int32   { return 8 }
+func (d *countDecoder) SampleRate() int32 { return 100 }
+func (d *countDecoder) Buffer() []byte    { return d.buf }
+func (d *countDecoder) ReachEnd() bool    { return d.pos >= d.frames }
+func (d *countDecoder) Rewind()           { d.pos = 0 }
+
+func decodeAll(d Decoder, max int) (out []byte) {
+	for !d.ReachEnd() && len(out) < max {
+		if d.Decode() == 0 {
+			break
+		}
+		out = append(out, d.Buffer()...)
+	}
+	return
+}
+
+func TestLoopDecoder(t *testing.T) {
+	// intro [0, 3), loop body [3, 6) repeats twice
+	d := NewLoopDecoder(&countDecoder{frames: 10, chunk: 4}, 3, 6, 2)
+	out := decodeAll(d, 100)
+	want := []byte{0, 1, 2, 3, 4, 5, 3, 4, 5, 3, 4, 5}
+	if string(out) != string(want) {
+		t.Errorf("loop points: got %v, want %v", out, want)
+	}
+
+	// loop to the end of stream forever
+	d = NewLoopDecoder(&countDecoder{frames: 5, chunk: 2}, 2, 0, -1)
+	out = decodeAll(d, 11)
+	want = []byte{0, 1, 2, 3, 4, 2, 3, 4, 2, 3, 4}
+	if string(out[:11]) != string(want) {
+		t.Errorf("forever: got %v, want %v", out, want)
+	}
+	if d.ReachEnd() {
+		t.Error("loop forever should not reach end")
+	}
+
+	// empty stream or empty loop body doesn't spin forever
+	d = NewLoopDecoder(&countDecoder{frames: 0, chunk: 2}, 0, 0, -1)
+	if out = decodeAll(d, 100); len(out) != 0 || !d.ReachEnd() {
+		t.Errorf("empty stream: got %v", out)
+	}
+	d = NewLoopDecoder(&countDecoder{frames: 4, chunk: 2}, 6, 0, -1)
+	if out = decodeAll(d, 100); len(out) != 4 || !d.ReachEnd() {
+		t.Errorf("empty loop body: got %v", out)
+	}
+
+	// length of the loops
+	d = NewLoopDecoder(&countDecoder{frames: 10, chunk: 4}, 3, 6, 2)
+	if n := d.Length(); n != -1 {
+		t.Errorf("length of unsized stream: %d", n)
+	}
+
+	// rewind restores the loop count
+	d = NewLoopDecoder(&countDecoder{frames: 4, chunk: 3}, 0, 0, 1)
+	decodeAll(d, 100)
+	d.Rewind()
+	if out = decodeAll(d, 100); len(out) != 8 {
+		t.Errorf("rewind: got %v", out)
+	}
+}
+
+// mono 16-bit, each frame is two bytes of its index
+type count16Decoder struct {
+	countDecoder
+}
+
+func (d *count16Decoder) BitDepth() int32 { return 16 }
+
+func TestLoopDecoderSamples(t *testing.T) {
+	d := NewLoopDecoder(&count16Decoder{countDecoder{frames: 8, chunk: 4}}, 2, 0, 1)
+	if n := d.Decode(); n != 2 || len(d.Buffer()) != 4 {
+		t.Errorf("decode: %d samples, %d bytes", n, len(d.Buffer()))
+	}
+}
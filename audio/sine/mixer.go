@@ -0,0 +1,334 @@
+package sine
+
+import (
+	"sync"
+)
+
+// DefaultSampleRate is the output sample rate of the Mixer.
+const DefaultSampleRate = 44100
+
+// Mixer is a software mixer written in pure Go. It mixes the playing
+// voices into 16-bit stereo frames, voices with different sample rate
+// are resampled by linear interpolation. The mixed frames are written to
+// a Sink, so the audio can be played(or tested) without audio device.
+type Mixer struct {
+	sync.Mutex
+
+	rate   int32
+	volume float32
+	voices []*Voice
+	buses  map[string]*MixBus
+	sink   Sink
+
+	// buses in processing order, nil if the graph is changed
+	order []*MixBus
+
+	// mix buffer
+	acc []float32
+	out []int16
+}
+
+func NewMixer(rate int32, sink Sink) *Mixer {
+	if rate <= 0 {
+		rate = DefaultSampleRate
+	}
+	if sink == nil {
+		sink = NullSink{}
+	}
+	return &Mixer{rate: rate, volume: 1, sink: sink, buses: make(map[string]*MixBus)}
+}
+
+func (m *Mixer) SampleRate() int32 {
+	return m.rate
+}
+
+func (m *Mixer) SetSink(sink Sink) {
+	m.Lock()
+	defer m.Unlock()
+	if sink == nil {
+		sink = NullSink{}
+	}
+	m.sink = sink
+}
+
+// SetVolume sets the master volume.
+func (m *Mixer) SetVolume(v float32) {
+	m.Lock()
+	m.volume = v
+	m.Unlock()
+}
+
+func (m *Mixer) Volume() float32 {
+	m.Lock()
+	defer m.Unlock()
+	return m.volume
+}
+
+// NewVoice creates a voice in the mixer, a voice is only mixed when it's
+// playing.
+func (m *Mixer) NewVoice() *Voice {
+	m.Lock()
+	defer m.Unlock()
+	v := &Voice{m: m, volume: 1, state: Initial}
+	m.voices = append(m.voices, v)
+	return v
+}
+
+// Mix mixes len(out)/2 stereo frames into out.
+func (m *Mixer) Mix(out []int16) {
+	m.Lock()
+	defer m.Unlock()
+	m.mix(out)
+}
+
+func (m *Mixer) mix(out []int16) {
+	frames := len(out) / 2
+	if cap(m.acc) < frames*2 {
+		m.acc = make([]float32, frames*2)
+	}
+	acc := m.acc[:frames*2]
+	for i := range acc {
+		acc[i] = 0
+	}
+	for _, b := range m.buses {
+		b.clear(frames * 2)
+	}
+	for _, v := range m.voices {
+		if v.state != Playing {
+			continue
+		}
+		if b := v.bus; b != nil {
+			v.mix(b.acc, m.rate)
+		} else {
+			v.mix(acc, m.rate)
+		}
+	}
+	m.mixBuses(acc)
+	for i, s := range acc {
+		s *= m.volume
+		if s > 32767 {
+			s = 32767
+		} else if s < -32768 {
+			s = -32768
+		}
+		out[i] = int16(s)
+	}
+}
+
+// Render mixes the frames and writes them to the sink.
+func (m *Mixer) Render(frames int) error {
+	m.Lock()
+	defer m.Unlock()
+	if cap(m.out) < frames*2 {
+		m.out = make([]int16, frames*2)
+	}
+	out := m.out[:frames*2]
+	m.mix(out)
+	return m.sink.Write(out)
+}
+
+// Close closes the sink.
+func (m *Mixer) Close() error {
+	m.Lock()
+	defer m.Unlock()
+	return m.sink.Close()
+}
+
+// Voice plays 16-bit pcm data in the Mixer. A static sound is played
+// with Play, a stream is played by queuing buffers and Start.
+type Voice struct {
+	m   *Mixer
+	bus *MixBus
+
+	channels int
+	rate     int32
+	queue    [][]int16
+	pos      float64
+
+	volume, pan float32
+	// <0 means loop forever, or the times to repeat
+	loop  int
+	state uint32
+}
+
+// Play plays the pcm data from the beginning, the data is shared.
+func (v *Voice) Play(pcm []int16, channels int, rate int32) {
+	v.m.Lock()
+	defer v.m.Unlock()
+	v.setFormat(channels, rate)
+	v.queue = append(v.queue[:0], pcm)
+	v.pos = 0
+	v.state = Playing
+}
+
+// Queue appends a buffer to the voice, the data is shared.
+func (v *Voice) Queue(pcm []int16, channels int, rate int32) {
+	v.m.Lock()
+	defer v.m.Unlock()
+	v.setFormat(channels, rate)
+	v.queue = append(v.queue, pcm)
+}
+
+// Queued returns the number of buffers not played.
+func (v *Voice) Queued() int {
+	v.m.Lock()
+	defer v.m.Unlock()
+	return len(v.queue)
+}
+
+// Start plays the queued buffers.
+func (v *Voice) Start() {
+	v.m.Lock()
+	v.state = Playing
+	v.m.Unlock()
+}
+
+// Stop stops the voice and drops the queued buffers.
+func (v *Voice) Stop() {
+	v.m.Lock()
+	v.state = Stopped
+	v.queue = v.queue[:0]
+	v.pos = 0
+	v.m.Unlock()
+}
+
+func (v *Voice) Pause() {
+	v.m.Lock()
+	if v.state == Playing {
+		v.state = Paused
+	}
+	v.m.Unlock()
+}
+
+func (v *Voice) Resume() {
+	v.m.Lock()
+	if v.state == Paused {
+		v.state = Playing
+	}
+	v.m.Unlock()
+}
+
+func (v *Voice) State() uint32 {
+	v.m.Lock()
+	defer v.m.Unlock()
+	return v.state
+}
+
+func (v *Voice) Volume() float32 {
+	v.m.Lock()
+	defer v.m.Unlock()
+	return v.volume
+}
+
+func (v *Voice) SetVolume(volume float32) {
+	v.m.Lock()
+	v.volume = volume
+	v.m.Unlock()
+}
+
+// SetPan sets the stereo position in [-1, 1], -1 is left and 1 is right.
+func (v *Voice) SetPan(pan float32) {
+	if pan < -1 {
+		pan = -1
+	} else if pan > 1 {
+		pan = 1
+	}
+	v.m.Lock()
+	v.pan = pan
+	v.m.Unlock()
+}
+
+// SetLoop sets the times to repeat the static sound, <0 means forever.
+func (v *Voice) SetLoop(loop int) {
+	v.m.Lock()
+	v.loop = loop
+	v.m.Unlock()
+}
+
+// SetBus routes the voice to the bus, nil means the output of mixer.
+func (v *Voice) SetBus(b *MixBus) {
+	v.m.Lock()
+	v.bus = b
+	v.m.Unlock()
+}
+
+func (v *Voice) setFormat(channels int, rate int32) {
+	if channels != 2 {
+		channels = 1
+	}
+	v.channels, v.rate = channels, rate
+}
+
+// mix into the stereo accumulator, rate is the output sample rate
+func (v *Voice) mix(acc []float32, rate int32) {
+	step := float64(1)
+	if v.rate > 0 {
+		step = float64(v.rate) / float64(rate)
+	}
+	// linear pan, the center is not attenuated
+	left, right := v.volume, v.volume
+	if v.pan > 0 {
+		left *= 1 - v.pan
+	} else {
+		right *= 1 + v.pan
+	}
+
+	ch := v.channels
+	for i := 0; i < len(acc); i += 2 {
+		if len(v.queue) == 0 {
+			v.state = Stopped
+			return
+		}
+		buf := v.queue[0]
+		frames := len(buf) / ch
+		n := int(v.pos)
+		if n >= frames {
+			// repeat the last buffer or play next
+			if v.loop != 0 && len(v.queue) == 1 && frames > 0 {
+				if v.loop > 0 {
+					v.loop--
+				}
+				v.pos -= float64(frames)
+			} else {
+				v.queue = v.queue[1:]
+				v.pos -= float64(frames)
+			}
+			i -= 2
+			continue
+		}
+		next, t := n+1, float32(v.pos-float64(n))
+		if next >= frames {
+			next = n
+		}
+		l := float32(buf[n*ch])*(1-t) + float32(buf[next*ch])*t
+		r := l
+		if ch == 2 {
+			r = float32(buf[n*ch+1])*(1-t) + float32(buf[next*ch+1])*t
+		}
+		acc[i] += l * left
+		acc[i+1] += r * right
+		v.pos += step
+	}
+}
+
+// convert pcm data of the format to 16-bit samples, returns the samples
+// and number of channels.
+func toPCM16(format FormatEnum, bits []byte) (pcm []int16, channels int) {
+	channels = 1
+	if format == Stereo8 || format == Stereo16 {
+		channels = 2
+	}
+	switch format {
+	case Mono8, Stereo8:
+		pcm = make([]int16, len(bits))
+		for i, b := range bits {
+			pcm[i] = (int16(b) - 128) << 8
+		}
+	case Mono16, Stereo16:
+		pcm = make([]int16, len(bits)/2)
+		for i := range pcm {
+			pcm[i] = int16(uint16(bits[i*2]) | uint16(bits[i*2+1])<<8)
+		}
+	}
+	return
+}
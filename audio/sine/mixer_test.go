@@ -0,0 +1,352 @@
+package sine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+type memSink struct {
+	frames []int16
+}
+
+func (s *memSink) Write(frames []int16) error {
+	s.frames = append(s.frames, frames...)
+	return nil
+}
+
+func (s *memSink) Close() error {
+	return nil
+}
+
+func TestMixVolumeAndPan(t *testing.T) {
+	sink := &memSink{}
+	m := NewMixer(100, sink)
+	v := m.NewVoice()
+	v.Play([]int16{1000, 1000, 1000, 1000}, 1, 100)
+	v.SetVolume(.5)
+	v.SetPan(1)
+
+	if err := m.Render(2); err != nil {
+		t.Fatal(err)
+	}
+	if l, r := sink.frames[0], sink.frames[1]; l != 0 || r != 500 {
+		t.Errorf("pan right: got (%d, %d), want (0, 500)", l, r)
+	}
+
+	v.SetPan(0)
+	m.SetVolume(2)
+	m.Render(2)
+	if l, r := sink.frames[4], sink.frames[5]; l != 1000 || r != 1000 {
+		t.Errorf("center: got (%d, %d), want (1000, 1000)", l, r)
+	}
+	if v.State() != Playing {
+		t.Errorf("state: got %x, want Playing", v.State())
+	}
+
+	// drained
+	m.Render(1)
+	if v.State() != Stopped {
+		t.Errorf("state: got %x, want Stopped", v.State())
+	}
+}
+
+func TestMixClamp(t *testing.T) {
+	out := make([]int16, 2)
+	m := NewMixer(100, nil)
+	for i := 0; i < 2; i++ {
+		m.NewVoice().Play([]int16{30000, -30000}, 2, 100)
+	}
+	m.Mix(out)
+	if out[0] != 32767 || out[1] != -32768 {
+		t.Errorf("clamp: got %v", out)
+	}
+}
+
+func TestResample(t *testing.T) {
+	sink := &memSink{}
+	m := NewMixer(200, sink)
+	v := m.NewVoice()
+	// 100Hz stereo, played at 200Hz takes twice frames
+	v.Play([]int16{0, 0, 100, 200, 200, 400, 300, 600}, 2, 100)
+
+	m.Render(8)
+	want := []int16{0, 0, 50, 100, 100, 200, 150, 300, 200, 400, 250, 500, 300, 600, 300, 600}
+	for i, s := range want {
+		if sink.frames[i] != s {
+			t.Fatalf("resample: got %v, want %v", sink.frames, want)
+		}
+	}
+	m.Render(1)
+	if v.State() != Stopped {
+		t.Errorf("state: got %x, want Stopped", v.State())
+	}
+}
+
+func TestLoopAndPause(t *testing.T) {
+	sink := &memSink{}
+	m := NewMixer(100, sink)
+	v := m.NewVoice()
+	v.Play([]int16{1, 2}, 1, 100)
+	v.SetLoop(1)
+
+	m.Render(4)
+	want := []int16{1, 1, 2, 2, 1, 1, 2, 2}
+	if !equal(sink.frames, want) {
+		t.Errorf("loop: got %v, want %v", sink.frames, want)
+	}
+	m.Render(1)
+	if v.State() != Stopped {
+		t.Errorf("state: got %x, want Stopped", v.State())
+	}
+
+	sink.frames = sink.frames[:0]
+	v.SetLoop(-1)
+	v.Play([]int16{1, 2}, 1, 100)
+	v.Pause()
+	m.Render(2)
+	v.Resume()
+	m.Render(3)
+	want = []int16{0, 0, 0, 0, 1, 1, 2, 2, 1, 1}
+	if !equal(sink.frames, want) {
+		t.Errorf("pause: got %v, want %v", sink.frames, want)
+	}
+	if v.State() != Playing {
+		t.Errorf("state: got %x, want Playing", v.State())
+	}
+	v.Stop()
+	if v.State() != Stopped || v.Queued() != 0 {
+		t.Errorf("stop: state %x, queued %d", v.State(), v.Queued())
+	}
+}
+
+func TestQueue(t *testing.T) {
+	sink := &memSink{}
+	m := NewMixer(100, sink)
+	v := m.NewVoice()
+	v.Queue([]int16{1}, 1, 100)
+	v.Queue([]int16{2, 3}, 1, 100)
+	if n := v.Queued(); n != 2 {
+		t.Errorf("queued: got %d, want 2", n)
+	}
+	v.Start()
+	m.Render(3)
+	if want := []int16{1, 1, 2, 2, 3, 3}; !equal(sink.frames, want) {
+		t.Errorf("queue: got %v, want %v", sink.frames, want)
+	}
+	if n := v.Queued(); n != 1 {
+		t.Errorf("queued: got %d, want 1", n)
+	}
+}
+
+func TestQueueResample(t *testing.T) {
+	sink := &memSink{}
+	m := NewMixer(100, sink)
+	v := m.NewVoice()
+	// 200Hz played at 100Hz skips every other frame, the position
+	// is carried to the next buffer
+	v.Queue([]int16{1, 2, 3}, 1, 200)
+	v.Queue([]int16{4, 5, 6, 7}, 1, 200)
+	v.Start()
+	m.Render(4)
+	if want := []int16{1, 1, 3, 3, 5, 5, 7, 7}; !equal(sink.frames, want) {
+		t.Errorf("queue resample: got %v, want %v", sink.frames, want)
+	}
+}
+
+func TestToPCM16(t *testing.T) {
+	pcm, ch := toPCM16(Mono8, []byte{0, 128, 255})
+	if ch != 1 || !equal(pcm, []int16{-32768, 0, 32512}) {
+		t.Errorf("mono8: got %v %d", pcm, ch)
+	}
+	pcm, ch = toPCM16(Stereo16, []byte{0x01, 0x00, 0xff, 0xff})
+	if ch != 2 || !equal(pcm, []int16{1, -1}) {
+		t.Errorf("stereo16: got %v %d", pcm, ch)
+	}
+}
+
+// in-memory io.WriteSeeker
+type memFile struct {
+	buf []byte
+	off int
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	if end := f.off + len(p); end > len(f.buf) {
+		f.buf = append(f.buf, make([]byte, end-len(f.buf))...)
+	}
+	n := copy(f.buf[f.off:], p)
+	f.off += n
+	return n, nil
+}
+
+func (f *memFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		f.off = int(offset)
+	case io.SeekCurrent:
+		f.off += int(offset)
+	case io.SeekEnd:
+		f.off = len(f.buf) + int(offset)
+	}
+	if f.off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	return int64(f.off), nil
+}
+
+func TestWavSink(t *testing.T) {
+	f := &memFile{}
+	s, err := NewWavSink(f, 8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMixer(8000, s)
+	m.NewVoice().Play([]int16{100, -100}, 1, 8000)
+	m.Render(3)
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(f.buf); n != 44+12 {
+		t.Fatalf("size: got %d, want %d", n, 44+12)
+	}
+	if !bytes.Equal(f.buf[0:4], []byte("RIFF")) || !bytes.Equal(f.buf[8:16], []byte("WAVEfmt ")) || !bytes.Equal(f.buf[36:40], []byte("data")) {
+		t.Errorf("header: %q", f.buf[:44])
+	}
+	le := binary.LittleEndian
+	if v := le.Uint32(f.buf[4:]); v != 36+12 {
+		t.Errorf("riff size: got %d", v)
+	}
+	if v := le.Uint32(f.buf[24:]); v != 8000 {
+		t.Errorf("rate: got %d", v)
+	}
+	if v := le.Uint32(f.buf[28:]); v != 8000*4 {
+		t.Errorf("byte rate: got %d", v)
+	}
+	if v := le.Uint32(f.buf[40:]); v != 12 {
+		t.Errorf("data size: got %d", v)
+	}
+	if v := int16(le.Uint16(f.buf[46:])); v != 100 {
+		t.Errorf("first sample: got %d", v)
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ws := NewWriterSink(buf)
+	ws.Write([]int16{1, -2})
+	if want := []byte{1, 0, 0xfe, 0xff}; !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("got %v, want %v", buf.Bytes(), want)
+	}
+}
+
+func equal(a, b []int16) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type gainFilter float32
+
+func (g gainFilter) Process(frames []float32, rate int32) {
+	for i := range frames {
+		frames[i] *= float32(g)
+	}
+}
+
+func TestMixBus(t *testing.T) {
+	sink := &memSink{}
+	m := NewMixer(100, sink)
+	sfx, master := m.Bus("sfx"), m.Bus("master")
+	sfx.SetParent(master)
+	sfx.SetFilters(gainFilter(.5))
+	master.SetFilters(gainFilter(.5))
+
+	m.NewVoice().Play([]int16{1000}, 1, 100)
+	v := m.NewVoice()
+	v.SetBus(sfx)
+	v.Play([]int16{1000}, 1, 100)
+	m.Render(1)
+	if l := sink.frames[0]; l != 1250 {
+		t.Errorf("bus: expect 1250, got %d", l)
+	}
+
+	// cycle is rejected
+	master.SetParent(sfx)
+	if master.Parent() != nil {
+		t.Error("cycle should be rejected")
+	}
+
+	// the order is cached until the graph is changed
+	if len(m.order) != 2 || m.order[0] != sfx {
+		t.Fatalf("bus order: %v", m.order)
+	}
+	sfx.SetParent(nil)
+	if m.order != nil {
+		t.Error("bus order should be rebuilt")
+	}
+	v.Play([]int16{1000}, 1, 100)
+	m.Render(1)
+	if l := sink.frames[2]; l != 500 {
+		t.Errorf("bus: expect 500, got %d", l)
+	}
+}
+
+func TestFilters(t *testing.T) {
+	dc := func(n int) []float32 {
+		frames := make([]float32, n*2)
+		for i := range frames {
+			frames[i] = 10000
+		}
+		return frames
+	}
+
+	// dc passes low-pass and is removed by high-pass
+	frames := dc(4410)
+	NewLowPass(1000).Process(frames, 44100)
+	if s := frames[len(frames)-1]; s < 9999 {
+		t.Errorf("low-pass: got %v", s)
+	}
+	frames = dc(4410)
+	NewHighPass(1000).Process(frames, 44100)
+	if s := frames[len(frames)-1]; abs(s) > 1 {
+		t.Errorf("high-pass: got %v", s)
+	}
+
+	// -6dB over -12dB threshold is reduced to -10.5dB with 4:1
+	frames = make([]float32, 44100*2)
+	for i := range frames {
+		frames[i] = 32768 * dbToGain(-6)
+	}
+	c := NewCompressor(-12, 4)
+	c.Process(frames, 44100)
+	if db := gainToDb(frames[len(frames)-1] / 32768); db < -10.6 || db > -10.4 {
+		t.Errorf("compressor: got %vdB", db)
+	}
+
+	// reverb tail after the impulse
+	frames = make([]float32, 4410*2)
+	frames[0], frames[1] = 10000, 10000
+	r := NewReverb(.5, .5, 1)
+	r.Dry = 0
+	r.Process(frames, 44100)
+	if frames[0] != 0 {
+		t.Errorf("reverb: dry should be 0, got %v", frames[0])
+	}
+	tail := float32(0)
+	for _, s := range frames[4000:] {
+		tail += abs(s)
+	}
+	if tail == 0 {
+		t.Error("reverb should have tail")
+	}
+}
@@ -147,6 +147,31 @@ func (sp *SoundPool) SetChanVolume(chanId int, v float32) {
 	}
 }
 
+// SetChanPan sets the stereo position for the specified channel,
+// pan is in [-1, 1].
+func (sp *SoundPool) SetChanPan(chanId int, pan float32) {
+	if ch, ok := sp.findChannel(chanId); ok {
+		ch.SetPan(pan)
+	}
+}
+
+// SetChanBus routes the specified channel to the bus, the
+// bus only works with software mixer.
+func (sp *SoundPool) SetChanBus(chanId int, bus string) {
+	if ch, ok := sp.findChannel(chanId); ok {
+		ch.SetBus(bus)
+	}
+}
+
+// ChanPlaying returns true if the specified channel is still
+// playing, a stopped channel may be reused by other sound.
+func (sp *SoundPool) ChanPlaying(chanId int) bool {
+	if ch, ok := sp.findChannel(chanId); ok {
+		return ch.playing && ch.State() != Stopped
+	}
+	return false
+}
+
 // GetChanVolume gets volume from the specified channel. Return
 // false if not found.
 func (sp *SoundPool) GetChanVolume(chanId int) (float32, bool) {
@@ -0,0 +1,135 @@
+package sine
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+// Sink receives the frames mixed by Mixer, the frames are 16-bit
+// interleaved stereo samples.
+type Sink interface {
+	Write(frames []int16) error
+	Close() error
+}
+
+// NullSink drops all the frames.
+type NullSink struct{}
+
+func (NullSink) Write(frames []int16) error {
+	return nil
+}
+
+func (NullSink) Close() error {
+	return nil
+}
+
+// WriterSink writes the frames to a io.Writer as little-endian raw pcm.
+type WriterSink struct {
+	w   io.Writer
+	buf []byte
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+func (ws *WriterSink) Write(frames []int16) error {
+	if size := len(frames) * 2; cap(ws.buf) < size {
+		ws.buf = make([]byte, size)
+	}
+	buf := ws.buf[:len(frames)*2]
+	for i, s := range frames {
+		binary.LittleEndian.PutUint16(buf[i*2:], uint16(s))
+	}
+	_, err := ws.w.Write(buf)
+	return err
+}
+
+// Close closes the writer if it's a io.Closer.
+func (ws *WriterSink) Close() error {
+	if c, ok := ws.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// WavSink writes the frames to a WAV file, the size fields of the header
+// are written when closed.
+type WavSink struct {
+	w    io.WriteSeeker
+	ws   *WriterSink
+	rate int32
+	size uint32
+}
+
+// NewWavSink writes the WAV header to w.
+func NewWavSink(w io.WriteSeeker, rate int32) (*WavSink, error) {
+	s := &WavSink{w: w, ws: NewWriterSink(w), rate: rate}
+	if err := s.header(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// CreateWavFile creates a WAV file to render audio offline.
+func CreateWavFile(name string, rate int32) (*WavSink, error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	s, err := NewWavSink(f, rate)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *WavSink) Write(frames []int16) error {
+	if err := s.ws.Write(frames); err != nil {
+		return err
+	}
+	s.size += uint32(len(frames) * 2)
+	return nil
+}
+
+// Close writes the size of data and closes the file.
+func (s *WavSink) Close() error {
+	if _, err := s.w.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := s.header(); err != nil {
+		return err
+	}
+	if _, err := s.w.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+	return s.ws.Close()
+}
+
+// 44 bytes canonical header of 16-bit stereo pcm
+func (s *WavSink) header() error {
+	const channels, depth = 2, 16
+	h := struct {
+		Riff     [4]byte
+		RiffSize uint32
+		Wave     [4]byte
+		Fmt      [4]byte
+		FmtSize  uint32
+		Format   uint16
+		Channels uint16
+		Rate     uint32
+		ByteRate uint32
+		Align    uint16
+		Depth    uint16
+		Data     [4]byte
+		DataSize uint32
+	}{
+		[4]byte{'R', 'I', 'F', 'F'}, 36 + s.size, [4]byte{'W', 'A', 'V', 'E'},
+		[4]byte{'f', 'm', 't', ' '}, 16, 1, channels, uint32(s.rate),
+		uint32(s.rate) * channels * depth / 8, channels * depth / 8, depth,
+		[4]byte{'d', 'a', 't', 'a'}, s.size,
+	}
+	return binary.Write(s.w, binary.LittleEndian, &h)
+}
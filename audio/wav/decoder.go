@@ -131,6 +131,7 @@ type Decoder struct {
 	size     int32
 	offset   int32
 	reachEnd bool
+	length   int64
 
 	file res.File
 	name string
@@ -157,12 +158,16 @@ func (*Decoder) FullDecode(file res.File) (data []byte, numChan, bitDepth, freq
 	return
 }
 
-// streamed from disc
+// streamed from disc, returns the number of samples
 func (d *Decoder) Decode() (decoded int) {
 	n, err := io.ReadFull(d.file, d.buffer)
-	if err == io.EOF {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
 		d.reachEnd = true
 	}
+	d.size = int32(n)
+	if bs := int(d.bitDepth / 8); bs > 0 {
+		return n / bs
+	}
 	return n
 }
 
@@ -175,6 +180,10 @@ func (d *Decoder) head() error {
 	if err != nil {
 		return err
 	}
+	return d.open(file)
+}
+
+func (d *Decoder) open(file res.File) error {
 	d.file = file
 	h, err := decode(file)
 	if err != nil {
@@ -183,7 +192,11 @@ func (d *Decoder) head() error {
 	d.numChannels = int32(h.NumChannels)
 	d.sampleRate = int32(h.SampleRate)
 	d.bitDepth = int32(h.BitsPerSample)
+	if h.BlockAlign > 0 {
+		d.length = int64(h.Subchunk2Size) / int64(h.BlockAlign)
+	}
 	d.buffer = make([]byte, 16384)
+	d.size = 0
 	d.reachEnd = false
 	return nil
 }
@@ -201,13 +214,18 @@ func (d *Decoder) SampleRate() int32 {
 }
 
 func (d *Decoder) Buffer() []byte {
-	return d.buffer
+	return d.buffer[:d.size]
 }
 
 func (d *Decoder) ReachEnd() bool {
 	return d.reachEnd
 }
 
+// Length returns the number of sample frames.
+func (d *Decoder) Length() int64 {
+	return d.length
+}
+
 func (d *Decoder) Rewind() {
 	d.head()
 }
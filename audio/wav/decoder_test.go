@@ -0,0 +1,80 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writes a 16-bit stereo wav file
+func writeWav(t *testing.T, name string, samples []int16) {
+	var b bytes.Buffer
+	size := uint32(len(samples) * 2)
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, 36+size)
+	b.WriteString("WAVEfmt ")
+	for _, v := range []interface{}{
+		uint32(16), uint16(wave_FORMAT_PCM), uint16(2), uint32(22050), uint32(22050 * 4), uint16(4), uint16(16),
+	} {
+		binary.Write(&b, binary.LittleEndian, v)
+	}
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, size)
+	binary.Write(&b, binary.LittleEndian, samples)
+	if err := ioutil.WriteFile(name, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "a.wav")
+	samples := make([]int16, 10000)
+	for i := range samples {
+		samples[i] = int16(i)
+	}
+	writeWav(t, name, samples)
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, numChan, depth, freq, err := (&Decoder{}).FullDecode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numChan != 2 || depth != 16 || freq != 22050 || len(data) != 20000 {
+		t.Fatalf("full decode: chan %d depth %d freq %d size %d", numChan, depth, freq, len(data))
+	}
+
+	// streaming, Decode returns the number of samples
+	if f, err = os.Open(name); err != nil {
+		t.Fatal(err)
+	}
+	d := &Decoder{}
+	if err := d.open(f); err != nil {
+		t.Fatal(err)
+	}
+	if n := d.Length(); n != 5000 {
+		t.Errorf("length: %d", n)
+	}
+	var out []byte
+	for !d.ReachEnd() {
+		n := d.Decode()
+		if len(d.Buffer()) != n*2 {
+			t.Fatalf("buffer size: %d, decoded %d", len(d.Buffer()), n)
+		}
+		out = append(out, d.Buffer()...)
+	}
+	if len(out) != 20000 || binary.LittleEndian.Uint16(out[19998:]) != 9999 {
+		t.Errorf("stream decode: got %d bytes", len(out))
+	}
+	d.file.Close()
+}
@@ -21,6 +21,9 @@ type ParticleComp struct {
 
 	tex gfx.Tex2D
 	size f32.Vec2
+
+	// name of the config file, see asset.PSConfig
+	config string
 }
 
 func (pc *ParticleComp) SetSimulator(sim Simulator) {
@@ -87,6 +90,20 @@ func (pc *ParticleComp) SetSize(w, h float32) {
 	pc.size[0], pc.size[1] = w, h
 }
 
+func (pc *ParticleComp) Size() (w, h float32) {
+	return pc.size[0], pc.size[1]
+}
+
+// SetConfig records the config name which the simulator is created
+// from, it's saved with the scene, see game.SaveScene.
+func (pc *ParticleComp) SetConfig(name string) {
+	pc.config = name
+}
+
+func (pc *ParticleComp) Config() string {
+	return pc.config
+}
+
 // component manager
 type ParticleSystemTable struct {
 	comps []ParticleComp
@@ -120,7 +137,7 @@ func (et *ParticleSystemTable) NewComp(entity engi.Entity) (ec *ParticleComp) {
 
 func (et *ParticleSystemTable) Alive(entity engi.Entity) bool {
 	if v, ok := et._map[entity.Index()]; ok {
-		return et.comps[v].Entity != 0
+		return et.comps[v].Entity == entity
 	}
 	return false
 }
@@ -155,6 +172,11 @@ func (et *ParticleSystemTable) Size() (size, cap int) {
 	return et.index, et.cap
 }
 
+// EntityAt returns the entity of the i-th comp, i in [0, size)
+func (et *ParticleSystemTable) EntityAt(i int) engi.Entity {
+	return et.comps[i].Entity
+}
+
 func effectCompResize(slice []ParticleComp, size int) []ParticleComp {
 	newSlice := make([]ParticleComp, size)
 	copy(newSlice, slice)
@@ -4,10 +4,31 @@ package engi
 type Component interface {
 }
 
-// System
+// System, RequireTable is called with all the tables when the system
+// is registered, Update is called each frame.
 type System interface {
+	RequireTable(tables []interface{})
+	Update(dt float32)
 }
 
-// Component Table
+// Component Table, Delete is called when the entity is destroyed,
+// the table should remove the component of the entity(if any).
 type CompTable interface {
+	Delete(entity Entity)
+}
+
+// Table is the contract of component table which can be registered to
+// game.DB. A table should also have a `Comp(entity) *XxxComp` method,
+// it's not in the interface since the type of comp is different.
+type Table interface {
+	CompTable
+	Alive(entity Entity) bool
+	Size() (size, cap int)
+}
+
+// Iterable table can be used to drive a query(see game.Query), comps
+// are stored continuously, EntityAt returns the entity of the i-th comp.
+type Iterable interface {
+	Table
+	EntityAt(i int) Entity
 }
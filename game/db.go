@@ -0,0 +1,179 @@
+package game
+
+import (
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx"
+
+	"log"
+	"reflect"
+	"sort"
+)
+
+// 内置的 Table 和用户自定义的 Table 都注册在 DB 中，各个 System 通过
+// RequireTable 获取自己需要的 Table. 用户代码可以通过 LookupTable 按
+// 类型查找 Table.
+
+// RegisterTable adds a component table to DB. Only one table can be
+// registered for each type. Table should be registered before the
+// systems which require it.
+func (db *DB) RegisterTable(table engi.Table) bool {
+	t := reflect.TypeOf(table)
+	for _, v := range db.Tables {
+		if reflect.TypeOf(v) == t {
+			log.Printf("Table already registered: %v", t)
+			return false
+		}
+	}
+	db.Tables = append(db.Tables, table)
+	return true
+}
+
+// LookupTable finds the table which has the same type as the pointer
+// points to, and sets the pointer. It returns false if not found:
+//
+//	var st *gfx.SpriteTable
+//	db.LookupTable(&st)
+func (db *DB) LookupTable(ptr interface{}) bool {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		log.Printf("LookupTable need a non-nil pointer, got: %v", v.Type())
+		return false
+	}
+	elem := v.Elem()
+	for _, t := range db.Tables {
+		if tv := reflect.ValueOf(t); tv.Type() == elem.Type() {
+			elem.Set(tv)
+			return true
+		}
+	}
+	return false
+}
+
+// update order of built-in systems, a custom system is updated
+// right before the first built-in system which has a bigger order.
+const (
+	OrderScript     = 100
+	OrderAnimation  = 200
+	OrderSimulation = 300
+	OrderRender     = 400
+)
+
+type systemEntry struct {
+	engi.System
+	order int
+}
+
+// RegisterSystem adds a custom system to DB, RequireTable is called
+// immediately with all the registered tables, and Update is called
+// each frame in the order, see OrderScript...
+func (db *DB) RegisterSystem(s engi.System, order int) {
+	s.RequireTable(db.Tables)
+	db.systems = append(db.systems, systemEntry{s, order})
+	sort.SliceStable(db.systems, func(i, j int) bool {
+		return db.systems[i].order < db.systems[j].order
+	})
+}
+
+// UnregisterSystem removes the custom system.
+func (db *DB) UnregisterSystem(s engi.System) {
+	for i, v := range db.systems {
+		if v.System == s {
+			db.systems = append(db.systems[:i], db.systems[i+1:]...)
+			return
+		}
+	}
+}
+
+// update custom systems with order in [begin, end)
+func (db *DB) update(dt float32, begin, end int) {
+	for _, s := range db.systems {
+		if s.order >= begin && s.order < end {
+			s.Update(dt)
+		}
+	}
+}
+
+// 销毁 Entity 时，需要删除它在所有 Table 中的组件，Table 通过实现
+// engi.CompTable 接口来接收删除通知。
+//
+// 在游戏循环中直接销毁 Entity 是危险的（比如在 Script.Update 中销毁了其它
+// 对象，此时各个系统可能正在遍历组件），所以 Destroy 只是把 Entity 放入销毁
+// 队列，在 Game.Update 的最后统一处理。
+
+type destroyRequest struct {
+	engi.Entity
+	children bool
+}
+
+// Destroy put the entity to the destroy queue, the entity and all
+// its components will be destroyed at the end of the frame. If
+// children is true, all the children linked with Transform.LinkChild
+// are destroyed too.
+func (db *DB) Destroy(entity engi.Entity, children bool) {
+	db.destroyQueue = append(db.destroyQueue, destroyRequest{entity, children})
+}
+
+// DestroyNow destroys the entity immediately, it's not safe to call
+// it when systems are updating.
+func (db *DB) DestroyNow(entity engi.Entity, children bool) {
+	list := []engi.Entity{entity}
+	if children {
+		list = db.children(list, entity)
+	}
+	for _, e := range list {
+		db.destroy(e)
+	}
+}
+
+// flush the destroy queue
+func (db *DB) flush() {
+	// destroy may push new request(Script.Destroy)
+	for len(db.destroyQueue) > 0 {
+		queue := db.destroyQueue
+		db.destroyQueue = nil
+		for _, r := range queue {
+			db.DestroyNow(r.Entity, r.children)
+		}
+	}
+}
+
+func (db *DB) destroy(entity engi.Entity) {
+	if em := db.EntityM; em != nil && !em.Alive(entity) {
+		return
+	}
+	for _, t := range db.Tables {
+		switch table := t.(type) {
+		case *ScriptTable:
+			if sc := table.Comp(entity); sc != nil && sc.Script != nil {
+				sc.Script.Destroy()
+			}
+			table.Delete(entity)
+		case engi.CompTable:
+			table.Delete(entity)
+		}
+	}
+	if em := db.EntityM; em != nil {
+		em.Destroy(entity)
+	}
+}
+
+// append all the descendants of the entity to the list
+func (db *DB) children(list []engi.Entity, entity engi.Entity) []engi.Entity {
+	var xt *gfx.TransformTable
+	for _, t := range db.Tables {
+		if table, ok := t.(*gfx.TransformTable); ok {
+			xt = table
+			break
+		}
+	}
+	if xt == nil {
+		return list
+	}
+	if xf := xt.Comp(entity); xf != nil {
+		for c := xf.FirstChild(); c != nil; _, c = c.Sibling() {
+			list = append(list, c.Entity)
+			list = db.children(list, c.Entity)
+		}
+	}
+	return list
+}
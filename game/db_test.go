@@ -0,0 +1,155 @@
+package game
+
+import (
+	"testing"
+
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx"
+)
+
+type countScript struct {
+	destroyed int
+}
+
+func (s *countScript) Init()             {}
+func (s *countScript) Update(dt float32) {}
+func (s *countScript) Destroy()          { s.destroyed++ }
+
+func TestDBDestroy(t *testing.T) {
+	g := &Game{}
+	g.loadTables()
+
+	var (
+		st *gfx.SpriteTable
+		xt *gfx.TransformTable
+		sc *ScriptTable
+	)
+	for _, table := range g.DB.Tables {
+		switch table := table.(type) {
+		case *gfx.SpriteTable:
+			st = table
+		case *gfx.TransformTable:
+			xt = table
+		case *ScriptTable:
+			sc = table
+		}
+	}
+
+	em := g.DB.EntityM
+	// car -> wheel1, wheel2 -> nut
+	car, wheel1, wheel2, nut, other := em.New(), em.New(), em.New(), em.New(), em.New()
+	for _, e := range []engi.Entity{car, wheel1, wheel2, nut, other} {
+		st.NewComp(e)
+		xt.NewComp(e)
+	}
+	xt.Comp(car).LinkChildren(xt.Comp(wheel1), xt.Comp(wheel2))
+	xt.Comp(wheel2).LinkChild(xt.Comp(nut))
+
+	script := &countScript{}
+	sc.NewComp(wheel2, script)
+
+	g.DB.Destroy(car, true)
+	if st.Comp(car) == nil || !em.Alive(car) {
+		t.Fatal("destroy should be deferred")
+	}
+
+	g.DB.flush()
+	for _, e := range []engi.Entity{car, wheel1, wheel2, nut} {
+		if em.Alive(e) || st.Comp(e) != nil || xt.Comp(e) != nil {
+			t.Error("fail to destroy entity:", e)
+		}
+	}
+	if sc.Comp(wheel2) != nil || script.destroyed != 1 {
+		t.Error("fail to destroy script")
+	}
+	if !em.Alive(other) || st.Comp(other) == nil || xt.Comp(other) == nil {
+		t.Error("destroy wrong entity")
+	}
+	if n, _ := st.Size(); n != 1 {
+		t.Error("sprite table size, expected 1, got:", n)
+	}
+
+	// destroy twice is safe
+	g.DB.Destroy(car, true)
+	g.DB.flush()
+	if !em.Alive(other) {
+		t.Error("destroy a dead entity twice")
+	}
+}
+
+type healthTable struct {
+	hp map[engi.Entity]int
+}
+
+func (t *healthTable) Alive(entity engi.Entity) bool { _, ok := t.hp[entity]; return ok }
+func (t *healthTable) Delete(entity engi.Entity)     { delete(t.hp, entity) }
+func (t *healthTable) Size() (size, cap int)         { return len(t.hp), 0 }
+
+type orderSystem struct {
+	name  string
+	log   *[]string
+	table *healthTable
+}
+
+func (s *orderSystem) RequireTable(tables []interface{}) {
+	for _, t := range tables {
+		if table, ok := t.(*healthTable); ok {
+			s.table = table
+		}
+	}
+}
+
+func (s *orderSystem) Update(dt float32) { *s.log = append(*s.log, s.name) }
+
+func TestDBRegistry(t *testing.T) {
+	g := &Game{}
+	g.loadTables()
+
+	ht := &healthTable{hp: make(map[engi.Entity]int)}
+	if !g.DB.RegisterTable(ht) {
+		t.Fatal("fail to register table")
+	}
+	if g.DB.RegisterTable(&healthTable{}) {
+		t.Error("table of the same type should not be registered twice")
+	}
+
+	var found *healthTable
+	if !g.DB.LookupTable(&found) || found != ht {
+		t.Error("fail to lookup custom table")
+	}
+	var st *gfx.SpriteTable
+	if !g.DB.LookupTable(&st) || st == nil {
+		t.Error("fail to lookup built-in table")
+	}
+
+	// custom table is cleaned when entity destroyed
+	e := g.DB.EntityM.New()
+	ht.hp[e] = 100
+	g.DB.DestroyNow(e, false)
+	if ht.Alive(e) {
+		t.Error("fail to delete comp in custom table")
+	}
+
+	var log []string
+	late := &orderSystem{name: "late", log: &log}
+	early := &orderSystem{name: "early", log: &log}
+	g.DB.RegisterSystem(late, OrderRender+1)
+	g.DB.RegisterSystem(early, OrderScript-1)
+	if late.table != ht {
+		t.Error("RequireTable should be called when registered")
+	}
+
+	g.DB.update(0, OrderScript, OrderRender)
+	if len(log) != 0 {
+		t.Error("no system should be updated, got:", log)
+	}
+	g.DB.update(0, -1<<31, 1<<31-1)
+	if len(log) != 2 || log[0] != "early" || log[1] != "late" {
+		t.Error("wrong update order:", log)
+	}
+
+	g.DB.UnregisterSystem(early)
+	if len(g.DB.systems) != 1 {
+		t.Error("fail to unregister system")
+	}
+}
@@ -6,13 +6,16 @@ import (
 	"korok.io/korok/effect"
 	"korok.io/korok/anim"
 	"korok.io/korok/anim/frame"
+	"korok.io/korok/anim/spine"
 	"korok.io/korok/asset"
 	"korok.io/korok/hid/input"
 	"korok.io/korok/gfx/dbg"
 	"korok.io/korok/gui"
 	"korok.io/korok/audio"
+	"korok.io/korok/physics"
 
 	"log"
+	"math"
 	"reflect"
 	"time"
 )
@@ -26,6 +29,12 @@ const (
 	MaxMeshSize = 64 << 10
 
 	MaxParticleSize = 1024
+
+	MaxSkeletonSize = 1024
+
+	MaxBodySize = 4 << 10
+
+	MaxSourceSize = 1 << 10
 )
 
 
@@ -38,6 +47,15 @@ type Table interface{}
 type DB struct {
 	EntityM *engi.EntityManager
 	Tables  []interface{}
+
+	// custom systems, sorted by update order
+	systems []systemEntry
+
+	// entity to destroy at the end of frame
+	destroyQueue []destroyRequest
+
+	// parsed prefab, see Instantiate
+	prefabs map[string]prefabCache
 }
 
 type appState struct {
@@ -90,6 +108,9 @@ type Game struct {
 	*effect.ParticleSimulateSystem
 	*ScriptSystem
 	*anim.AnimationSystem
+	*physics.PhysicsSystem
+	*TriggerSystem
+	*audio.SourceSystem
 
 	// game state
 	appState
@@ -129,11 +150,26 @@ func (g *Game) OnFocusChanged(focused bool) {
 }
 
 /// input callback
+// input is blocked during scene transition, releases are always sent so
+// that no key is stuck.
 func (g *Game) OnKeyEvent(key int, pressed bool) {
+	if pressed && g.SceneManager.Transitioning() {
+		return
+	}
 	g.InputSystem.SetKeyEvent(key, pressed)
 }
 
+func (g *Game) OnCharEvent(char rune) {
+	if g.SceneManager.Transitioning() {
+		return
+	}
+	g.InputSystem.SetCharEvent(char)
+}
+
 func (g *Game) OnPointEvent(key int, pressed bool, x, y float32) {
+	if pressed && g.SceneManager.Transitioning() {
+		return
+	}
 	g.InputSystem.SetPointerEvent(key, pressed, x, y)
 }
 
@@ -161,6 +197,9 @@ func (g *Game) Create(w, h float32, ratio float32) {
 	rs := gfx.NewRenderSystem()
 	g.RenderSystem = rs
 
+	// registered entities are unregistered when destroyed
+	g.DB.Tables = append(g.DB.Tables, rs.V)
+
 	// init game window size
 	g.setGameSize(w, h); g.MainCamera.MoveTo(w/2, h/2)
 
@@ -184,6 +223,8 @@ func (g *Game) Create(w, h float32, ratio float32) {
 	mrf.Register(rs)
 	trf := &gfx.TextRenderFeature{}
 	trf.Register(rs)
+	skf := &spine.SkeletonRenderFeature{}
+	skf.Register(rs)
 
 	// gui system
 	ui := &gui.UIRenderFeature{}
@@ -212,7 +253,20 @@ func (g *Game) Create(w, h float32, ratio float32) {
 	g.AnimationSystem.RequireTable(g.DB.Tables)
 	anim.SetDefaultAnimationSystem(g.AnimationSystem)
 
-	// audio system
+	/// physics system, contacts are sent to scripts
+	g.PhysicsSystem = physics.NewPhysicsSystem()
+	g.PhysicsSystem.RequireTable(g.DB.Tables)
+	g.PhysicsSystem.AddContactListener(&scriptContact{g.ScriptSystem.ScriptTable})
+	g.AnimationSystem.AddFrameEventListener(&scriptFrameEvent{g.ScriptSystem.ScriptTable})
+
+	/// trigger system
+	g.TriggerSystem = NewTriggerSystem()
+	g.TriggerSystem.RequireTable(g.DB.Tables)
+
+	/// positional audio, the camera is the default listener
+	g.SourceSystem = audio.NewSourceSystem()
+	g.SourceSystem.RequireTable(g.DB.Tables)
+	g.SourceSystem.SetCamera(g.Camera())
 
 	/// setup scene manager
 	g.SceneManager.Setup(g)
@@ -262,23 +316,25 @@ func (g *Game) loadTables() {
 	g.DB.EntityM = engi.NewEntityManager()
 
 	// init tables
-	scriptTable := NewScriptTable(MaxScriptSize)
-	tagTable := &TagTable{}
+	g.DB.RegisterTable(NewScriptTable(MaxScriptSize))
+	g.DB.RegisterTable(NewTagTable(MaxScriptSize))
 
-	g.DB.Tables = append(g.DB.Tables, scriptTable, tagTable)
+	g.DB.RegisterTable(gfx.NewSpriteTable(MaxSpriteSize))
+	g.DB.RegisterTable(gfx.NewMeshTable(MaxMeshSize))
+	g.DB.RegisterTable(gfx.NewTransformTable(MaxTransformSize))
+	g.DB.RegisterTable(gfx.NewTextTable(MaxTextSize))
 
-	spriteTable := gfx.NewSpriteTable(MaxSpriteSize)
-	meshTable := gfx.NewMeshTable(MaxMeshSize)
-	xfTable := gfx.NewTransformTable(MaxTransformSize)
-	textTable := gfx.NewTextTable(MaxTextSize)
+	g.DB.RegisterTable(effect.NewParticleSystemTable(MaxParticleSize))
 
-	g.DB.Tables = append(g.DB.Tables, spriteTable, meshTable, xfTable, textTable)
+	g.DB.RegisterTable(frame.NewFlipbookTable(MaxSpriteSize))
+	g.DB.RegisterTable(frame.NewAnimatorTable(MaxSpriteSize))
+	g.DB.RegisterTable(spine.NewSkeletonTable(MaxSkeletonSize))
 
-	psTable := effect.NewParticleSystemTable(MaxParticleSize)
-	g.DB.Tables = append(g.DB.Tables, psTable)
+	g.DB.RegisterTable(physics.NewRigidBodyTable(MaxBodySize))
+	g.DB.RegisterTable(physics.NewColliderTable(MaxBodySize))
+	g.DB.RegisterTable(NewTriggerTable(MaxBodySize))
 
-	spriteAnimTable := frame.NewFlipbookTable(MaxSpriteSize)
-	g.DB.Tables = append(g.DB.Tables, spriteAnimTable)
+	g.DB.RegisterTable(audio.NewSourceTable(MaxSourceSize))
 }
 
 func (g *Game) Input(dt float32) {
@@ -297,11 +353,16 @@ func (g *Game) Update() {
 	// update input-system
 	g.InputSystem.AdvanceFrame()
 
+	// frame time for gui animation
+	gui.SetDeltaTime(dt)
+
 	// update scene
 	g.SceneManager.Update(dt)
 
 	// update script
+	g.DB.update(dt, math.MinInt32, OrderScript)
 	g.ScriptSystem.Update(dt)
+	g.DB.update(dt, OrderScript, OrderAnimation)
 
 	g.InputSystem.Reset()
 
@@ -309,27 +370,35 @@ func (g *Game) Update() {
 
 	// update sprite animation
 	g.AnimationSystem.Update(dt)
+	g.DB.update(dt, OrderAnimation, OrderSimulation)
 
 	/// 动画更新，骨骼数据
 	///g.AnimationSystem.Update(dt)
 
-	// g.CollisionSystem.Update(dt)
+	g.PhysicsSystem.Update(dt)
+	g.TriggerSystem.Update(dt)
+	g.SourceSystem.Update(dt)
 
 	// 粒子系统更新
 	g.ParticleSimulateSystem.Update(dt)
+	g.DB.update(dt, OrderSimulation, OrderRender)
 
 	// Render
 	g.RenderSystem.Update(dt)
+	g.DB.update(dt, OrderRender, math.MaxInt32)
 
 	// fps & profile
 	g.DrawProfile()
 
 	//bk.Dump()
-	audio.AdvanceFrame()
+	audio.Update(dt)
 
 	// flush drawCall
 	num := gfx.Flush()
 
+	// destroy entities queued in this frame
+	g.DB.flush()
+
 	// drawCall = all-drawCall - camera-drawCall
 	dc := num - len(g.RenderSystem.RenderList)
 	dbg.LogFPS(int(g.fps), dc)
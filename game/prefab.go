@@ -0,0 +1,110 @@
+package game
+
+import (
+	"korok.io/korok/asset"
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx"
+	"korok.io/korok/math/f32"
+
+	"bytes"
+	"log"
+)
+
+// 预制体是一个 Entity 模板, 描述了一棵 Entity 树和它们的组件数据, 文件格式
+// 和场景文件相同(见 SaveScene), 第一个 Entity 是根节点. 预制体文件通过
+// asset.Prefab 加载:
+//
+//	asset.Prefab.Load("prefab/enemy.json")
+//	enemy := db.Instantiate("prefab/enemy.json", f32.Vec2{100, 100})
+//
+// 实例化时可以通过 Override 修改某个节点, 节点用 TagComp.Name 标识.
+
+// Override is applied to the node after the instance is created, Node
+// is the Tag name of the entity in the prefab, empty means the root.
+type Override struct {
+	Node  string
+	Apply func(entity engi.Entity)
+}
+
+// the prefab is parsed once, and parsed again if the content is changed,
+// data is a copy of the prefab file
+type prefabCache struct {
+	data []byte
+	sf   *sceneFile
+}
+
+// Instantiate creates a new instance of the prefab, the first root entity
+// is moved to the position, other roots keep their offset to it. It returns
+// the first root entity, or engi.Ghost if the prefab is not loaded.
+func (db *DB) Instantiate(name string, position f32.Vec2, overrides ...Override) engi.Entity {
+	sf := db.prefab(name)
+	if sf == nil || len(sf.Entities) == 0 {
+		return engi.Ghost
+	}
+	list, err := db.loadScene(sf)
+	if err != nil {
+		log.Println("Instantiate:", err)
+		return engi.Ghost
+	}
+
+	// move roots, children follow their parent
+	var xt *gfx.TransformTable
+	if db.LookupTable(&xt) {
+		var (
+			d     f32.Vec2
+			first = true
+		)
+		for i, se := range sf.Entities {
+			if se.Transform == nil || se.Parent != 0 {
+				continue
+			}
+			xf := xt.Comp(list[i])
+			if first {
+				d, first = position.Sub(xf.Position()), false
+			}
+			xf.MoveBy(d[0], d[1])
+		}
+	}
+
+	for _, o := range overrides {
+		if o.Apply == nil {
+			continue
+		}
+		if o.Node == "" {
+			o.Apply(list[0])
+			continue
+		}
+		found := false
+		for i, se := range sf.Entities {
+			if se.Tag != nil && se.Tag.Name == o.Node {
+				o.Apply(list[i])
+				found = true
+			}
+		}
+		if !found {
+			log.Printf("Instantiate: node %q not found in prefab %q", o.Node, name)
+		}
+	}
+	return list[0]
+}
+
+func (db *DB) prefab(name string) *sceneFile {
+	data, ok := asset.Prefab.Get(name)
+	if !ok {
+		log.Println("Instantiate: prefab not loaded:", name)
+		return nil
+	}
+	if c, ok := db.prefabs[name]; ok && bytes.Equal(c.data, data) {
+		return c.sf
+	}
+	sf, err := decodeScene(bytes.NewReader(data))
+	if err != nil {
+		log.Printf("Instantiate: fail to parse prefab %q: %v", name, err)
+		return nil
+	}
+	if db.prefabs == nil {
+		db.prefabs = make(map[string]prefabCache)
+	}
+	db.prefabs[name] = prefabCache{append([]byte(nil), data...), sf}
+	return sf
+}
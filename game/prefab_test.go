@@ -0,0 +1,79 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"korok.io/korok/asset"
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx"
+	"korok.io/korok/math/f32"
+)
+
+const enemyPrefab = `{
+  "version": 1,
+  "entities": [
+    {"id": 1, "transform": {"position": [10, 0], "scale": [1, 1]}, "sprite": {"size": [32, 32], "gravity": [0.5, 0.5], "color": 4294967295, "visible": true}, "tag": {"name": "enemy"}},
+    {"id": 2, "parent": 1, "transform": {"position": [16, 0], "scale": [1, 1]}, "tag": {"name": "gun"}}
+  ]
+}`
+
+func TestInstantiate(t *testing.T) {
+	g := &Game{}
+	g.loadTables()
+	db := &g.DB
+
+	asset.Prefab.Add("test/enemy", []byte(enemyPrefab))
+	defer asset.Prefab.Unload("test/enemy")
+
+	var (
+		xt *gfx.TransformTable
+		st *gfx.SpriteTable
+	)
+	db.LookupTable(&xt)
+	db.LookupTable(&st)
+
+	var gun engi.Entity
+	e1 := db.Instantiate("test/enemy", f32.Vec2{100, 100}, Override{"gun", func(e engi.Entity) {
+		gun = e
+	}})
+	e2 := db.Instantiate("test/enemy", f32.Vec2{200, 0}, Override{"", func(e engi.Entity) {
+		st.Comp(e).SetSize(64, 64)
+	}})
+	if e1 == engi.Ghost || e2 == engi.Ghost || e1 == e2 {
+		t.Fatal("fail to instantiate prefab")
+	}
+
+	if p := xt.Comp(e1).World().Position; p != (f32.Vec2{100, 100}) {
+		t.Error("root position, got:", p)
+	}
+	if xf := xt.Comp(gun); xf == nil || xf.Parent() != xt.Comp(e1) || xf.World().Position != (f32.Vec2{116, 100}) {
+		t.Error("wrong child of instance")
+	}
+	if w, _ := st.Comp(e1).Size(); w != 32 {
+		t.Error("override should not change other instance, got:", w)
+	}
+	if w, _ := st.Comp(e2).Size(); w != 64 {
+		t.Error("fail to override root, got:", w)
+	}
+	if n, _ := xt.Size(); n != 4 {
+		t.Error("expected 4 transforms, got:", n)
+	}
+
+	if db.Instantiate("test/none", f32.Vec2{}) != engi.Ghost {
+		t.Error("prefab not loaded should return Ghost")
+	}
+
+	// parsed once, and parsed again if the content is changed
+	sf := db.prefab("test/enemy")
+	asset.Prefab.Add("test/enemy", []byte(enemyPrefab))
+	defer asset.Prefab.Unload("test/enemy")
+	if db.prefab("test/enemy") != sf {
+		t.Error("prefab of same content should not be parsed again")
+	}
+	asset.Prefab.Add("test/enemy", []byte(strings.Replace(enemyPrefab, `"gun"`, `"sword"`, 1)))
+	defer asset.Prefab.Unload("test/enemy")
+	if n := db.prefab("test/enemy"); n == sf || n.Entities[1].Tag.Name != "sword" {
+		t.Error("changed prefab should be parsed again")
+	}
+}
@@ -0,0 +1,175 @@
+package game
+
+import (
+	"korok.io/korok/engi"
+
+	"log"
+	"reflect"
+)
+
+// Query 遍历同时拥有多个组件的 Entity. 查询从最小的 Table 开始遍历, 其它
+// Table 只做 Alive 测试, 所以复杂度只和最小的 Table 有关:
+//
+//	var (
+//		xf *gfx.Transform
+//		sprite *gfx.SpriteComp
+//	)
+//	q := db.Query(&xf, &sprite)
+//	for q.Next() {
+//		xf.MoveBy(1, 0)
+//	}
+//
+// 遍历过程中不要直接删除组件或销毁 Entity, 请使用 DB.Destroy.
+type Query struct {
+	tables []engi.Table
+	filter []func(engi.Entity) bool
+	binds  []queryBind
+
+	driver engi.Iterable
+	i, n   int
+	entity engi.Entity
+}
+
+// bind a typed pointer to the Comp method of the table
+type queryBind struct {
+	comp reflect.Value
+	ptr  reflect.Value
+}
+
+// NewQuery creates a query which iterates the intersection of tables,
+// at least one of the tables should implement engi.Iterable.
+func NewQuery(tables ...engi.Table) *Query {
+	q := &Query{tables: tables}
+	q.Reset()
+	return q
+}
+
+// Query creates a query from typed component pointers, for each pointer
+// the table whose `Comp(entity)` method returns the same type is joined,
+// and the pointer is set to the component of current entity on Next.
+func (db *DB) Query(ptrs ...interface{}) *Query {
+	q := &Query{}
+	for _, ptr := range ptrs {
+		v := reflect.ValueOf(ptr)
+		if v.Kind() != reflect.Ptr || v.IsNil() {
+			log.Printf("Query need a non-nil pointer, got: %v", reflect.TypeOf(ptr))
+			return q
+		}
+		table, comp := db.lookupComp(v.Elem().Type())
+		if table == nil {
+			log.Printf("Query: no table for comp %v", v.Elem().Type())
+			return q
+		}
+		q.tables = append(q.tables, table)
+		q.binds = append(q.binds, queryBind{comp, v.Elem()})
+	}
+	q.Reset()
+	return q
+}
+
+// find the table which has a method `Comp(engi.Entity) t`
+func (db *DB) lookupComp(t reflect.Type) (engi.Table, reflect.Value) {
+	for _, v := range db.Tables {
+		table, ok := v.(engi.Table)
+		if !ok {
+			continue
+		}
+		m := reflect.ValueOf(table).MethodByName("Comp")
+		if !m.IsValid() {
+			continue
+		}
+		if mt := m.Type(); mt.NumIn() == 1 && mt.In(0) == reflect.TypeOf(engi.Entity(0)) &&
+			mt.NumOut() == 1 && mt.Out(0) == t {
+			return table, m
+		}
+	}
+	return nil, reflect.Value{}
+}
+
+// Where adds a filter, only the entity which passes all the filters is
+// returned. For example, find entities by tag:
+//
+//	q.Where(func(e engi.Entity) bool { return tags.Comp(e).Label == "enemy" })
+func (q *Query) Where(fn func(engi.Entity) bool) *Query {
+	q.filter = append(q.filter, fn)
+	return q
+}
+
+// Reset rewinds the query, the smallest table is selected again, so a
+// query can be created once and reused each frame.
+func (q *Query) Reset() {
+	q.driver, q.i, q.n = nil, 0, 0
+	for _, t := range q.tables {
+		it, ok := t.(engi.Iterable)
+		if !ok {
+			continue
+		}
+		if size, _ := it.Size(); q.driver == nil || size < q.n {
+			q.driver, q.n = it, size
+		}
+	}
+	if q.driver == nil && len(q.tables) > 0 {
+		log.Println("Query: no iterable table")
+	}
+}
+
+// Next moves to the next matched entity and updates the bound pointers,
+// it returns false when the iteration is done.
+func (q *Query) Next() bool {
+	if q.driver == nil {
+		return false
+	}
+	// table may grow when iterating, only iterate the old ones
+	if size, _ := q.driver.Size(); size < q.n {
+		q.n = size
+	}
+	for q.i < q.n {
+		e := q.driver.EntityAt(q.i)
+		q.i++
+		if q.match(e) {
+			q.entity = e
+			q.bind(e)
+			return true
+		}
+	}
+	return false
+}
+
+// Entity returns the current entity.
+func (q *Query) Entity() engi.Entity {
+	return q.entity
+}
+
+// Count returns the number of matched entities, the query is rewound.
+func (q *Query) Count() (n int) {
+	q.Reset()
+	for q.Next() {
+		n++
+	}
+	q.Reset()
+	return
+}
+
+func (q *Query) match(e engi.Entity) bool {
+	for _, t := range q.tables {
+		if t != q.driver && !t.Alive(e) {
+			return false
+		}
+	}
+	for _, fn := range q.filter {
+		if !fn(e) {
+			return false
+		}
+	}
+	return true
+}
+
+func (q *Query) bind(e engi.Entity) {
+	if len(q.binds) == 0 {
+		return
+	}
+	in := []reflect.Value{reflect.ValueOf(e)}
+	for _, b := range q.binds {
+		b.ptr.Set(b.comp.Call(in)[0])
+	}
+}
@@ -0,0 +1,79 @@
+package game
+
+import (
+	"testing"
+
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx"
+	"korok.io/korok/math/f32"
+)
+
+func TestQuery(t *testing.T) {
+	g := &Game{}
+	g.loadTables()
+
+	var (
+		st *gfx.SpriteTable
+		xt *gfx.TransformTable
+		tt *TagTable
+	)
+	g.DB.LookupTable(&st)
+	g.DB.LookupTable(&xt)
+	g.DB.LookupTable(&tt)
+
+	// 0..9 has transform, even ones have sprite, 0..4 are enemies
+	em := g.DB.EntityM
+	entities := make([]engi.Entity, 10)
+	for i := range entities {
+		e := em.New()
+		entities[i] = e
+		xt.NewComp(e).SetPosition(f32.Vec2{float32(i), 0})
+		if i%2 == 0 {
+			st.NewComp(e)
+		}
+		if i < 5 {
+			tt.NewComp(e).Label = "enemy"
+		}
+	}
+
+	var (
+		xf     *gfx.Transform
+		sprite *gfx.SpriteComp
+	)
+	q := g.DB.Query(&xf, &sprite)
+	n := 0
+	for q.Next() {
+		e := q.Entity()
+		if xf != xt.Comp(e) || sprite != st.Comp(e) {
+			t.Error("wrong comp bound for entity:", e)
+		}
+		if int(xf.Position()[0])%2 != 0 {
+			t.Error("entity without sprite:", e)
+		}
+		n++
+	}
+	if n != 5 {
+		t.Error("transform x sprite, expected 5, got:", n)
+	}
+
+	q.Where(func(e engi.Entity) bool {
+		tc := tt.Comp(e)
+		return tc != nil && tc.Label == "enemy"
+	})
+	if n := q.Count(); n != 3 {
+		t.Error("enemy sprites, expected 3, got:", n)
+	}
+
+	// dead comp is skipped
+	g.DB.DestroyNow(entities[0], false)
+	if n := NewQuery(xt, st).Count(); n != 4 {
+		t.Error("after destroy, expected 4, got:", n)
+	}
+
+	// entity reuses the index of a dead one
+	e := em.New()
+	xt.NewComp(e)
+	if xt.Alive(entities[0]) || !xt.Alive(e) {
+		t.Error("Alive should check the generation of entity")
+	}
+}
@@ -1,10 +1,27 @@
 package game
 
+import (
+	"korok.io/korok/asset"
+
+	"log"
+	"time"
+)
+
+// LoaderState is the state of async loading, progress is in [0, 100].
 type LoaderState struct {
 	progress int
 	done bool
 }
 
+func (ls LoaderState) Progress() int {
+	return ls.progress
+}
+
+func (ls LoaderState) Done() bool {
+	return ls.done
+}
+
+// AsyncHandler receives a value when the target scene of LoadAsync is entered.
 type AsyncHandler chan bool
 
 // Loader
@@ -12,6 +29,16 @@ type Loader interface {
 	Load()
 }
 
+// AsyncLoader adds the assets of a scene to the batch, the assets are
+// decoded in worker goroutines. If the scene is also a Loader, Load is
+// called after all the assets are uploaded.
+type AsyncLoader interface {
+	LoadAsync(b *asset.Batch)
+}
+
+// DefaultUploadBudget is the time spent to upload assets in each frame.
+const DefaultUploadBudget = 4 * time.Millisecond
+
 type UnLoader interface {
 	Unload()
 }
@@ -32,9 +59,26 @@ type SceneManager struct {
 
 	stack []Scene
 	hScene Scene
+
+	// async loading
+	async *asyncLoading
+	state LoaderState
+
+	// running transition
+	trans *transition
+
+	// UploadBudget is the time spent to upload assets in each frame,
+	// zero means DefaultUploadBudget.
+	UploadBudget time.Duration
 }
 
-// 设计合理的异步加载方案：TODO
+type asyncLoading struct {
+	target, loading Scene
+	batch *asset.Batch
+	handler AsyncHandler
+}
+
+// Load loads the scene in the render thread.
 func (*SceneManager) Load(sn Scene) {
 	if loader, ok := sn.(Loader); ok {
 		loader.Load()
@@ -59,10 +103,159 @@ func (sm *SceneManager) Setup(g *Game) {
 	}
 }
 
+// LoadAsync loads the target scene asynchronously, the loading scene(if
+// any) is pushed and shown during loading, and replaced by the target
+// scene when done. Use State to get the progress in the loading scene.
+// Only one scene can be loaded at a time, it returns nil if the last
+// loading is not finished.
+func (sm *SceneManager) LoadAsync(target, loading Scene) AsyncHandler {
+	if sm.async != nil {
+		log.Println("scene is loading, can't load another scene async")
+		return nil
+	}
+	b := asset.NewBatch()
+	if loader, ok := target.(AsyncLoader); ok {
+		loader.LoadAsync(b)
+	}
+	b.Start(0)
+
+	handler := make(AsyncHandler, 1)
+	sm.async = &asyncLoading{target, loading, b, handler}
+	sm.state = LoaderState{}
+
+	if loading != nil {
+		sm.Load(loading)
+		sm.Push(loading)
+	}
+	return handler
+}
+
+// State returns the state of current async loading.
+func (sm *SceneManager) State() LoaderState {
+	return sm.state
+}
+
+// upload the assets in budget, and switch to the target scene when done.
+func (sm *SceneManager) updateAsync() {
+	l := sm.async
+	budget := sm.UploadBudget
+	if budget == 0 {
+		budget = DefaultUploadBudget
+	}
+	done := l.batch.Upload(budget)
+	sm.state.progress = int(l.batch.Progress() * 100)
+	if !done {
+		return
+	}
+	sm.async = nil
+	sm.Load(l.target)
+
+	// replace the loading scene
+	if size := len(sm.stack); l.loading != nil && size > 0 && sm.stack[size-1] == l.loading {
+		sm.stack = sm.stack[:size-1]
+		l.loading.OnExit()
+		sm.UnLoad(l.loading)
+		sm.hScene = nil
+	}
+	sm.Push(l.target)
+	sm.state.done = true
+	l.handler <- true
+}
+
 func (sm *SceneManager) Update(dt float32) {
+	if sm.async != nil {
+		sm.updateAsync()
+	}
+	if t := sm.trans; t != nil {
+		t.out.Update(dt)
+	}
 	if h := sm.hScene; h != nil {
 		h.Update(dt)
 	}
+	if t := sm.trans; t != nil {
+		t.elapsed += dt
+		if t.Effect != nil {
+			t.Effect.Draw(t.progress())
+		}
+		if t.elapsed >= t.Duration {
+			sm.finishTransition()
+		}
+	}
+}
+
+// Transitioning returns true if a transition is running, input events
+// are blocked during the transition.
+func (sm *SceneManager) Transitioning() bool {
+	return sm.trans != nil
+}
+
+// PushWith pushes the scene with a transition, the current scene exits
+// when the transition is finished.
+func (sm *SceneManager) PushWith(sn Scene, t Transition) {
+	sm.finishTransition()
+	out := sm.hScene
+	if out == nil {
+		sm.Push(sn)
+		if fn := t.OnComplete; fn != nil {
+			fn()
+		}
+		return
+	}
+	sm.hScene = sn
+	sm.stack = append(sm.stack, sn)
+	sn.OnEnter(sm.g)
+
+	sm.beginTransition(t, out, sn, out.OnExit)
+}
+
+// PopWith pops the current scene with a transition, the popped scene
+// exits and is unloaded when the transition is finished.
+func (sm *SceneManager) PopWith(t Transition) (sn Scene, ok bool) {
+	sm.finishTransition()
+	size := len(sm.stack)
+	if size < 2 {
+		sn, ok = sm.Pop()
+		if fn := t.OnComplete; ok && fn != nil {
+			fn()
+		}
+		return
+	}
+	sn, ok = sm.stack[size-1], true
+	sm.stack = sm.stack[:size-1]
+	in := sm.stack[size-2]
+	sm.hScene = in
+	in.OnEnter(sm.g)
+
+	out := sn
+	sm.beginTransition(t, out, in, func() {
+		out.OnExit()
+		sm.UnLoad(out)
+	})
+	return
+}
+
+func (sm *SceneManager) beginTransition(t Transition, out, in Scene, finish func()) {
+	sm.trans = &transition{Transition: t, out: out, in: in, finish: finish}
+	if t.Effect != nil {
+		t.Effect.Begin(sm.g, out, in)
+		t.Effect.Draw(0)
+	}
+}
+
+// finish the running transition immediately
+func (sm *SceneManager) finishTransition() {
+	t := sm.trans
+	if t == nil {
+		return
+	}
+	sm.trans = nil
+	if t.Effect != nil {
+		t.Effect.End()
+	}
+	t.finish()
+	if fn := t.OnComplete; fn != nil {
+		fn()
+	}
 }
 
 // SetDefault sets the default Scene before the Game start.
@@ -73,6 +266,7 @@ func (sm *SceneManager) SetDefault(sn Scene) {
 }
 
 func (sm *SceneManager) Push(sn Scene) {
+	sm.finishTransition()
 	if h := sm.hScene; h != nil {
 		h.OnExit()
 	}
@@ -85,6 +279,7 @@ func (sm *SceneManager) Push(sn Scene) {
 }
 
 func (sm *SceneManager) Pop() (sn Scene, ok bool) {
+	sm.finishTransition()
 	if size := len(sm.stack); size > 0 {
 		sn = sm.stack[size-1]
 		ok = true
@@ -0,0 +1,836 @@
+package game
+
+import (
+	"korok.io/korok/anim/frame"
+	"korok.io/korok/asset"
+	"korok.io/korok/asset/res"
+	"korok.io/korok/effect"
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx"
+	"korok.io/korok/math/f32"
+
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+)
+
+// 场景文件保存 Entity 和它们的组件(Transform, Sprite, Text, Flipbook,
+// Particle, Tag). 组件引用的资源(纹理, 字体, 粒子配置)只保存名字, 加载时
+// 通过 asset 包查找, 所以字体, 图集和粒子配置需要在加载场景之前加载好,
+// 普通纹理如果没有加载会自动加载.
+//
+// 文件有两种格式: JSON 方便编辑和比较, 二进制格式更紧凑, 加载时根据文件头
+// 自动识别.
+
+// SceneVersion is the version of scene file format, files with a newer
+// version can't be loaded.
+const SceneVersion = 1
+
+type SceneFormat uint8
+
+const (
+	SceneJSON SceneFormat = iota
+	SceneBinary
+)
+
+// magic number of the binary format
+const sceneMagic = "KSCN"
+
+type sceneFile struct {
+	Version  int           `json:"version"`
+	Entities []sceneEntity `json:"entities"`
+}
+
+// Id is only used in the file to link the Transform hierarchy, Parent
+// is the Id of parent entity, zero means no parent.
+type sceneEntity struct {
+	Id     uint32 `json:"id"`
+	Parent uint32 `json:"parent,omitempty"`
+
+	Transform *sceneTransform `json:"transform,omitempty"`
+	Sprite    *sceneSprite    `json:"sprite,omitempty"`
+	Text      *sceneText      `json:"text,omitempty"`
+	Flipbook  *sceneFlipbook  `json:"flipbook,omitempty"`
+	Particle  *sceneParticle  `json:"particle,omitempty"`
+	Tag       *sceneTag       `json:"tag,omitempty"`
+}
+
+// reference to a texture or a sub-texture of an atlas
+type sceneTex struct {
+	File  string `json:"file"`
+	Atlas bool   `json:"atlas,omitempty"`
+	Frame string `json:"frame,omitempty"`
+	Index int    `json:"index,omitempty"`
+}
+
+type sceneTransform struct {
+	Position [2]float32 `json:"position"`
+	Rotation float32    `json:"rotation"`
+	Scale    [2]float32 `json:"scale"`
+}
+
+type sceneSprite struct {
+	Texture *sceneTex  `json:"texture,omitempty"`
+	Size    [2]float32 `json:"size"`
+	Gravity [2]float32 `json:"gravity"`
+	Color   uint32     `json:"color"`
+	FlipX   bool       `json:"flipX,omitempty"`
+	FlipY   bool       `json:"flipY,omitempty"`
+	Z       int16      `json:"z"`
+	Visible bool       `json:"visible"`
+}
+
+type sceneText struct {
+	Font     string     `json:"font"`
+	Text     string     `json:"text"`
+	FontSize float32    `json:"fontSize"`
+	Color    uint32     `json:"color"`
+	Gravity  [2]float32 `json:"gravity"`
+	Z        int16      `json:"z"`
+	Visible  bool       `json:"visible"`
+}
+
+type sceneFlipbook struct {
+	Animation string  `json:"animation"`
+	Rate      float32 `json:"rate"`
+	Loop      bool    `json:"loop"`
+	LoopType  uint8   `json:"loopType"`
+	Running   bool    `json:"running"`
+}
+
+type sceneParticle struct {
+	Config  string     `json:"config"`
+	Texture *sceneTex  `json:"texture,omitempty"`
+	Size    [2]float32 `json:"size"`
+	Z       int16      `json:"z"`
+	Visible bool       `json:"visible"`
+}
+
+type sceneTag struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+}
+
+// the tables which are saved in the scene file
+type sceneTables struct {
+	xt *gfx.TransformTable
+	st *gfx.SpriteTable
+	tt *gfx.TextTable
+	ft *frame.FlipbookTable
+	pt *effect.ParticleSystemTable
+	gt *TagTable
+}
+
+func (db *DB) sceneTables() (t sceneTables) {
+	db.LookupTable(&t.xt)
+	db.LookupTable(&t.st)
+	db.LookupTable(&t.tt)
+	db.LookupTable(&t.ft)
+	db.LookupTable(&t.pt)
+	db.LookupTable(&t.gt)
+	return
+}
+
+// SaveScene writes all the entities which have any of the Transform,
+// Sprite, Text, Flipbook, Particle and Tag components.
+func (db *DB) SaveScene(w io.Writer, format SceneFormat) error {
+	sf := db.saveScene()
+	switch format {
+	case SceneJSON:
+		data, err := json.MarshalIndent(sf, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	case SceneBinary:
+		bw := &sceneWriter{w: bufio.NewWriter(w)}
+		sf.encode(bw)
+		if bw.err != nil {
+			return bw.err
+		}
+		return bw.w.Flush()
+	}
+	return fmt.Errorf("unknown scene format: %d", format)
+}
+
+// SaveSceneFile saves the scene to a file.
+func (db *DB) SaveSceneFile(file string, format SceneFormat) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err = db.SaveScene(f, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadScene creates entities and components from the scene data, the
+// format is detected automatically. It returns the created entities in
+// the same order as the file.
+func (db *DB) LoadScene(r io.Reader) (list []engi.Entity, err error) {
+	sf, err := decodeScene(r)
+	if err != nil {
+		return
+	}
+	return db.loadScene(sf)
+}
+
+// LoadSceneFile loads the scene from the asset file system, see res.Open.
+func (db *DB) LoadSceneFile(file string) ([]engi.Entity, error) {
+	f, err := res.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return db.LoadScene(f)
+}
+
+func decodeScene(r io.Reader) (sf *sceneFile, err error) {
+	br := bufio.NewReader(r)
+	sf = &sceneFile{}
+	if magic, _ := br.Peek(len(sceneMagic)); string(magic) == sceneMagic {
+		sr := &sceneReader{r: br}
+		sf.decode(sr)
+		err = sr.err
+	} else {
+		err = json.NewDecoder(br).Decode(sf)
+	}
+	if err == nil && sf.Version > SceneVersion {
+		err = fmt.Errorf("scene version %d is not supported, current: %d", sf.Version, SceneVersion)
+	}
+	return
+}
+
+func (db *DB) saveScene() *sceneFile {
+	t := db.sceneTables()
+	sf := &sceneFile{Version: SceneVersion}
+	ids := make(map[engi.Entity]uint32)
+
+	add := func(e engi.Entity, parent uint32) {
+		if _, ok := ids[e]; ok {
+			return
+		}
+		id := uint32(len(sf.Entities) + 1)
+		ids[e] = id
+		sf.Entities = append(sf.Entities, t.save(e, id, parent))
+	}
+
+	// Transform first, parent is always saved before its children
+	if t.xt != nil {
+		var walk func(xf *gfx.Transform)
+		walk = func(xf *gfx.Transform) {
+			pid := ids[xf.Entity]
+			for c := xf.FirstChild(); c != nil; _, c = c.Sibling() {
+				add(c.Entity, pid)
+				walk(c)
+			}
+		}
+		n, _ := t.xt.Size()
+		for i := 0; i < n; i++ {
+			if xf := t.xt.Comp(t.xt.EntityAt(i)); xf.Parent() == nil {
+				add(xf.Entity, 0)
+				walk(xf)
+			}
+		}
+	}
+
+	var tables []engi.Iterable
+	if t.st != nil {
+		tables = append(tables, t.st)
+	}
+	if t.tt != nil {
+		tables = append(tables, t.tt)
+	}
+	if t.ft != nil {
+		tables = append(tables, t.ft)
+	}
+	if t.pt != nil {
+		tables = append(tables, t.pt)
+	}
+	if t.gt != nil {
+		tables = append(tables, t.gt)
+	}
+	for _, table := range tables {
+		n, _ := table.Size()
+		for i := 0; i < n; i++ {
+			add(table.EntityAt(i), 0)
+		}
+	}
+	return sf
+}
+
+func (t *sceneTables) save(e engi.Entity, id, parent uint32) (se sceneEntity) {
+	se.Id, se.Parent = id, parent
+
+	if t.xt != nil {
+		if xf := t.xt.Comp(e); xf != nil {
+			local := xf.Local()
+			se.Transform = &sceneTransform{local.Position, local.Rotation, local.Scale}
+		}
+	}
+	if t.st != nil {
+		if sc := t.st.Comp(e); sc != nil {
+			ss := &sceneSprite{Color: sc.Color().U32(), Z: sc.Z(), Visible: sc.Visible()}
+			ss.Texture = saveTex(sc.Sprite)
+			ss.Size[0], ss.Size[1] = sc.Size()
+			ss.Gravity[0], ss.Gravity[1] = sc.Gravity()
+			ss.FlipX, ss.FlipY = sc.Flipped()
+			se.Sprite = ss
+		}
+	}
+	if t.tt != nil {
+		if tc := t.tt.Comp(e); tc != nil {
+			st := &sceneText{Text: tc.Text(), FontSize: tc.FontSize(), Color: tc.Color().U32(), Z: tc.Z(), Visible: tc.Visible()}
+			if fnt := tc.Font(); fnt != nil {
+				if name, ok := asset.Font.Name(fnt); ok {
+					st.Font = name
+				} else {
+					log.Println("SaveScene: font not found in asset.Font, entity:", e)
+				}
+			}
+			st.Gravity[0], st.Gravity[1] = tc.Gravity()
+			se.Text = st
+		}
+	}
+	if t.ft != nil {
+		if fb := t.ft.Comp(e); fb != nil {
+			loop, typ := fb.Loop()
+			se.Flipbook = &sceneFlipbook{fb.Animation(), fb.Rate(), loop, uint8(typ), fb.Running()}
+		}
+	}
+	if t.pt != nil {
+		if pc := t.pt.Comp(e); pc != nil {
+			sp := &sceneParticle{Config: pc.Config(), Z: pc.Z(), Visible: pc.Visible()}
+			sp.Texture = saveTex(pc.Texture())
+			sp.Size[0], sp.Size[1] = pc.Size()
+			se.Particle = sp
+		}
+	}
+	if t.gt != nil {
+		if tc := t.gt.Comp(e); tc != nil {
+			se.Tag = &sceneTag{tc.Name, tc.Label}
+		}
+	}
+	return
+}
+
+func (db *DB) loadScene(sf *sceneFile) (list []engi.Entity, err error) {
+	if db.EntityM == nil {
+		return nil, errors.New("LoadScene: no EntityManager")
+	}
+	t := db.sceneTables()
+	list = make([]engi.Entity, len(sf.Entities))
+	ids := make(map[uint32]engi.Entity, len(sf.Entities))
+	for i := range sf.Entities {
+		e := db.EntityM.New()
+		list[i] = e
+		ids[sf.Entities[i].Id] = e
+	}
+
+	// create all the transforms before linking, table may grow
+	if t.xt != nil {
+		for i, se := range sf.Entities {
+			if se.Transform != nil {
+				t.xt.NewComp(list[i])
+			}
+		}
+		for i, se := range sf.Entities {
+			if se.Transform == nil || se.Parent == 0 {
+				continue
+			}
+			if p, ok := ids[se.Parent]; ok && t.xt.Comp(p) != nil {
+				t.xt.Comp(p).LinkChild(t.xt.Comp(list[i]))
+			} else {
+				log.Println("LoadScene: parent not found, id:", se.Parent)
+			}
+		}
+		// world SRT is computed from root to leaves
+		srt := make(map[engi.Entity]*sceneTransform)
+		for i, se := range sf.Entities {
+			if se.Transform != nil {
+				srt[list[i]] = se.Transform
+			}
+		}
+		var apply func(xf *gfx.Transform)
+		apply = func(xf *gfx.Transform) {
+			st := srt[xf.Entity]
+			xf.SetScale(st.Scale)
+			xf.SetRotation(st.Rotation)
+			xf.SetPosition(st.Position)
+			for c := xf.FirstChild(); c != nil; _, c = c.Sibling() {
+				apply(c)
+			}
+		}
+		for e := range srt {
+			if xf := t.xt.Comp(e); xf.Parent() == nil {
+				apply(xf)
+			}
+		}
+	}
+
+	for i, se := range sf.Entities {
+		t.load(list[i], &se)
+	}
+	return
+}
+
+func (t *sceneTables) load(e engi.Entity, se *sceneEntity) {
+	if ss := se.Sprite; ss != nil && t.st != nil {
+		sc := t.st.NewComp(e)
+		sc.SetSize(ss.Size[0], ss.Size[1])
+		if tex, ok := loadTex(ss.Texture); ok {
+			sc.SetSprite(tex)
+		}
+		sc.SetGravity(ss.Gravity[0], ss.Gravity[1])
+		sc.SetColor(gfx.U32Color(ss.Color))
+		sc.Flip(ss.FlipX, ss.FlipY)
+		sc.SetZOrder(ss.Z)
+		sc.SetVisible(ss.Visible)
+	}
+	if st := se.Text; st != nil && t.tt != nil {
+		tc := t.tt.NewComp(e)
+		tc.SetFontSize(st.FontSize)
+		if fnt, ok := asset.Font.Get(st.Font); ok {
+			tc.SetFont(fnt)
+			if st.Text != "" {
+				tc.SetText(st.Text)
+			}
+		} else {
+			log.Println("LoadScene: font not loaded:", st.Font)
+		}
+		tc.SetColor(gfx.U32Color(st.Color))
+		tc.SetGravity(st.Gravity[0], st.Gravity[1])
+		tc.SetZOrder(st.Z)
+		tc.SetVisible(st.Visible)
+	}
+	if sf := se.Flipbook; sf != nil && t.ft != nil {
+		fb := t.ft.NewComp(e)
+		fb.SetRate(sf.Rate)
+		fb.SetLoop(sf.Loop, frame.LoopType(sf.LoopType))
+		if sf.Running {
+			fb.Play(sf.Animation)
+		} else {
+			fb.SetAnimation(sf.Animation)
+		}
+	}
+	if sp := se.Particle; sp != nil && t.pt != nil {
+		pc := t.pt.NewComp(e)
+		pc.SetConfig(sp.Config)
+		if sim := newSimulator(sp.Config); sim != nil {
+			pc.SetSimulator(sim)
+		}
+		if tex, ok := loadTex(sp.Texture); ok {
+			pc.SetTexture(tex)
+		}
+		pc.SetSize(sp.Size[0], sp.Size[1])
+		pc.SetZOrder(sp.Z)
+		pc.SetVisible(sp.Visible)
+	}
+	if st := se.Tag; st != nil && t.gt != nil {
+		tc := t.gt.NewComp(e)
+		tc.Name, tc.Label = st.Name, st.Label
+	}
+}
+
+func saveTex(tex gfx.Tex2D) *sceneTex {
+	if tex == nil {
+		return nil
+	}
+	file, sub, ok := asset.Texture.Name(tex)
+	if !ok {
+		log.Println("SaveScene: texture not found in asset.Texture, id:", tex.Tex())
+		return nil
+	}
+	ref := &sceneTex{File: file}
+	if st, ok := tex.(gfx.SubTex); ok {
+		ref.Atlas = true
+		ref.Frame = sub
+		_, ref.Index = st.Id()
+	}
+	return ref
+}
+
+func loadTex(ref *sceneTex) (tex gfx.Tex2D, ok bool) {
+	if ref == nil {
+		return
+	}
+	if !ref.Atlas {
+		if _, raw := asset.Texture.GetRaw(ref.File); raw == nil {
+			asset.Texture.Load(ref.File)
+		}
+		return asset.Texture.Get(ref.File), true
+	}
+	at, ok := asset.Texture.Atlas(ref.File)
+	if !ok {
+		log.Println("LoadScene: atlas not loaded:", ref.File)
+		return
+	}
+	var st gfx.SubTex
+	if ref.Frame != "" {
+		st, ok = at.GetByName(ref.Frame)
+	} else {
+		st, ok = at.GetByIndex(ref.Index)
+	}
+	if !ok {
+		log.Println("LoadScene: sub-texture not found:", ref.File, ref.Frame, ref.Index)
+		return
+	}
+	return st, true
+}
+
+func newSimulator(config string) effect.Simulator {
+	if config == "" {
+		return nil
+	}
+	cfg, ok := asset.PSConfig.Get(config)
+	if !ok {
+		log.Println("LoadScene: particle config not loaded:", config)
+		return nil
+	}
+	switch cfg := cfg.(type) {
+	case *effect.GravityConfig:
+		return effect.NewGravitySimulator(cfg)
+	case *effect.RadiusConfig:
+		return effect.NewRadiusSimulator(cfg)
+	}
+	return nil
+}
+
+// 二进制格式: magic, version, entity count, 然后是每个 Entity 的数据,
+// 组件是否存在由一个字节的掩码表示. 整数使用 varint, 浮点数使用 float32.
+
+const (
+	maskTransform = 1 << iota
+	maskSprite
+	maskText
+	maskFlipbook
+	maskParticle
+	maskTag
+)
+
+func (sf *sceneFile) encode(w *sceneWriter) {
+	w.bytes([]byte(sceneMagic))
+	w.uvarint(uint64(sf.Version))
+	w.uvarint(uint64(len(sf.Entities)))
+	for i := range sf.Entities {
+		sf.Entities[i].encode(w)
+	}
+}
+
+func (sf *sceneFile) decode(r *sceneReader) {
+	magic := make([]byte, len(sceneMagic))
+	if r.bytes(magic); string(magic) != sceneMagic {
+		r.fail(errors.New("invalid scene file"))
+		return
+	}
+	sf.Version = int(r.uvarint())
+	if sf.Version > SceneVersion {
+		return
+	}
+	n := r.uvarint()
+	for i := uint64(0); i < n && r.err == nil; i++ {
+		se := sceneEntity{}
+		se.decode(r)
+		sf.Entities = append(sf.Entities, se)
+	}
+}
+
+func (se *sceneEntity) encode(w *sceneWriter) {
+	w.uvarint(uint64(se.Id))
+	w.uvarint(uint64(se.Parent))
+
+	var mask uint8
+	if se.Transform != nil {
+		mask |= maskTransform
+	}
+	if se.Sprite != nil {
+		mask |= maskSprite
+	}
+	if se.Text != nil {
+		mask |= maskText
+	}
+	if se.Flipbook != nil {
+		mask |= maskFlipbook
+	}
+	if se.Particle != nil {
+		mask |= maskParticle
+	}
+	if se.Tag != nil {
+		mask |= maskTag
+	}
+	w.u8(mask)
+
+	if c := se.Transform; c != nil {
+		w.vec2(c.Position)
+		w.f32(c.Rotation)
+		w.vec2(c.Scale)
+	}
+	if c := se.Sprite; c != nil {
+		w.tex(c.Texture)
+		w.vec2(c.Size)
+		w.vec2(c.Gravity)
+		w.u32(c.Color)
+		w.bool(c.FlipX)
+		w.bool(c.FlipY)
+		w.varint(int64(c.Z))
+		w.bool(c.Visible)
+	}
+	if c := se.Text; c != nil {
+		w.str(c.Font)
+		w.str(c.Text)
+		w.f32(c.FontSize)
+		w.u32(c.Color)
+		w.vec2(c.Gravity)
+		w.varint(int64(c.Z))
+		w.bool(c.Visible)
+	}
+	if c := se.Flipbook; c != nil {
+		w.str(c.Animation)
+		w.f32(c.Rate)
+		w.bool(c.Loop)
+		w.u8(c.LoopType)
+		w.bool(c.Running)
+	}
+	if c := se.Particle; c != nil {
+		w.str(c.Config)
+		w.tex(c.Texture)
+		w.vec2(c.Size)
+		w.varint(int64(c.Z))
+		w.bool(c.Visible)
+	}
+	if c := se.Tag; c != nil {
+		w.str(c.Name)
+		w.str(c.Label)
+	}
+}
+
+func (se *sceneEntity) decode(r *sceneReader) {
+	se.Id = uint32(r.uvarint())
+	se.Parent = uint32(r.uvarint())
+	mask := r.u8()
+
+	if mask&maskTransform != 0 {
+		c := &sceneTransform{}
+		c.Position = r.vec2()
+		c.Rotation = r.f32()
+		c.Scale = r.vec2()
+		se.Transform = c
+	}
+	if mask&maskSprite != 0 {
+		c := &sceneSprite{}
+		c.Texture = r.tex()
+		c.Size = r.vec2()
+		c.Gravity = r.vec2()
+		c.Color = r.u32()
+		c.FlipX = r.bool()
+		c.FlipY = r.bool()
+		c.Z = int16(r.varint())
+		c.Visible = r.bool()
+		se.Sprite = c
+	}
+	if mask&maskText != 0 {
+		c := &sceneText{}
+		c.Font = r.str()
+		c.Text = r.str()
+		c.FontSize = r.f32()
+		c.Color = r.u32()
+		c.Gravity = r.vec2()
+		c.Z = int16(r.varint())
+		c.Visible = r.bool()
+		se.Text = c
+	}
+	if mask&maskFlipbook != 0 {
+		c := &sceneFlipbook{}
+		c.Animation = r.str()
+		c.Rate = r.f32()
+		c.Loop = r.bool()
+		c.LoopType = r.u8()
+		c.Running = r.bool()
+		se.Flipbook = c
+	}
+	if mask&maskParticle != 0 {
+		c := &sceneParticle{}
+		c.Config = r.str()
+		c.Texture = r.tex()
+		c.Size = r.vec2()
+		c.Z = int16(r.varint())
+		c.Visible = r.bool()
+		se.Particle = c
+	}
+	if mask&maskTag != 0 {
+		c := &sceneTag{}
+		c.Name = r.str()
+		c.Label = r.str()
+		se.Tag = c
+	}
+}
+
+// sceneWriter and sceneReader keep the first error, so the caller only
+// checks error at the end.
+type sceneWriter struct {
+	w   *bufio.Writer
+	err error
+	buf [binary.MaxVarintLen64]byte
+}
+
+func (w *sceneWriter) bytes(b []byte) {
+	if w.err == nil {
+		_, w.err = w.w.Write(b)
+	}
+}
+
+func (w *sceneWriter) u8(v uint8) {
+	if w.err == nil {
+		w.err = w.w.WriteByte(v)
+	}
+}
+
+func (w *sceneWriter) bool(v bool) {
+	if v {
+		w.u8(1)
+	} else {
+		w.u8(0)
+	}
+}
+
+func (w *sceneWriter) u32(v uint32) {
+	binary.LittleEndian.PutUint32(w.buf[:4], v)
+	w.bytes(w.buf[:4])
+}
+
+func (w *sceneWriter) f32(v float32) {
+	w.u32(math.Float32bits(v))
+}
+
+func (w *sceneWriter) vec2(v f32.Vec2) {
+	w.f32(v[0])
+	w.f32(v[1])
+}
+
+func (w *sceneWriter) uvarint(v uint64) {
+	n := binary.PutUvarint(w.buf[:], v)
+	w.bytes(w.buf[:n])
+}
+
+func (w *sceneWriter) varint(v int64) {
+	n := binary.PutVarint(w.buf[:], v)
+	w.bytes(w.buf[:n])
+}
+
+func (w *sceneWriter) str(s string) {
+	w.uvarint(uint64(len(s)))
+	w.bytes([]byte(s))
+}
+
+func (w *sceneWriter) tex(ref *sceneTex) {
+	if ref == nil {
+		w.u8(0)
+		return
+	}
+	if ref.Atlas {
+		w.u8(2)
+	} else {
+		w.u8(1)
+	}
+	w.str(ref.File)
+	w.str(ref.Frame)
+	w.uvarint(uint64(ref.Index))
+}
+
+type sceneReader struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (r *sceneReader) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *sceneReader) bytes(b []byte) {
+	if r.err == nil {
+		_, err := io.ReadFull(r.r, b)
+		r.fail(err)
+	}
+}
+
+func (r *sceneReader) u8() uint8 {
+	if r.err != nil {
+		return 0
+	}
+	b, err := r.r.ReadByte()
+	r.fail(err)
+	return b
+}
+
+func (r *sceneReader) bool() bool {
+	return r.u8() != 0
+}
+
+func (r *sceneReader) u32() uint32 {
+	var b [4]byte
+	r.bytes(b[:])
+	return binary.LittleEndian.Uint32(b[:])
+}
+
+func (r *sceneReader) f32() float32 {
+	return math.Float32frombits(r.u32())
+}
+
+func (r *sceneReader) vec2() f32.Vec2 {
+	return f32.Vec2{r.f32(), r.f32()}
+}
+
+func (r *sceneReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(r.r)
+	r.fail(err)
+	return v
+}
+
+func (r *sceneReader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(r.r)
+	r.fail(err)
+	return v
+}
+
+func (r *sceneReader) str() string {
+	n := r.uvarint()
+	if r.err != nil || n == 0 {
+		return ""
+	}
+	if n > 1<<20 {
+		r.fail(errors.New("invalid scene file, string too long"))
+		return ""
+	}
+	b := make([]byte, n)
+	r.bytes(b)
+	return string(b)
+}
+
+func (r *sceneReader) tex() *sceneTex {
+	typ := r.u8()
+	if typ == 0 {
+		return nil
+	}
+	ref := &sceneTex{Atlas: typ == 2}
+	ref.File = r.str()
+	ref.Frame = r.str()
+	ref.Index = int(r.uvarint())
+	return ref
+}
@@ -0,0 +1,114 @@
+package game
+
+import (
+	"bytes"
+	"testing"
+
+	"korok.io/korok/anim/frame"
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx"
+	"korok.io/korok/math/f32"
+)
+
+func TestSceneRoundTrip(t *testing.T) {
+	for _, format := range []SceneFormat{SceneJSON, SceneBinary} {
+		src := &Game{}
+		src.loadTables()
+		db := &src.DB
+		tables := db.sceneTables()
+
+		// car -> wheel1, wheel2
+		em := db.EntityM
+		car, wheel1, wheel2, label := em.New(), em.New(), em.New(), em.New()
+		for _, e := range []engi.Entity{car, wheel1, wheel2} {
+			tables.xt.NewComp(e)
+		}
+		xf := tables.xt.Comp(car)
+		xf.LinkChildren(tables.xt.Comp(wheel1), tables.xt.Comp(wheel2))
+		xf.SetPosition(f32.Vec2{100, 50})
+		xf.SetRotation(1)
+		xf.SetScale(f32.Vec2{2, 2})
+		tables.xt.Comp(wheel2).SetPosition(f32.Vec2{10, 0})
+
+		sc := tables.st.NewComp(car)
+		sc.SetSize(64, 32)
+		sc.SetGravity(0, 1)
+		sc.SetColor(gfx.Color{R: 255, A: 255})
+		sc.Flip(true, false)
+		sc.SetZOrder(3)
+
+		fb := tables.ft.NewComp(wheel1)
+		fb.SetRate(.5)
+		fb.SetLoop(true, frame.PingPong)
+		fb.Play("roll")
+
+		tables.gt.NewComp(car).Name = "car"
+		tables.gt.NewComp(label).Label = "ui"
+
+		buf := &bytes.Buffer{}
+		if err := db.SaveScene(buf, format); err != nil {
+			t.Fatal("save scene:", err)
+		}
+
+		dst := &Game{}
+		dst.loadTables()
+		dst.DB.EntityM.New() // entities should not be reused in the file
+		list, err := dst.DB.LoadScene(buf)
+		if err != nil {
+			t.Fatal("load scene:", err)
+		}
+		if len(list) != 4 {
+			t.Fatal("expected 4 entities, got:", len(list))
+		}
+		lt := dst.DB.sceneTables()
+
+		// car is the first root, wheels follow it
+		car, wheel1, wheel2 = list[0], list[1], list[2]
+		xf = lt.xt.Comp(car)
+		if xf == nil || xf.Position() != (f32.Vec2{100, 50}) || xf.Rotation() != 1 || xf.Scale() != (f32.Vec2{2, 2}) {
+			t.Errorf("format %d: wrong transform of car", format)
+		}
+		c1 := xf.FirstChild()
+		if c1 == nil || c1.Entity != wheel1 {
+			t.Fatalf("format %d: wrong hierarchy", format)
+		}
+		if _, c2 := c1.Sibling(); c2 == nil || c2.Entity != wheel2 || c2.Parent() != xf {
+			t.Fatalf("format %d: wrong hierarchy", format)
+		}
+		if w := lt.xt.Comp(wheel2).World().Position; w != (f32.Vec2{110, 50}) {
+			t.Errorf("format %d: world position of wheel2, got: %v", format, w)
+		}
+
+		sc = lt.st.Comp(car)
+		if sc == nil {
+			t.Fatalf("format %d: sprite not loaded", format)
+		}
+		w, h := sc.Size()
+		gx, gy := sc.Gravity()
+		fx, fy := sc.Flipped()
+		if w != 64 || h != 32 || gx != 0 || gy != 1 || !fx || fy || sc.Z() != 3 || sc.Color() != (gfx.Color{R: 255, A: 255}) || !sc.Visible() {
+			t.Errorf("format %d: wrong sprite %+v", format, sc)
+		}
+
+		fb = lt.ft.Comp(wheel1)
+		if loop, typ := fb.Loop(); fb == nil || fb.Animation() != "roll" || fb.Rate() != .5 || !loop || typ != frame.PingPong || !fb.Running() {
+			t.Errorf("format %d: wrong flipbook", format)
+		}
+
+		if tc := lt.gt.Comp(car); tc == nil || tc.Name != "car" {
+			t.Errorf("format %d: wrong tag of car", format)
+		}
+		if tc := lt.gt.Comp(list[3]); tc == nil || tc.Label != "ui" || lt.xt.Comp(list[3]) != nil {
+			t.Errorf("format %d: wrong tag entity", format)
+		}
+	}
+}
+
+func TestSceneVersion(t *testing.T) {
+	g := &Game{}
+	g.loadTables()
+	_, err := g.DB.LoadScene(bytes.NewBufferString(`{"version": 99, "entities": []}`))
+	if err == nil {
+		t.Error("newer version should not be loaded")
+	}
+}
@@ -0,0 +1,182 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"korok.io/korok/asset"
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx"
+	"korok.io/korok/gfx/bk"
+	"korok.io/korok/math/f32"
+)
+
+type recordScene struct {
+	name string
+	log  *[]string
+}
+
+func (sn *recordScene) OnEnter(g *Game)   { *sn.log = append(*sn.log, sn.name+".enter") }
+func (sn *recordScene) Update(dt float32) {}
+func (sn *recordScene) OnExit()           { *sn.log = append(*sn.log, sn.name+".exit") }
+func (sn *recordScene) Load()             { *sn.log = append(*sn.log, sn.name+".load") }
+
+type asyncScene struct {
+	recordScene
+}
+
+func (sn *asyncScene) LoadAsync(b *asset.Batch) {
+	// not found, the error is logged and the batch goes on
+	b.Texture("test/missing.png").Audio("test/missing.ogg", false)
+}
+
+func TestLoadAsync(t *testing.T) {
+	var log []string
+	sm := &SceneManager{}
+	loading := &recordScene{"loading", &log}
+	target := &asyncScene{recordScene{"target", &log}}
+
+	h := sm.LoadAsync(target, loading)
+	if sn, _ := sm.Peek(); sn != loading {
+		t.Fatal("loading scene should be shown")
+	}
+	if h := sm.LoadAsync(&recordScene{"other", &log}, nil); h != nil {
+		t.Error("loading should be rejected before the last one is done")
+	}
+	for deadline := time.Now().Add(5 * time.Second); !sm.State().Done(); {
+		if time.Now().After(deadline) {
+			t.Fatal("async loading timeout, progress:", sm.State().Progress())
+		}
+		sm.Update(1.0 / 60)
+	}
+	select {
+	case <-h:
+	default:
+		t.Error("handler is not notified")
+	}
+	if p := sm.State().Progress(); p != 100 {
+		t.Error("progress should be 100, got:", p)
+	}
+	if sn, _ := sm.Peek(); sn != target || len(sm.stack) != 1 {
+		t.Error("loading scene should be replaced by target")
+	}
+
+	expect := []string{"loading.load", "loading.enter", "target.load", "loading.exit", "target.enter"}
+	if len(log) != len(expect) {
+		t.Fatal("wrong lifecycle:", log)
+	}
+	for i := range expect {
+		if log[i] != expect[i] {
+			t.Fatal("wrong lifecycle:", log)
+		}
+	}
+
+	// a new loading is accepted after done
+	if h := sm.LoadAsync(&recordScene{"next", &log}, nil); h == nil {
+		t.Error("loading should be accepted after the last one is done")
+	}
+}
+
+type rootScene struct {
+	recordScene
+	root    engi.Entity
+	updates int
+}
+
+func (sn *rootScene) Update(dt float32) { sn.updates++ }
+func (sn *rootScene) Root() engi.Entity { return sn.root }
+
+func TestPushWithTransition(t *testing.T) {
+	g := &Game{}
+	g.loadTables()
+	g.SceneManager.Setup(g)
+	sm := &g.SceneManager
+
+	var (
+		xt *gfx.TransformTable
+		st *gfx.SpriteTable
+	)
+	g.DB.LookupTable(&xt)
+	g.DB.LookupTable(&st)
+
+	var log []string
+	newScene := func(name string) *rootScene {
+		sn := &rootScene{recordScene: recordScene{name, &log}}
+		sn.root = g.DB.EntityM.New()
+		xt.NewComp(sn.root).SetPosition(f32.Vec2{100, 100})
+		child := g.DB.EntityM.New()
+		xt.NewComp(child)
+		xt.Comp(sn.root).LinkChild(xt.Comp(child))
+		st.NewComp(child).SetColor(gfx.White)
+		return sn
+	}
+	a, b := newScene("a"), newScene("b")
+	sm.Push(a)
+
+	done := false
+	sm.PushWith(b, Transition{Effect: &Slide{Dir: ToLeft, Distance: 100}, Duration: 1, OnComplete: func() {
+		done = true
+	}})
+	if !sm.Transitioning() || log[len(log)-1] != "b.enter" {
+		t.Fatal("transition should begin after incoming scene entered:", log)
+	}
+	if p := xt.Comp(b.root).Position(); p != (f32.Vec2{200, 100}) {
+		t.Error("incoming scene should start out of screen, got:", p)
+	}
+
+	sm.Update(.5)
+	if a.updates != 1 || b.updates != 1 {
+		t.Error("both scenes should be updated during transition")
+	}
+	if p := xt.Comp(a.root).Position(); p != (f32.Vec2{50, 100}) {
+		t.Error("outgoing scene should slide out, got:", p)
+	}
+	if done || log[len(log)-1] == "a.exit" {
+		t.Error("transition finished too early")
+	}
+
+	sm.Update(.5)
+	if !done || sm.Transitioning() || log[len(log)-1] != "a.exit" {
+		t.Fatal("transition should be finished:", log)
+	}
+	if p := xt.Comp(a.root).Position(); p != (f32.Vec2{100, 100}) {
+		t.Error("outgoing scene should be restored, got:", p)
+	}
+
+	// cross fade back
+	cf := &CrossFade{}
+	sm.PopWith(Transition{Effect: cf, Duration: 1})
+	sm.Update(.25)
+	bc := xt.Comp(b.root).FirstChild().Entity
+	if c := st.Comp(bc).Color(); c.A != 191 {
+		t.Error("outgoing scene should fade out, got:", c)
+	}
+	sm.Pop()
+	if sm.Transitioning() || st.Comp(bc).Color() != gfx.White {
+		t.Error("Pop should finish the transition and restore colors")
+	}
+}
+
+func TestShaderEffectDestroy(t *testing.T) {
+	defer bk.SetBackend(bk.CurrentBackend())
+	bk.SetBackend(bk.NewRecorder())
+	gfx.Init(1)
+
+	se := &ShaderEffect{}
+	se.Begin(&Game{}, nil, nil)
+	ib, vb := se.mesh.IndexId, se.mesh.VertexId
+	if se.render == nil || ib == 0 || vb == 0 {
+		t.Fatal("mesh should be created in Begin")
+	}
+	se.Destroy()
+	if se.render != nil || se.mesh.IndexId != 0 {
+		t.Error("effect should be reset")
+	}
+	// the freed buffers are reused
+	se.Begin(&Game{}, nil, nil)
+	if se.mesh.IndexId != ib || se.mesh.VertexId != vb {
+		t.Error("buffers should be freed, got:", se.mesh.IndexId, se.mesh.VertexId)
+	}
+	se.Destroy()
+	se.Destroy()
+}
@@ -1,6 +1,10 @@
 package game
 
-import "korok.io/korok/engi"
+import (
+	"korok.io/korok/anim/frame"
+	"korok.io/korok/engi"
+	"korok.io/korok/physics"
+)
 
 /**
 	游戏对象绑定脚本/行为
@@ -51,7 +55,7 @@ func (st *ScriptTable) NewComp(entity engi.Entity, script Script) (sc *ScriptCom
 
 func (st *ScriptTable) Alive(entity engi.Entity) bool {
 	if v, ok := st._map[entity.Index()]; ok {
-		return st.comps[v].Entity == 0
+		return st.comps[v].Entity == entity
 	}
 	return false
 }
@@ -86,6 +90,11 @@ func (st *ScriptTable) Size() (size, cap int) {
 	return st.index, st.cap
 }
 
+// EntityAt returns the entity of the i-th comp, i in [0, size)
+func (st *ScriptTable) EntityAt(i int) engi.Entity {
+	return st.comps[i].Entity
+}
+
 func scriptResize(slice []ScriptComp, size int) []ScriptComp {
 	newSlice := make([]ScriptComp, size)
 	copy(newSlice, slice)
@@ -120,3 +129,59 @@ func (ss *ScriptSystem) Update(dt float32) {
 
 
 
+
+// scriptContact sends contact events to the scripts which implement
+// physics.ContactListener, the entity of script is always Contact.A.
+type scriptContact struct {
+	*ScriptTable
+}
+
+func (sc *scriptContact) OnContactBegin(c *physics.Contact) {
+	if l, ok := sc.listener(c.A); ok {
+		l.OnContactBegin(c)
+	}
+	if l, ok := sc.listener(c.B); ok {
+		r := swapContact(c)
+		l.OnContactBegin(&r)
+	}
+}
+
+func (sc *scriptContact) OnContactEnd(c *physics.Contact) {
+	if l, ok := sc.listener(c.A); ok {
+		l.OnContactEnd(c)
+	}
+	if l, ok := sc.listener(c.B); ok {
+		r := swapContact(c)
+		l.OnContactEnd(&r)
+	}
+}
+
+func (sc *scriptContact) listener(e engi.Entity) (l physics.ContactListener, ok bool) {
+	if sc.ScriptTable == nil || !sc.Alive(e) {
+		return
+	}
+	l, ok = sc.Comp(e).Script.(physics.ContactListener)
+	return
+}
+
+// scriptFrameEvent sends frame events of FlipbookComp to the scripts
+// which implement frame.FrameEventListener.
+type scriptFrameEvent struct {
+	*ScriptTable
+}
+
+func (sf *scriptFrameEvent) OnFrameEvent(e engi.Entity, event string) {
+	if sf.ScriptTable == nil || !sf.Alive(e) {
+		return
+	}
+	if l, ok := sf.Comp(e).Script.(frame.FrameEventListener); ok {
+		l.OnFrameEvent(e, event)
+	}
+}
+
+func swapContact(c *physics.Contact) physics.Contact {
+	r := *c
+	r.A, r.B = c.B, c.A
+	r.Normal = c.Normal.Mul(-1)
+	return r
+}
@@ -48,7 +48,7 @@ func (tt *TagTable) NewComp(entity engi.Entity) (tc *TagComp) {
 
 func (tt *TagTable) Alive(entity engi.Entity) bool {
 	if v, ok := tt._map[entity.Index()]; ok {
-		return tt.comps[v].Entity == 0
+		return tt.comps[v].Entity == entity
 	}
 	return false
 }
@@ -60,7 +60,7 @@ func (tt *TagTable) Comp(entity engi.Entity) (tc *TagComp) {
 	return
 }
 
-func (tt *TagTable) Delete(entity engi.Entity) (tc *TagComp) {
+func (tt *TagTable) Delete(entity engi.Entity) {
 	ei := entity.Index()
 	if v, ok := tt._map[ei]; ok {
 		if tail := tt.index-1; v != tail && tail > 0 {
@@ -76,7 +76,6 @@ func (tt *TagTable) Delete(entity engi.Entity) (tc *TagComp) {
 		tt.index -= 1
 		delete(tt._map, ei)
 	}
-	return nil
 }
 
 // 删除所有属于该标签的元素..
@@ -100,6 +99,11 @@ func (tt *TagTable) Size() (size, cap int) {
 	return tt.index, tt.cap
 }
 
+// EntityAt returns the entity of the i-th comp, i in [0, size)
+func (tt *TagTable) EntityAt(i int) engi.Entity {
+	return tt.comps[i].Entity
+}
+
 func tagResize(slice []TagComp, size int) []TagComp {
 	newSlice := make([]TagComp, size)
 	copy(newSlice, slice)
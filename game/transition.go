@@ -0,0 +1,394 @@
+package game
+
+import (
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx"
+	"korok.io/korok/gfx/bk"
+	"korok.io/korok/gui"
+	"korok.io/korok/math/ease"
+	"korok.io/korok/math/f32"
+
+	"math"
+)
+
+// Transition describes how to switch from one scene to another. Both
+// the outgoing and incoming scenes are updated during the transition,
+// and input events are blocked.
+type Transition struct {
+	Effect TransitionEffect
+
+	// duration in seconds
+	Duration float32
+
+	// ease function of the progress, nil means ease.Linear
+	Function ease.Function
+
+	// called when the transition is finished
+	OnComplete func()
+}
+
+// TransitionEffect draws the transition. Begin is called when the
+// transition starts, the incoming scene has been entered. Draw is
+// called each frame with the eased progress in [0, 1]. End is called
+// before the outgoing scene exits.
+type TransitionEffect interface {
+	Begin(g *Game, out, in Scene)
+	Draw(progress float32)
+	End()
+}
+
+// SceneRoot is implemented by scenes which put all their entities under
+// a root Transform, effects like CrossFade and Slide work on the root
+// and its descendants. Fade and Wipe work on any scene, but they can only
+// hide the scenes with SceneRoot.
+type SceneRoot interface {
+	Root() engi.Entity
+}
+
+type TransitionDir uint8
+
+const (
+	ToLeft TransitionDir = iota
+	ToRight
+	ToUp
+	ToDown
+)
+
+func (dir TransitionDir) vec() f32.Vec2 {
+	switch dir {
+	case ToLeft:
+		return f32.Vec2{-1, 0}
+	case ToRight:
+		return f32.Vec2{1, 0}
+	case ToUp:
+		return f32.Vec2{0, 1}
+	default:
+		return f32.Vec2{0, -1}
+	}
+}
+
+// running transition
+type transition struct {
+	Transition
+	out, in Scene
+	elapsed float32
+
+	// called when finished, exit the outgoing scene
+	finish func()
+}
+
+func (t *transition) progress() float32 {
+	p := float32(1)
+	if t.Duration > 0 {
+		p = t.elapsed / t.Duration
+	}
+	if p > 1 {
+		p = 1
+	}
+	if fn := t.Function; fn != nil {
+		p = float32(fn(float64(p)))
+	}
+	return p
+}
+
+// Fade fades the outgoing scene to a color, then fades in the incoming
+// scene from the color. The scenes are hidden with SceneRoot, so only the
+// outgoing scene is shown in the first half and the incoming scene in the
+// second half. Without SceneRoot only the color is drawn, both scenes are
+// visible under the color except the middle of the transition.
+type Fade struct {
+	Color gfx.Color
+
+	out, in *sceneView
+}
+
+func (f *Fade) Begin(g *Game, out, in Scene) {
+	f.out, f.in = newSceneView(g, out), newSceneView(g, in)
+	f.in.setAlpha(0)
+}
+
+func (f *Fade) Draw(p float32) {
+	if p < .5 {
+		coverScreen(f.Color, p*2, 0, 0, 1, 1)
+	} else {
+		f.out.setAlpha(0)
+		f.in.setAlpha(1)
+		coverScreen(f.Color, 2-p*2, 0, 0, 1, 1)
+	}
+}
+
+func (f *Fade) End() {
+	f.out.restore()
+	f.in.restore()
+	f.out, f.in = nil, nil
+}
+
+// Wipe covers the outgoing scene with a color sweeping in the direction,
+// then uncovers the incoming scene in the same direction. Like Fade, the
+// uncovered part shows both scenes if they are not SceneRoot.
+type Wipe struct {
+	Color gfx.Color
+	Dir   TransitionDir
+
+	out, in *sceneView
+}
+
+func (w *Wipe) Begin(g *Game, out, in Scene) {
+	w.out, w.in = newSceneView(g, out), newSceneView(g, in)
+	w.in.setAlpha(0)
+}
+
+func (w *Wipe) Draw(p float32) {
+	// the covered range along the direction, in [0, 1]
+	from, to := float32(0), p*2
+	if p >= .5 {
+		w.out.setAlpha(0)
+		w.in.setAlpha(1)
+		from, to = p*2-1, 1
+	}
+	switch w.Dir {
+	case ToRight:
+		coverScreen(w.Color, 1, from, 0, to, 1)
+	case ToLeft:
+		coverScreen(w.Color, 1, 1-to, 0, 1-from, 1)
+	case ToDown:
+		coverScreen(w.Color, 1, 0, from, 1, to)
+	case ToUp:
+		coverScreen(w.Color, 1, 0, 1-to, 1, 1-from)
+	}
+}
+
+func (w *Wipe) End() {
+	w.out.restore()
+	w.in.restore()
+	w.out, w.in = nil, nil
+}
+
+// CrossFade fades out the outgoing scene and fades in the incoming scene
+// at the same time, only works with SceneRoot.
+type CrossFade struct {
+	out, in *sceneView
+}
+
+func (cf *CrossFade) Begin(g *Game, out, in Scene) {
+	cf.out, cf.in = newSceneView(g, out), newSceneView(g, in)
+	cf.in.setAlpha(0)
+}
+
+func (cf *CrossFade) Draw(p float32) {
+	cf.out.setAlpha(1 - p)
+	cf.in.setAlpha(p)
+}
+
+func (cf *CrossFade) End() {
+	cf.out.restore()
+	cf.in.restore()
+	cf.out, cf.in = nil, nil
+}
+
+// Slide pushes the outgoing scene out of the screen by the incoming
+// scene, only works with SceneRoot. Distance is the width(or height) of
+// the screen if zero.
+type Slide struct {
+	Dir      TransitionDir
+	Distance float32
+
+	d       f32.Vec2
+	out, in *sceneView
+}
+
+func (s *Slide) Begin(g *Game, out, in Scene) {
+	s.out, s.in = newSceneView(g, out), newSceneView(g, in)
+	d := s.Distance
+	if d == 0 && g.RenderSystem != nil {
+		_, _, w, h := g.Camera().View()
+		if s.Dir == ToLeft || s.Dir == ToRight {
+			d = w
+		} else {
+			d = h
+		}
+	}
+	s.d = s.Dir.vec().Mul(d)
+	s.in.setOffset(s.d.Mul(-1))
+}
+
+func (s *Slide) Draw(p float32) {
+	s.out.setOffset(s.d.Mul(p))
+	s.in.setOffset(s.d.Mul(p - 1))
+}
+
+func (s *Slide) End() {
+	s.out.restore()
+	s.in.restore()
+	s.out, s.in = nil, nil
+}
+
+// ShaderEffect draws a full screen quad with a custom shader over the
+// scenes. The shader has the same attributes and uniforms as the "mesh"
+// shader: "xyuv", "rgba", "proj", "model" and "tex". The uv of the quad
+// covers [0, 1], and the progress is passed in the alpha of "rgba".
+// The shader and mesh are created in the first Begin and reused, call
+// Destroy to free them when the effect is not used anymore.
+type ShaderEffect struct {
+	Vertex, Fragment string
+
+	// optional texture bound to "tex"
+	Texture uint16
+
+	render *gfx.MeshRender
+	mesh   gfx.Mesh
+	vertex []gfx.PosTexColorVertex
+	camera *gfx.Camera
+	mat4   f32.Mat4
+}
+
+func (se *ShaderEffect) Begin(g *Game, out, in Scene) {
+	if g.RenderSystem != nil {
+		se.camera = g.Camera()
+	}
+	if se.render != nil {
+		return
+	}
+	se.render = gfx.NewMeshRender(se.Vertex, se.Fragment)
+	se.mat4 = f32.Ident4()
+	se.vertex = []gfx.PosTexColorVertex{
+		{U: 0, V: 1}, {U: 1, V: 1}, {U: 1, V: 0}, {U: 0, V: 0},
+	}
+	se.mesh.SetVertex(se.vertex)
+	se.mesh.SetIndex([]uint16{3, 0, 1, 3, 1, 2})
+	se.mesh.Setup()
+	se.mesh.SetTexture(se.Texture)
+}
+
+func (se *ShaderEffect) Draw(p float32) {
+	if se.camera == nil {
+		return
+	}
+	x, y, w, h := se.camera.View()
+	left, right, bottom, top := x-w/2, x+w/2, y-h/2, y+h/2
+	color := uint32(p*255)<<24 | 0xFFFFFF
+	corners := [4]f32.Vec2{{left, bottom}, {right, bottom}, {right, top}, {left, top}}
+	for i := range se.vertex {
+		v := &se.vertex[i]
+		v.X, v.Y, v.RGBA = corners[i][0], corners[i][1], color
+	}
+	se.mesh.Update()
+	se.render.SetCamera(se.camera)
+	se.render.Draw(&se.mesh, &se.mat4, math.MaxInt16)
+}
+
+func (se *ShaderEffect) End() {
+	se.camera = nil
+}
+
+// Destroy frees the shader and mesh of the effect, the Texture is owned
+// by the caller and not freed. It's safe to Begin the effect again.
+func (se *ShaderEffect) Destroy() {
+	if se.render == nil {
+		return
+	}
+	se.render.Destroy()
+	for _, id := range []uint16{se.mesh.IndexId, se.mesh.VertexId} {
+		if id != 0 {
+			bk.R.Free(id)
+		}
+	}
+	se.render, se.mesh, se.vertex, se.camera = nil, gfx.Mesh{}, nil, nil
+}
+
+// draw a color rect over the screen, the rect is in [0, 1] of the screen
+func coverScreen(c gfx.Color, alpha float32, x0, y0, x1, y1 float32) {
+	if alpha <= 0 || x1 <= x0 || y1 <= y0 {
+		return
+	}
+	if alpha < 1 {
+		c = scaleColor(c, alpha)
+	}
+	w, h, _, _ := gui.HintAndScale()
+	old := gui.SetZOrder(math.MaxInt16)
+	gui.ColorRect(gui.Rect{X: x0 * w, Y: y0 * h, W: (x1 - x0) * w, H: (y1 - y0) * h}, c, 0)
+	gui.SetZOrder(old)
+}
+
+// colors are alpha premultiplied
+func scaleColor(c gfx.Color, a float32) gfx.Color {
+	return gfx.Color{R: uint8(float32(c.R) * a), G: uint8(float32(c.G) * a), B: uint8(float32(c.B) * a), A: uint8(float32(c.A) * a)}
+}
+
+// the entities of a SceneRoot, changed by effects and restored at end
+type sceneView struct {
+	xt *gfx.TransformTable
+	st *gfx.SpriteTable
+	tt *gfx.TextTable
+
+	root     engi.Entity
+	position f32.Vec2
+	sprites  map[engi.Entity]gfx.Color
+	texts    map[engi.Entity]gfx.Color
+}
+
+// returns nil if the scene has no root, all methods of nil view do nothing
+func newSceneView(g *Game, sn Scene) *sceneView {
+	sr, ok := sn.(SceneRoot)
+	if !ok {
+		return nil
+	}
+	v := &sceneView{root: sr.Root()}
+	g.DB.LookupTable(&v.xt)
+	g.DB.LookupTable(&v.st)
+	g.DB.LookupTable(&v.tt)
+	if v.xt == nil {
+		return nil
+	}
+	if xf := v.xt.Comp(v.root); xf != nil {
+		v.position = xf.Position()
+	}
+	v.sprites = make(map[engi.Entity]gfx.Color)
+	v.texts = make(map[engi.Entity]gfx.Color)
+	for _, e := range g.DB.children([]engi.Entity{v.root}, v.root) {
+		if v.st != nil {
+			if sc := v.st.Comp(e); sc != nil {
+				v.sprites[e] = sc.Color()
+			}
+		}
+		if v.tt != nil {
+			if tc := v.tt.Comp(e); tc != nil {
+				v.texts[e] = tc.Color()
+			}
+		}
+	}
+	return v
+}
+
+func (v *sceneView) setAlpha(a float32) {
+	if v == nil {
+		return
+	}
+	for e, c := range v.sprites {
+		if sc := v.st.Comp(e); sc != nil {
+			sc.SetColor(scaleColor(c, a))
+		}
+	}
+	for e, c := range v.texts {
+		if tc := v.tt.Comp(e); tc != nil {
+			tc.SetColor(scaleColor(c, a))
+		}
+	}
+}
+
+func (v *sceneView) setOffset(d f32.Vec2) {
+	if v == nil {
+		return
+	}
+	if xf := v.xt.Comp(v.root); xf != nil {
+		xf.SetPosition(v.position.Add(d))
+	}
+}
+
+func (v *sceneView) restore() {
+	if v == nil {
+		return
+	}
+	v.setAlpha(1)
+	v.setOffset(f32.Vec2{})
+}
@@ -0,0 +1,545 @@
+package game
+
+import (
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx"
+	"korok.io/korok/math/f32"
+
+	"math"
+	"sort"
+)
+
+// 触发器是不参与物理模拟的碰撞形状, 适用于 "玩家是否碰到了金币" 这样的
+// 需求. TriggerSystem 每帧检测触发器之间的重叠, 产生 Enter/Stay/Exit 事件,
+// 也可以随时查询点, 矩形, 圆和射线覆盖的 Entity.
+//
+// 触发器通过 TagComp 的 Name/Label 过滤, 比如金币只关心 Name 为 "player"
+// 的对象:
+//
+//	coin := Trigger.NewComp(e)
+//	coin.SetCircle(16)
+//	coin.SetFilter(TagFilter{Name: "player"})
+
+type TriggerShape uint8
+
+const (
+	TriggerRect TriggerShape = iota
+	TriggerCircle
+)
+
+// TagFilter matches the TagComp of entity, empty field matches anything,
+// an entity without TagComp only matches the empty filter.
+type TagFilter struct {
+	Name, Label string
+}
+
+func (f TagFilter) empty() bool {
+	return f.Name == "" && f.Label == ""
+}
+
+func (f TagFilter) match(tt *TagTable, e engi.Entity) bool {
+	if f.empty() {
+		return true
+	}
+	if tt == nil {
+		return false
+	}
+	tc := tt.Comp(e)
+	if tc == nil || tc.Entity != e {
+		return false
+	}
+	return (f.Name == "" || f.Name == tc.Name) && (f.Label == "" || f.Label == tc.Label)
+}
+
+// TriggerListener receives trigger events, self is the entity of the
+// trigger, and other passes its filter. A Script which implements it
+// receives the events of its entity.
+type TriggerListener interface {
+	OnTriggerEnter(self, other engi.Entity)
+	OnTriggerStay(self, other engi.Entity)
+	OnTriggerExit(self, other engi.Entity)
+}
+
+// TriggerComp is centered at the world position of Transform plus the
+// offset, the size is scaled with Transform, rotation is ignored.
+type TriggerComp struct {
+	engi.Entity
+	shape  TriggerShape
+	offset f32.Vec2
+
+	width, height float32
+	radius        float32
+
+	filter  TagFilter
+	enabled bool
+}
+
+func (tc *TriggerComp) Shape() TriggerShape {
+	return tc.shape
+}
+
+func (tc *TriggerComp) SetRect(w, h float32) {
+	tc.shape = TriggerRect
+	tc.width, tc.height = w, h
+}
+
+func (tc *TriggerComp) Size() (w, h float32) {
+	return tc.width, tc.height
+}
+
+func (tc *TriggerComp) SetCircle(radius float32) {
+	tc.shape = TriggerCircle
+	tc.radius = radius
+}
+
+func (tc *TriggerComp) Radius() float32 {
+	return tc.radius
+}
+
+func (tc *TriggerComp) SetOffset(offset f32.Vec2) {
+	tc.offset = offset
+}
+
+func (tc *TriggerComp) Offset() f32.Vec2 {
+	return tc.offset
+}
+
+// SetFilter sets the filter of the entities this trigger reports.
+func (tc *TriggerComp) SetFilter(f TagFilter) {
+	tc.filter = f
+}
+
+func (tc *TriggerComp) Filter() TagFilter {
+	return tc.filter
+}
+
+// A disabled trigger doesn't report events and can't be queried.
+func (tc *TriggerComp) SetEnabled(v bool) {
+	tc.enabled = v
+}
+
+func (tc *TriggerComp) Enabled() bool {
+	return tc.enabled
+}
+
+// TriggerTable
+type TriggerTable struct {
+	comps      []TriggerComp
+	_map       map[uint32]int
+	index, cap int
+}
+
+func NewTriggerTable(cap int) *TriggerTable {
+	return &TriggerTable{cap: cap, _map: make(map[uint32]int)}
+}
+
+func (tt *TriggerTable) NewComp(entity engi.Entity) (tc *TriggerComp) {
+	if size := len(tt.comps); tt.index >= size {
+		tt.comps = triggerResize(tt.comps, size+64)
+	}
+	ei := entity.Index()
+	if v, ok := tt._map[ei]; ok {
+		return &tt.comps[v]
+	}
+	tc = &tt.comps[tt.index]
+	tc.Entity = entity
+	tc.enabled = true
+	tt._map[ei] = tt.index
+	tt.index++
+	return
+}
+
+func (tt *TriggerTable) Alive(entity engi.Entity) bool {
+	if v, ok := tt._map[entity.Index()]; ok {
+		return tt.comps[v].Entity == entity
+	}
+	return false
+}
+
+func (tt *TriggerTable) Comp(entity engi.Entity) (tc *TriggerComp) {
+	if v, ok := tt._map[entity.Index()]; ok {
+		tc = &tt.comps[v]
+	}
+	return
+}
+
+func (tt *TriggerTable) Delete(entity engi.Entity) {
+	ei := entity.Index()
+	if v, ok := tt._map[ei]; ok {
+		if tail := tt.index - 1; v != tail && tail > 0 {
+			tt.comps[v] = tt.comps[tail]
+			// remap index
+			tComp := &tt.comps[tail]
+			ei := tComp.Entity.Index()
+			tt._map[ei] = v
+			tt.comps[tail] = TriggerComp{}
+		} else {
+			tt.comps[tail] = TriggerComp{}
+		}
+		tt.index -= 1
+		delete(tt._map, ei)
+	}
+}
+
+func (tt *TriggerTable) Size() (size, cap int) {
+	return tt.index, tt.cap
+}
+
+// EntityAt returns the entity of the i-th comp, i in [0, size)
+func (tt *TriggerTable) EntityAt(i int) engi.Entity {
+	return tt.comps[i].Entity
+}
+
+func triggerResize(slice []TriggerComp, size int) []TriggerComp {
+	newSlice := make([]TriggerComp, size)
+	copy(newSlice, slice)
+	return newSlice
+}
+
+// world space shape of trigger
+type triggerShape struct {
+	*TriggerComp
+	bounds gfx.AABB
+	// circle
+	center f32.Vec2
+	radius float32
+}
+
+func (s *triggerShape) circle() bool {
+	return s.shape == TriggerCircle
+}
+
+func (s *triggerShape) left() float32 {
+	x, _, _, _ := s.bounds.Rect()
+	return x
+}
+
+func (s *triggerShape) right() float32 {
+	x, _, w, _ := s.bounds.Rect()
+	return x + w
+}
+
+// self -> other
+type triggerPair struct {
+	self, other engi.Entity
+}
+
+// RayHit is the result of Raycast, Fraction is in [0, 1] along the ray.
+type RayHit struct {
+	engi.Entity
+	Point    f32.Vec2
+	Fraction float32
+}
+
+type TriggerSystem struct {
+	tt *TriggerTable
+	xt *gfx.TransformTable
+	gt *TagTable
+	st *ScriptTable
+
+	shapes []triggerShape
+	sorted []int
+
+	// overlapped pairs of last frame
+	pairs   []triggerPair
+	overlap map[triggerPair]bool
+	current []triggerPair
+
+	listeners []TriggerListener
+}
+
+func NewTriggerSystem() *TriggerSystem {
+	return &TriggerSystem{overlap: make(map[triggerPair]bool)}
+}
+
+func (ts *TriggerSystem) RequireTable(tables []interface{}) {
+	for _, t := range tables {
+		switch table := t.(type) {
+		case *TriggerTable:
+			ts.tt = table
+		case *gfx.TransformTable:
+			ts.xt = table
+		case *TagTable:
+			ts.gt = table
+		case *ScriptTable:
+			ts.st = table
+		}
+	}
+}
+
+func (ts *TriggerSystem) AddTriggerListener(l TriggerListener) {
+	ts.listeners = append(ts.listeners, l)
+}
+
+func (ts *TriggerSystem) RemoveTriggerListener(l TriggerListener) {
+	for i, v := range ts.listeners {
+		if v == l {
+			ts.listeners = append(ts.listeners[:i], ts.listeners[i+1:]...)
+			return
+		}
+	}
+}
+
+// Update finds the overlapped triggers and sends Enter/Stay/Exit events.
+func (ts *TriggerSystem) Update(dt float32) {
+	if ts.tt == nil {
+		return
+	}
+	ts.updateShapes()
+
+	// sweep and prune along the x axis
+	shapes := ts.shapes
+	ts.sorted = ts.sorted[:0]
+	for i := range shapes {
+		ts.sorted = append(ts.sorted, i)
+	}
+	sort.Slice(ts.sorted, func(i, j int) bool {
+		return shapes[ts.sorted[i]].left() < shapes[ts.sorted[j]].left()
+	})
+	ts.current = ts.current[:0]
+	for i, ai := range ts.sorted {
+		a := &shapes[ai]
+		for _, bi := range ts.sorted[i+1:] {
+			b := &shapes[bi]
+			if b.left() > a.right() {
+				break
+			}
+			if !overlapShapes(a, b) {
+				continue
+			}
+			if a.filter.match(ts.gt, b.Entity) {
+				ts.current = append(ts.current, triggerPair{a.Entity, b.Entity})
+			}
+			if b.filter.match(ts.gt, a.Entity) {
+				ts.current = append(ts.current, triggerPair{b.Entity, a.Entity})
+			}
+		}
+	}
+
+	// enter & stay
+	for _, p := range ts.current {
+		if ts.overlap[p] {
+			ts.notify(p, (TriggerListener).OnTriggerStay)
+		} else {
+			ts.notify(p, (TriggerListener).OnTriggerEnter)
+		}
+	}
+	// exit
+	current := make(map[triggerPair]bool, len(ts.current))
+	for _, p := range ts.current {
+		current[p] = true
+	}
+	for _, p := range ts.pairs {
+		if !current[p] {
+			ts.notify(p, (TriggerListener).OnTriggerExit)
+		}
+	}
+	ts.overlap = current
+	ts.pairs = append(ts.pairs[:0], ts.current...)
+}
+
+func (ts *TriggerSystem) notify(p triggerPair, fn func(TriggerListener, engi.Entity, engi.Entity)) {
+	if st := ts.st; st != nil && st.Alive(p.self) {
+		if l, ok := st.Comp(p.self).Script.(TriggerListener); ok {
+			fn(l, p.self, p.other)
+		}
+	}
+	for _, l := range ts.listeners {
+		fn(l, p.self, p.other)
+	}
+}
+
+func (ts *TriggerSystem) updateShapes() {
+	ts.shapes = ts.shapes[:0]
+	comps := ts.tt.comps[:ts.tt.index]
+	for i := range comps {
+		if tc := &comps[i]; tc.enabled {
+			ts.shapes = append(ts.shapes, ts.shape(tc))
+		}
+	}
+}
+
+// compute world shape with Transform
+func (ts *TriggerSystem) shape(tc *TriggerComp) (s triggerShape) {
+	s.TriggerComp = tc
+	pos, scale := tc.offset, f32.Vec2{1, 1}
+	if ts.xt != nil {
+		if xf := ts.xt.Comp(tc.Entity); xf != nil {
+			world := xf.World()
+			pos = world.Position.Add(f32.Vec2{tc.offset[0] * world.Scale[0], tc.offset[1] * world.Scale[1]})
+			scale = world.Scale
+		}
+	}
+	if tc.shape == TriggerCircle {
+		s.center = pos
+		s.radius = tc.radius * max32(abs32(scale[0]), abs32(scale[1]))
+		s.bounds = gfx.NewAABB(pos[0]-s.radius, pos[1]-s.radius, s.radius*2, s.radius*2)
+	} else {
+		w, h := tc.width*abs32(scale[0]), tc.height*abs32(scale[1])
+		s.bounds = gfx.NewAABB(pos[0]-w/2, pos[1]-h/2, w, h)
+	}
+	return
+}
+
+func overlapShapes(a, b *triggerShape) bool {
+	if !gfx.OverlapAB(&a.bounds, &b.bounds) {
+		return false
+	}
+	switch {
+	case a.circle() && b.circle():
+		d, r := b.center.Sub(a.center), a.radius+b.radius
+		return d.Dot(d) <= r*r
+	case a.circle():
+		return overlapCircleRect(a.center, a.radius, &b.bounds)
+	case b.circle():
+		return overlapCircleRect(b.center, b.radius, &a.bounds)
+	}
+	return true
+}
+
+func overlapCircleRect(c f32.Vec2, r float32, ab *gfx.AABB) bool {
+	x, y, w, h := ab.Rect()
+	d := f32.Vec2{clamp32(c[0], x, x+w) - c[0], clamp32(c[1], y, y+h) - c[1]}
+	return d.Dot(d) <= r*r
+}
+
+// QueryPoint returns the triggers which contain the point.
+func (ts *TriggerSystem) QueryPoint(p f32.Vec2, filter TagFilter) []engi.Entity {
+	return ts.query(filter, func(s *triggerShape) bool {
+		if s.circle() {
+			d := p.Sub(s.center)
+			return d.Dot(d) <= s.radius*s.radius
+		}
+		return s.bounds.Contains(p[0], p[1])
+	})
+}
+
+// QueryRect returns the triggers which overlap the rect.
+func (ts *TriggerSystem) QueryRect(rect gfx.AABB, filter TagFilter) []engi.Entity {
+	return ts.query(filter, func(s *triggerShape) bool {
+		if !gfx.OverlapAB(&s.bounds, &rect) {
+			return false
+		}
+		return !s.circle() || overlapCircleRect(s.center, s.radius, &rect)
+	})
+}
+
+// QueryCircle returns the triggers which overlap the circle.
+func (ts *TriggerSystem) QueryCircle(center f32.Vec2, radius float32, filter TagFilter) []engi.Entity {
+	c := triggerShape{TriggerComp: &TriggerComp{shape: TriggerCircle}, center: center, radius: radius}
+	c.bounds = gfx.NewAABB(center[0]-radius, center[1]-radius, radius*2, radius*2)
+	return ts.query(filter, func(s *triggerShape) bool {
+		return overlapShapes(&c, s)
+	})
+}
+
+// Raycast returns the triggers hit by the segment from -> to, sorted
+// by distance.
+func (ts *TriggerSystem) Raycast(from, to f32.Vec2, filter TagFilter) (hits []RayHit) {
+	if ts.tt == nil {
+		return
+	}
+	d := to.Sub(from)
+	comps := ts.tt.comps[:ts.tt.index]
+	for i := range comps {
+		tc := &comps[i]
+		if !tc.enabled || !filter.match(ts.gt, tc.Entity) {
+			continue
+		}
+		s := ts.shape(tc)
+		var (
+			t  float32
+			ok bool
+		)
+		if s.circle() {
+			t, ok = rayCircle(from, d, s.center, s.radius)
+		} else {
+			t, ok = rayRect(from, d, &s.bounds)
+		}
+		if ok {
+			hits = append(hits, RayHit{tc.Entity, from.Add(d.Mul(t)), t})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		return hits[i].Fraction < hits[j].Fraction
+	})
+	return
+}
+
+func (ts *TriggerSystem) query(filter TagFilter, test func(s *triggerShape) bool) (list []engi.Entity) {
+	if ts.tt == nil {
+		return
+	}
+	comps := ts.tt.comps[:ts.tt.index]
+	for i := range comps {
+		tc := &comps[i]
+		if !tc.enabled || !filter.match(ts.gt, tc.Entity) {
+			continue
+		}
+		if s := ts.shape(tc); test(&s) {
+			list = append(list, tc.Entity)
+		}
+	}
+	return
+}
+
+// slab test, returns the entry fraction of the segment
+func rayRect(o, d f32.Vec2, ab *gfx.AABB) (float32, bool) {
+	x, y, w, h := ab.Rect()
+	min, max := [2]float32{x, y}, [2]float32{x + w, y + h}
+	tmin, tmax := float32(0), float32(1)
+	for i := 0; i < 2; i++ {
+		if d[i] == 0 {
+			if o[i] < min[i] || o[i] > max[i] {
+				return 0, false
+			}
+			continue
+		}
+		t1, t2 := (min[i]-o[i])/d[i], (max[i]-o[i])/d[i]
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		tmin, tmax = max32(tmin, t1), min32(tmax, t2)
+		if tmin > tmax {
+			return 0, false
+		}
+	}
+	return tmin, true
+}
+
+func rayCircle(o, d, c f32.Vec2, r float32) (float32, bool) {
+	m := o.Sub(c)
+	// starts inside
+	if m.Dot(m) <= r*r {
+		return 0, true
+	}
+	a, b := d.Dot(d), m.Dot(d)
+	if a == 0 || b > 0 {
+		return 0, false
+	}
+	disc := b*b - a*(m.Dot(m)-r*r)
+	if disc < 0 {
+		return 0, false
+	}
+	t := (-b - float32(math.Sqrt(float64(disc)))) / a
+	if t > 1 {
+		return 0, false
+	}
+	return t, true
+}
+
+func abs32(v float32) float32 {
+	return float32(math.Abs(float64(v)))
+}
+
+func min32(a, b float32) float32 {
+	return float32(math.Min(float64(a), float64(b)))
+}
+
+func max32(a, b float32) float32 {
+	return float32(math.Max(float64(a), float64(b)))
+}
+
+func clamp32(v, low, high float32) float32 {
+	return min32(max32(v, low), high)
+}
@@ -0,0 +1,98 @@
+package game
+
+import (
+	"testing"
+
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx"
+	"korok.io/korok/math/f32"
+)
+
+type triggerLog struct {
+	events []string
+}
+
+func (l *triggerLog) OnTriggerEnter(self, other engi.Entity) { l.events = append(l.events, "enter") }
+func (l *triggerLog) OnTriggerStay(self, other engi.Entity)  { l.events = append(l.events, "stay") }
+func (l *triggerLog) OnTriggerExit(self, other engi.Entity)  { l.events = append(l.events, "exit") }
+
+type coinScript struct {
+	countScript
+	triggerLog
+}
+
+func TestTriggerSystem(t *testing.T) {
+	g := &Game{}
+	g.loadTables()
+
+	var (
+		xt *gfx.TransformTable
+		tt *TriggerTable
+		gt *TagTable
+		st *ScriptTable
+	)
+	g.DB.LookupTable(&xt)
+	g.DB.LookupTable(&tt)
+	g.DB.LookupTable(&gt)
+	g.DB.LookupTable(&st)
+	ts := NewTriggerSystem()
+	ts.RequireTable(g.DB.Tables)
+
+	em := g.DB.EntityM
+	player, coin, wall := em.New(), em.New(), em.New()
+	gt.NewComp(player).Name = "player"
+	gt.NewComp(wall).Name = "wall"
+
+	xt.NewComp(player).SetPosition(f32.Vec2{0, 0})
+	tt.NewComp(player).SetRect(20, 20)
+
+	xt.NewComp(coin).SetPosition(f32.Vec2{100, 0})
+	ct := tt.NewComp(coin)
+	ct.SetCircle(10)
+	ct.SetFilter(TagFilter{Name: "player"})
+	script := &coinScript{}
+	st.NewComp(coin, script)
+
+	// wall overlaps the coin, but it's filtered
+	xt.NewComp(wall).SetPosition(f32.Vec2{100, 15})
+	tt.NewComp(wall).SetRect(100, 10)
+
+	for _, x := range []float32{0, 95, 100, 200} {
+		xt.Comp(player).SetPosition(f32.Vec2{x, 0})
+		ts.Update(0)
+	}
+	expected := []string{"enter", "stay", "exit"}
+	if len(script.events) != len(expected) {
+		t.Fatal("wrong events:", script.events)
+	}
+	for i, v := range expected {
+		if script.events[i] != v {
+			t.Fatal("wrong events:", script.events)
+		}
+	}
+
+	// queries
+	if list := ts.QueryPoint(f32.Vec2{100, 0}, TagFilter{}); len(list) != 1 || list[0] != coin {
+		t.Error("query point:", list)
+	}
+	if list := ts.QueryPoint(f32.Vec2{100, 15}, TagFilter{}); len(list) != 1 || list[0] != wall {
+		t.Error("query point on wall:", list)
+	}
+	if list := ts.QueryRect(gfx.NewAABB(150, -50, 100, 100), TagFilter{Name: "player"}); len(list) != 1 || list[0] != player {
+		t.Error("query rect:", list)
+	}
+	if list := ts.QueryCircle(f32.Vec2{100, -15}, 6, TagFilter{}); len(list) != 1 || list[0] != coin {
+		t.Error("query circle:", list)
+	}
+
+	hits := ts.Raycast(f32.Vec2{0, 0}, f32.Vec2{300, 0}, TagFilter{})
+	if len(hits) != 2 || hits[0].Entity != coin || hits[1].Entity != player {
+		t.Fatal("raycast:", hits)
+	}
+	if p := hits[0].Point; p[0] < 89.99 || p[0] > 90.01 {
+		t.Error("raycast hit point, expected 90, got:", p)
+	}
+	if hits := ts.Raycast(f32.Vec2{0, 0}, f32.Vec2{300, 0}, TagFilter{Name: "wall"}); len(hits) != 0 {
+		t.Error("raycast should miss the wall:", hits)
+	}
+}
@@ -212,6 +212,28 @@ func (tm *TexManager) Atlas(name string) (at *Atlas) {
 	return
 }
 
+// Name returns the atlas name and sub-texture name of the SubTex,
+// the sub-texture name may be empty(see LoadAtlasIndexed).
+func (tm *TexManager) Name(tex SubTex) (atlas, name string, ok bool) {
+	ai, ii := tex.Id()
+	for k, v := range tm.names {
+		if v == ai {
+			atlas, ok = k, true
+			break
+		}
+	}
+	if !ok {
+		return
+	}
+	for k, v := range tm.atlases[ai].names {
+		if v == ii {
+			name = k
+			break
+		}
+	}
+	return
+}
+
 // Region returns sub-texture's Region by id.
 func (tm *TexManager) region(id uint32) (rg Region) {
 	var (
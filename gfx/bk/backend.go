@@ -0,0 +1,88 @@
+package bk
+
+import (
+	"image"
+	"unsafe"
+)
+
+// Backend executes the bk-api on a concrete graphics driver. Resources
+// managed by ResManager are created through it, and the sorted draw-call
+// list is handed to it when the RenderQueue is flushed.
+//
+// The default backend is OpenGL(RenderContext). A different backend(see
+// Recorder) can be installed with SetBackend before Init is called, this
+// makes it possible to run the render pipeline on machines without GPU.
+type Backend interface {
+	Init()
+	Shutdown()
+
+	// index-buffer, a nil data means dynamic buffer
+	CreateIndexBuffer(size uint32, data unsafe.Pointer) (id uint32, err error)
+	UpdateIndexBuffer(id uint32, offset, size uint32, data unsafe.Pointer)
+	DestroyIndexBuffer(id uint32)
+
+	// vertex-buffer, a nil data means dynamic buffer
+	CreateVertexBuffer(size uint32, data unsafe.Pointer) (id uint32, err error)
+	UpdateVertexBuffer(id uint32, offset, size uint32, data unsafe.Pointer)
+	DestroyVertexBuffer(id uint32)
+
+	// texture
+	CreateTexture(img *image.RGBA) (id uint32, err error)
+	UpdateTexture(id uint32, img *image.RGBA, xoff, yoff, w, h int32)
+	DestroyTexture(id uint32)
+
+	// shader program, uniform and attribute slot
+	CreateProgram(vsh, fsh string) (id uint32, err error)
+	UseProgram(id uint32)
+	DestroyProgram(id uint32)
+	UniformLocation(program uint32, name string) int32
+	AttribLocation(program uint32, name string) int32
+
+	// Draw executes the sorted draw-call list of a frame.
+	Draw(frame *Frame)
+
+	// Reset is called at the end of each frame.
+	Reset()
+}
+
+// Frame is all the data flushed by the RenderQueue in one frame.
+type Frame struct {
+	// draw-call list and the sorted order
+	SortKeys   []uint64
+	SortValues []uint16
+	DrawList   []RenderDraw
+
+	// clips rect, index-0 is a default zero-rect.
+	Clips []Rect
+
+	// uniform data referenced by RenderDraw's uniform range
+	Uniforms *UniformBuffer
+
+	// resource the draw-call refers to
+	R *ResManager
+
+	// frame-buffer size in pixel
+	Width, Height uint16
+
+	// per-view state, indexed by view id(the Layer of SortKey).
+	// a zero viewport means the whole frame-buffer.
+	Viewports  [4]Rect
+	Scissors   [4]Rect
+	Clears     [4]ViewClear
+	Transforms [4]ViewTransform
+}
+
+// SetBackend replaces the graphics backend used by the bk-api. It should
+// be called before Init, resources allocated with the old backend will
+// not be migrated.
+func SetBackend(b Backend) {
+	gBackend = b
+	gRenderQ.ctx = b
+}
+
+// CurrentBackend returns the backend used by the bk-api.
+func CurrentBackend() Backend {
+	return gBackend
+}
+
+var gBackend Backend
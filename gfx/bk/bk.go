@@ -143,4 +143,5 @@ func init() {
 	R = NewResManager()
 	// after res-manager!
 	gRenderQ = NewRenderQueue(R)
+	gBackend = gRenderQ.ctx
 }
@@ -1,11 +1,8 @@
 package bk
 
 import (
-	"korok.io/korok/hid/gl"
-
 	"log"
 	"unsafe"
-	"errors"
 )
 
 type IndexBuffer struct {
@@ -18,20 +15,11 @@ func (ib *IndexBuffer) Create(size uint32, data unsafe.Pointer, flags uint16) er
 	ib.size = size
 	ib.flags = flags
 
-	gl.GenBuffers(1, &ib.Id)
-
-	if 0 == ib.Id {
-		return errors.New("failed to generate buffer id")
-	}
-
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ib.Id)
-	if data == nil {
-		gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, int(size), nil, gl.DYNAMIC_DRAW)
-	} else {
-		gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, int(size), data, gl.STATIC_DRAW)
+	id, err := gBackend.CreateIndexBuffer(size, data)
+	if err != nil {
+		return err
 	}
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, 0)
-
+	ib.Id = id
 	return nil
 }
 
@@ -47,19 +35,15 @@ func (ib *IndexBuffer) Update(offset uint32, size uint32, data unsafe.Pointer, d
 		ib.Create(ib.size, nil, ib.flags)
 	}
 
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ib.Id)
-	gl.BufferSubData(gl.ELEMENT_ARRAY_BUFFER, int(offset), int(size), data)
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, 0)
+	gBackend.UpdateIndexBuffer(ib.Id, offset, size, data)
 }
 
 func (ib *IndexBuffer) Destroy() {
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, 0)
-	gl.DeleteBuffers(1, &ib.Id)
+	gBackend.DestroyIndexBuffer(ib.Id)
 }
 
 type VertexBuffer struct {
 	Id     uint32
-	target uint32
 	size   uint32
 	layout uint16 // Stride | Offset
 }
@@ -68,19 +52,12 @@ type VertexBuffer struct {
 func (vb *VertexBuffer) Create(size uint32, data unsafe.Pointer, layout uint16, flags uint16) error{
 	vb.size = size
 	vb.layout = layout
-	vb.target = gl.ARRAY_BUFFER
 
-	gl.GenBuffers(1, &vb.Id)
-	if vb.Id == 0 {
-		return errors.New("failed to generate buffer id")
-	}
-	gl.BindBuffer(vb.target, vb.Id)
-	if data == nil {
-		gl.BufferData(vb.target, int(size), data, gl.DYNAMIC_DRAW)
-	} else {
-		gl.BufferData(vb.target, int(size), data, gl.STATIC_DRAW)
+	id, err := gBackend.CreateVertexBuffer(size, data)
+	if err != nil {
+		return err
 	}
-	gl.BindBuffer(vb.target, 0)
+	vb.Id = id
 	return nil
 }
 
@@ -95,12 +72,9 @@ func (vb *VertexBuffer) Update(offset uint32, size uint32, data unsafe.Pointer,
 		vb.Create(vb.size, nil, vb.layout, 0)
 	}
 
-	gl.BindBuffer(vb.target, vb.Id)
-	gl.BufferSubData(vb.target, int(offset), int(size), data)
-	gl.BindBuffer(vb.target, 0)
+	gBackend.UpdateVertexBuffer(vb.Id, offset, size, data)
 }
 
 func (vb *VertexBuffer) Destroy() {
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-	gl.DeleteBuffers(1, &vb.Id)
+	gBackend.DestroyVertexBuffer(vb.Id)
 }
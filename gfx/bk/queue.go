@@ -30,6 +30,31 @@ func (r *Rect) isZero() bool {
 	return *u64 == 0
 }
 
+// clear flags of view
+const (
+	ClearNone    uint16 = 0
+	ClearColor   uint16 = 1 << 0
+	ClearDepth   uint16 = 1 << 1
+	ClearStencil uint16 = 1 << 2
+)
+
+// ViewClear is the clear state of a view, rgba is in 0xRRGGBBAA format.
+type ViewClear struct {
+	Flags   uint16
+	Rgba    uint32
+	Depth   float32
+	Stencil uint8
+}
+
+// ViewTransform is the view and projection matrices of a view.
+type ViewTransform struct {
+	View, Proj f32.Mat4
+	Flags      uint8
+
+	// false if SetViewTransform is never called
+	Valid bool
+}
+
 type Stream struct {
 	vertexBuffer uint16
 	vertexFormat uint16 // Offset | Stride， not used now!!
@@ -85,31 +110,35 @@ type RenderQueue struct {
 	uniformEnd   uint16
 
 	// per-frame state
-	viewports [4]Rect
-	scissors  [4]Rect
-	clears    [4]struct {
-		index   [8]uint8
-		rgba    uint32
-		depth   float32
-		stencil uint8
-		flags   uint16
-	}
+	viewports  [4]Rect
+	scissors   [4]Rect
+	clears     [4]ViewClear
+	transforms [4]ViewTransform
+
+	// window rect
+	wRect Rect
+	// pixel-ratio = windows-size/frame-buffer-size
+	pixelRatio float32
+
+	// clips rect, index-0 is a default zero-rect.
+	clips []Rect
 
 	// per-frame data flow
 	rm *ResManager
 	ub *UniformBuffer
 
-	// render context
-	ctx *RenderContext
+	// render backend
+	ctx Backend
 }
 
 func NewRenderQueue(m *ResManager) *RenderQueue {
 	ub := NewUniformBuffer()
 	rc := NewRenderContext(m, ub)
 	return &RenderQueue{
-		ctx: rc,
-		rm:  m,
-		ub:  ub,
+		ctx:   rc,
+		rm:    m,
+		ub:    ub,
+		clips: make([]Rect, 1),
 	}
 }
 
@@ -119,13 +148,24 @@ func (rq *RenderQueue) Init() {
 
 // reset frame-buffer size
 func (rq *RenderQueue) Reset(w, h uint16, pr float32) {
-	rq.ctx.wRect.w = w
-	rq.ctx.wRect.h = h
-	rq.ctx.pixelRatio = pr
+	rq.wRect.w = w
+	rq.wRect.h = h
+	rq.pixelRatio = pr
 }
 
 func (rq *RenderQueue) Destroy() {
-	//
+	rq.ctx.Shutdown()
+}
+
+func (rq *RenderQueue) addClipRect(x, y, w, h uint16) uint16 {
+	index := uint16(len(rq.clips))
+	ratio := rq.pixelRatio
+	rq.clips = append(rq.clips, Rect{
+		uint16(float32(x) * ratio),
+		uint16(float32(y) * ratio),
+		uint16(float32(w) * ratio),
+		uint16(float32(h) * ratio)})
+	return index
 }
 
 func (rq *RenderQueue) SetState(state uint64, rgba uint32) {
@@ -179,7 +219,7 @@ func (rq *RenderQueue) SetStencil(stencil uint32) {
 }
 
 func (rq *RenderQueue) SetScissor(x, y, width, height uint16) (id uint16) {
-	id = rq.ctx.AddClipRect(x, y, width, height)
+	id = rq.addClipRect(x, y, width, height)
 	rq.drawCall.scissor = id
 	return id
 }
@@ -210,18 +250,28 @@ func (rq *RenderQueue) SetViewClear(id uint8, flags uint16, rgba uint32, depth f
 		log.Printf("Not support view id: %d", id)
 		return
 	}
-	clear := &rq.clears[id]
-	clear.flags = flags
-	clear.rgba = rgba
-	clear.depth = depth
-	clear.stencil = stencil
+	rq.clears[id] = ViewClear{flags, rgba, depth, stencil}
 }
 
 func (rq *RenderQueue) SetViewTransform(id uint8, view, proj *f32.Mat4, flags uint8) {
-
+	if id < 0 || id >= 4 {
+		log.Printf("Not support view id: %d", id)
+		return
+	}
+	xf := &rq.transforms[id]
+	xf.View, xf.Proj = f32.Ident4(), f32.Ident4()
+	if view != nil {
+		xf.View = *view
+	}
+	if proj != nil {
+		xf.Proj = *proj
+	}
+	xf.Flags = flags
+	xf.Valid = true
 }
 
-// conversion: depth range [-int16, int16]
+// conversion: depth range [-512, 511], the z-order of SortKey has only 10 bits,
+// depth out of range is clamped, or it will overflow to the Layer bits.
 func (rq *RenderQueue) Submit(id uint8, program uint16, depth int32) uint32 {
 	// uniform range
 	rq.uniformEnd = uint16(rq.ub.GetPos())
@@ -229,7 +279,12 @@ func (rq *RenderQueue) Submit(id uint8, program uint16, depth int32) uint32 {
 	// encode sort-key
 	sk := &rq.sk
 	sk.Layer = uint16(id)
-	sk.Order = uint16(depth+0xFFFF>>1)
+	if depth < -512 {
+		depth = -512
+	} else if depth > 511 {
+		depth = 511
+	}
+	sk.Order = uint16(depth + 512)
 
 	sk.Shader = program & IdMask // trip type
 	sk.Blend = 0
@@ -272,13 +327,27 @@ func (rq *RenderQueue) Flush() int {
 	}
 
 	// Draw respect to sorted values
-	rq.ctx.Draw(sortKeys, sortVals, drawList)
+	rq.ctx.Draw(&Frame{
+		SortKeys:   sortKeys,
+		SortValues: sortVals,
+		DrawList:   drawList,
+		Clips:      rq.clips,
+		Uniforms:   rq.ub,
+		R:          rq.rm,
+		Width:      uint16(float32(rq.wRect.w) * rq.pixelRatio),
+		Height:     uint16(float32(rq.wRect.h) * rq.pixelRatio),
+		Viewports:  rq.viewports,
+		Scissors:   rq.scissors,
+		Clears:     rq.clears,
+		Transforms: rq.transforms,
+	})
 
 	// Clear counter
 	rq.drawCallNum = 0
 	rq.uniformBegin = 0
 	rq.uniformEnd = 0
 	rq.ub.Reset()
+	rq.clips = rq.clips[:1]
 	rq.ctx.Reset()
 
 	return int(num)
@@ -0,0 +1,644 @@
+package bk
+
+import (
+	"image"
+	"math"
+	"unsafe"
+
+	"korok.io/korok/math/f32"
+)
+
+// Rasterizer is a software Backend, it rasterizes the draw-call list of a
+// frame on CPU and outputs an image.RGBA per view. It's slow, but the output
+// is the same on every machine, so it can be used for screenshot regression
+// tests on machines without OpenGL:
+//
+//	ras := bk.NewRasterizer()
+//	bk.SetBackend(ras)
+//	bk.Init()
+//	... submit and flush
+//	img := ras.Image(0)
+//
+// Rasterizer doesn't run the shader, it emulates the pipeline of korok's
+// shaders instead:
+//   - position and uv are read from the `xyuv` attribute, color from the
+//     `rgba` attribute
+//   - position is transformed by the `proj`(or `projection`) and `model`
+//     uniform, the view transform is used if the program has no projection
+//   - fragment color is texture(tex, uv) * color, a draw-call without
+//     texture(or uv.x == 2, as dbg does) uses the color only
+//   - blend, scissor and viewport are applied as OpenGL does
+//
+// Only triangle list and triangle strip are supported. The output image
+// is top-down as a screenshot, not the bottom-up of OpenGL.
+type Rasterizer struct {
+	// use nearest texture filter instead of bilinear
+	Nearest bool
+
+	// output of the last frame
+	views [4]*image.RGBA
+
+	// resource id
+	nextId uint32
+
+	indexBuffers  map[uint32][]byte
+	vertexBuffers map[uint32][]byte
+	textures      map[uint32]*image.RGBA
+
+	// uniform and attribute slot per program
+	uniforms map[uint32][]string
+	attribs  map[uint32][]string
+
+	// uniform value per program, as OpenGL, uniform is a program state
+	values map[uint32]map[uint8][]byte
+}
+
+func NewRasterizer() *Rasterizer {
+	return &Rasterizer{
+		indexBuffers:  make(map[uint32][]byte),
+		vertexBuffers: make(map[uint32][]byte),
+		textures:      make(map[uint32]*image.RGBA),
+		uniforms:      make(map[uint32][]string),
+		attribs:       make(map[uint32][]string),
+		values:        make(map[uint32]map[uint8][]byte),
+	}
+}
+
+// Image returns the rendered image of the view in the last frame. It
+// returns nil if nothing is drawn to the view and the view is not cleared.
+func (r *Rasterizer) Image(view uint8) *image.RGBA {
+	if view >= 4 {
+		return nil
+	}
+	return r.views[view]
+}
+
+func (r *Rasterizer) Init() {
+}
+
+func (r *Rasterizer) Shutdown() {
+}
+
+func (r *Rasterizer) Reset() {
+}
+
+func (r *Rasterizer) Draw(frame *Frame) {
+	r.views = [4]*image.RGBA{}
+	for id := range frame.Clears {
+		if frame.Clears[id].Flags&ClearColor != 0 {
+			r.target(frame, uint8(id))
+		}
+	}
+
+	var key SortKey
+	for i, encodedKey := range frame.SortKeys {
+		draw := &frame.DrawList[frame.SortValues[i]]
+		key.Decode(encodedKey)
+
+		ok, sh := frame.R.Shader(key.Shader | IdTypeShader<<IdTypeShift)
+		if !ok {
+			continue
+		}
+
+		// uniform is applied even if there is nothing to draw
+		if draw.uniformBegin < draw.uniformEnd {
+			r.applyUniform(frame.Uniforms, sh.Program, uint32(draw.uniformBegin), uint32(draw.uniformEnd))
+		}
+		if draw.num == 0 || key.Layer >= 4 {
+			continue
+		}
+		r.drawTriangles(frame, uint8(key.Layer), sh, draw)
+	}
+}
+
+// target returns the image of the view, the image is created and cleared
+// at the first time it's used in a frame.
+func (r *Rasterizer) target(frame *Frame, view uint8) *image.RGBA {
+	if img := r.views[view]; img != nil {
+		return img
+	}
+	vp := r.viewport(frame, view)
+	img := image.NewRGBA(image.Rect(0, 0, int(vp.w), int(vp.h)))
+	if clear := frame.Clears[view]; clear.Flags&ClearColor != 0 {
+		c := [4]uint8{uint8(clear.Rgba >> 24), uint8(clear.Rgba >> 16), uint8(clear.Rgba >> 8), uint8(clear.Rgba)}
+		for i := 0; i < len(img.Pix); i += 4 {
+			copy(img.Pix[i:i+4], c[:])
+		}
+	}
+	r.views[view] = img
+	return img
+}
+
+func (r *Rasterizer) viewport(frame *Frame, view uint8) Rect {
+	if vp := frame.Viewports[view]; !vp.isZero() {
+		return vp
+	}
+	return Rect{0, 0, frame.Width, frame.Height}
+}
+
+func (r *Rasterizer) applyUniform(ub *UniformBuffer, program uint32, begin, end uint32) {
+	values := r.values[program]
+	if values == nil {
+		values = make(map[uint8][]byte)
+		r.values[program] = values
+	}
+
+	ub.Seek(begin)
+	for ub.GetPos() < end {
+		opcode := ub.ReadUInt32()
+		if opcode == uint32(UniformEnd) {
+			break
+		}
+
+		var uType, loc, size, num uint8
+		Uniform_decode(opcode, &uType, &loc, &size, &num)
+		n := uint32(size) * uint32(num)
+		data := make([]byte, n)
+		copy(data, (*[UNIFORM_BUFFER_SIZE]byte)(ub.ReadPointer(n))[:n])
+		values[loc] = data
+	}
+}
+
+// uniformMat4 returns the value of the first mat4 uniform found in names.
+func (r *Rasterizer) uniformMat4(program uint32, names ...string) (m f32.Mat4, ok bool) {
+	slots := r.uniforms[program]
+	for _, name := range names {
+		for loc, v := range slots {
+			if v != name {
+				continue
+			}
+			if data := r.values[program][uint8(loc)]; len(data) >= 64 {
+				return *(*f32.Mat4)(unsafe.Pointer(&data[0])), true
+			}
+		}
+	}
+	return
+}
+
+// vertex after transformed, in image space
+type rasterVertex struct {
+	x, y float32
+	u, v float32
+	c    [4]float32
+}
+
+func (r *Rasterizer) drawTriangles(frame *Frame, view uint8, sh *Shader, draw *RenderDraw) {
+	var (
+		program = sh.Program
+		stream  = draw.vertexBuffers[0]
+		vb      = &frame.R.vertexBuffers[stream.vertexBuffer]
+		data    = r.vertexBuffers[vb.Id]
+		stride  = int(vb.layout)
+	)
+	if stride == 0 || data == nil {
+		return
+	}
+
+	// find the attribute of position and color
+	var pos, color *AttribBind
+	names := r.attribs[program]
+	for i := uint32(0); i < sh.numAttr; i++ {
+		bind := &sh.AttrBinds[i]
+		var name string
+		if int(bind.slot) < len(names) {
+			name = names[bind.slot]
+		}
+		switch {
+		case name == "rgba" || name == "color":
+			color = bind
+		case pos == nil:
+			pos = bind
+		}
+	}
+	if pos == nil {
+		return
+	}
+
+	// transform: proj * model
+	mvp, ok := r.uniformMat4(program, "proj", "projection")
+	if !ok {
+		mvp = f32.Ident4()
+		if xf := frame.Transforms[view]; xf.Valid {
+			mvp = mulMat4(&xf.Proj, &xf.View)
+		}
+	}
+	if model, ok := r.uniformMat4(program, "model"); ok {
+		mvp = mulMat4(&mvp, &model)
+	}
+
+	// vertex index
+	var indices []uint16
+	if ib := draw.indexBuffer; ib != InvalidId {
+		buf := r.indexBuffers[frame.R.indexBuffers[ib].Id]
+		first, last := int(draw.firstIndex)*2, (int(draw.firstIndex)+int(draw.num))*2
+		if last > len(buf) {
+			return
+		}
+		indices = make([]uint16, draw.num)
+		for i := range indices {
+			indices[i] = uint16(buf[first+i*2]) | uint16(buf[first+i*2+1])<<8
+		}
+	} else {
+		indices = make([]uint16, draw.num)
+		for i := range indices {
+			indices[i] = draw.firstIndex + uint16(i)
+		}
+	}
+
+	var (
+		img = r.target(frame, view)
+		vp  = r.viewport(frame, view)
+		w   = float32(vp.w)
+		h   = float32(vp.h)
+	)
+	vertex := func(index uint16) (v rasterVertex, ok bool) {
+		base := (int(stream.firstVertex) + int(index)) * stride
+		if base+stride > len(data) {
+			return
+		}
+		p := readAttrib(data[base:base+stride], pos.comp)
+		c := [4]float32{1, 1, 1, 1}
+		if color != nil {
+			c = readAttrib(data[base:base+stride], color.comp)
+		}
+		x, y, _, cw := transformVec4(&mvp, p[0], p[1], 1, 1)
+		if cw == 0 {
+			return
+		}
+		v.x = (x/cw + 1) / 2 * w
+		v.y = (1 - y/cw) / 2 * h
+		v.u, v.v = p[2], p[3]
+		v.c = c
+		return v, true
+	}
+
+	// texture
+	var tex *image.RGBA
+	if t := draw.textures[0]; t != InvalidId {
+		tex = r.textures[frame.R.textures[t].Id]
+	}
+
+	// clip: bottom-left origin in frame-buffer -> top-left origin in image
+	clip := image.Rect(0, 0, int(vp.w), int(vp.h))
+	for _, rect := range []Rect{frame.Scissors[view], frame.Clips[draw.scissor]} {
+		if rect.isZero() {
+			continue
+		}
+		x0 := int(rect.x) - int(vp.x)
+		y1 := int(vp.h) - (int(rect.y) - int(vp.y))
+		clip = clip.Intersect(image.Rect(x0, y1-int(rect.h), x0+int(rect.w), y1))
+	}
+
+	blend := uint8((draw.state & ST.BLEND_MASK) >> ST.BLEND_SHIFT)
+	strip := draw.state&ST.PT_MASK == ST_PT.TRIANGLE_STRIP
+	if pt := draw.state & ST.PT_MASK; pt != ST_PT.TRIANGLES && !strip {
+		return
+	}
+
+	for i := 0; i+2 < len(indices); {
+		var ok0, ok1, ok2 bool
+		var tri [3]rasterVertex
+		tri[0], ok0 = vertex(indices[i])
+		tri[1], ok1 = vertex(indices[i+1])
+		tri[2], ok2 = vertex(indices[i+2])
+		if ok0 && ok1 && ok2 {
+			r.fillTriangle(img, clip, &tri, tex, blend)
+		}
+		if strip {
+			i++
+		} else {
+			i += 3
+		}
+	}
+}
+
+// fillTriangle rasterizes the triangle with top-left fill rule, so pixels
+// on the shared edge of two triangles are drawn only once.
+func (r *Rasterizer) fillTriangle(img *image.RGBA, clip image.Rectangle, tri *[3]rasterVertex, tex *image.RGBA, blend uint8) {
+	v0, v1, v2 := &tri[0], &tri[1], &tri[2]
+	area := edge(v0, v1, v2.x, v2.y)
+	if area == 0 {
+		return
+	}
+	if area < 0 {
+		v1, v2 = v2, v1
+		area = -area
+	}
+
+	minX := int(math.Floor(float64(min3(v0.x, v1.x, v2.x))))
+	minY := int(math.Floor(float64(min3(v0.y, v1.y, v2.y))))
+	maxX := int(math.Ceil(float64(max3(v0.x, v1.x, v2.x))))
+	maxY := int(math.Ceil(float64(max3(v0.y, v1.y, v2.y))))
+	bound := image.Rect(minX, minY, maxX, maxY).Intersect(clip)
+
+	tl0, tl1, tl2 := topLeft(v1, v2), topLeft(v2, v0), topLeft(v0, v1)
+
+	for y := bound.Min.Y; y < bound.Max.Y; y++ {
+		py := float32(y) + .5
+		for x := bound.Min.X; x < bound.Max.X; x++ {
+			px := float32(x) + .5
+			w0, w1, w2 := edge(v1, v2, px, py), edge(v2, v0, px, py), edge(v0, v1, px, py)
+			if !inside(w0, tl0) || !inside(w1, tl1) || !inside(w2, tl2) {
+				continue
+			}
+			w0, w1, w2 = w0/area, w1/area, w2/area
+
+			var src [4]float32
+			for k := range src {
+				src[k] = w0*v0.c[k] + w1*v1.c[k] + w2*v2.c[k]
+			}
+			u := w0*v0.u + w1*v1.u + w2*v2.u
+			v := w0*v0.v + w1*v1.v + w2*v2.v
+			if tex != nil && v0.u != 2 {
+				t := r.sample(tex, u, v)
+				for k := range src {
+					src[k] *= t[k]
+				}
+			}
+
+			off := img.PixOffset(x, y)
+			writePixel(img.Pix[off:off+4], src, blend)
+		}
+	}
+}
+
+// sample the texture with clamp-to-edge wrap mode.
+func (r *Rasterizer) sample(tex *image.RGBA, u, v float32) (c [4]float32) {
+	w, h := tex.Rect.Dx(), tex.Rect.Dy()
+	if w == 0 || h == 0 {
+		return
+	}
+	texel := func(x, y int) (c [4]float32) {
+		x, y = clampInt(x, 0, w-1), clampInt(y, 0, h-1)
+		off := y*tex.Stride + x*4
+		for k := range c {
+			c[k] = float32(tex.Pix[off+k]) / 255
+		}
+		return
+	}
+
+	fx, fy := u*float32(w), v*float32(h)
+	if r.Nearest {
+		return texel(int(math.Floor(float64(fx))), int(math.Floor(float64(fy))))
+	}
+
+	fx, fy = fx-.5, fy-.5
+	x0, y0 := int(math.Floor(float64(fx))), int(math.Floor(float64(fy)))
+	ax, ay := fx-float32(x0), fy-float32(y0)
+	c00, c10 := texel(x0, y0), texel(x0+1, y0)
+	c01, c11 := texel(x0, y0+1), texel(x0+1, y0+1)
+	for k := range c {
+		top := c00[k]*(1-ax) + c10[k]*ax
+		bottom := c01[k]*(1-ax) + c11[k]*ax
+		c[k] = top*(1-ay) + bottom*ay
+	}
+	return
+}
+
+// writePixel blends src with the pixel, blend is the index of g_Blend.
+func writePixel(pix []uint8, src [4]float32, blend uint8) {
+	var dst [4]float32
+	for k := range dst {
+		dst[k] = float32(pix[k]) / 255
+	}
+	sa := src[3]
+	for k := range src {
+		var c float32
+		switch blend {
+		case 2: // ONE, ONE_MINUS_SRC_ALPHA
+			c = src[k] + dst[k]*(1-sa)
+		case 3: // SRC_ALPHA, ONE_MINUS_SRC_ALPHA
+			c = src[k]*sa + dst[k]*(1-sa)
+		case 4: // SRC_ALPHA, ONE
+			c = src[k]*sa + dst[k]
+		default: // disabled or ONE, ZERO
+			c = src[k]
+		}
+		pix[k] = uint8(clampFloat(c, 0, 1)*255 + .5)
+	}
+}
+
+// edge function, > 0 if (x, y) is on the right side of a->b in image space.
+func edge(a, b *rasterVertex, x, y float32) float32 {
+	return (b.x-a.x)*(y-a.y) - (b.y-a.y)*(x-a.x)
+}
+
+func topLeft(a, b *rasterVertex) bool {
+	dx, dy := b.x-a.x, b.y-a.y
+	return (dy == 0 && dx > 0) || dy < 0
+}
+
+func inside(w float32, topLeft bool) bool {
+	return w > 0 || (w == 0 && topLeft)
+}
+
+// readAttrib reads a vertex attribute, missing component is (0, 0, 0, 1).
+func readAttrib(data []byte, comp VertexComp) (v [4]float32) {
+	v[3] = 1
+	off := int(comp.Offset)
+	norm := comp.Normalized&0x01 != 0
+	for i := 0; i < int(comp.Num) && i < 4; i++ {
+		switch comp.Type {
+		case AttrFloat:
+			if off+4 <= len(data) {
+				v[i] = *(*float32)(unsafe.Pointer(&data[off]))
+			}
+			off += 4
+		case AttrUInt8:
+			if off < len(data) {
+				v[i] = float32(data[off])
+				if norm {
+					v[i] /= 255
+				}
+			}
+			off += 1
+		case AttrInt8:
+			if off < len(data) {
+				v[i] = float32(int8(data[off]))
+				if norm {
+					v[i] /= 127
+				}
+			}
+			off += 1
+		case AttrUInt16:
+			if off+2 <= len(data) {
+				v[i] = float32(uint16(data[off]) | uint16(data[off+1])<<8)
+				if norm {
+					v[i] /= 65535
+				}
+			}
+			off += 2
+		case AttrInt16:
+			if off+2 <= len(data) {
+				v[i] = float32(int16(uint16(data[off]) | uint16(data[off+1])<<8))
+				if norm {
+					v[i] /= 32767
+				}
+			}
+			off += 2
+		}
+	}
+	return
+}
+
+// column-major matrix multiply, a * b
+func mulMat4(a, b *f32.Mat4) (m f32.Mat4) {
+	for col := 0; col < 4; col++ {
+		for row := 0; row < 4; row++ {
+			var sum float32
+			for k := 0; k < 4; k++ {
+				sum += a[k*4+row] * b[col*4+k]
+			}
+			m[col*4+row] = sum
+		}
+	}
+	return
+}
+
+func transformVec4(m *f32.Mat4, x, y, z, w float32) (x1, y1, z1, w1 float32) {
+	x1 = m[0]*x + m[4]*y + m[8]*z + m[12]*w
+	y1 = m[1]*x + m[5]*y + m[9]*z + m[13]*w
+	z1 = m[2]*x + m[6]*y + m[10]*z + m[14]*w
+	w1 = m[3]*x + m[7]*y + m[11]*z + m[15]*w
+	return
+}
+
+func min3(a, b, c float32) float32 {
+	return float32(math.Min(float64(a), math.Min(float64(b), float64(c))))
+}
+
+func max3(a, b, c float32) float32 {
+	return float32(math.Max(float64(a), math.Max(float64(b), float64(c))))
+}
+
+func clampInt(v, low, high int) int {
+	if v < low {
+		return low
+	}
+	if v > high {
+		return high
+	}
+	return v
+}
+
+func clampFloat(v, low, high float32) float32 {
+	if v < low {
+		return low
+	}
+	if v > high {
+		return high
+	}
+	return v
+}
+
+/// software resource, data is copied to main memory
+
+func (r *Rasterizer) newId() uint32 {
+	r.nextId++
+	return r.nextId
+}
+
+func (r *Rasterizer) CreateIndexBuffer(size uint32, data unsafe.Pointer) (id uint32, err error) {
+	id = r.newId()
+	r.indexBuffers[id] = newBuffer(size, data)
+	return
+}
+
+func (r *Rasterizer) UpdateIndexBuffer(id uint32, offset, size uint32, data unsafe.Pointer) {
+	updateBuffer(r.indexBuffers[id], offset, size, data)
+}
+
+func (r *Rasterizer) DestroyIndexBuffer(id uint32) {
+	delete(r.indexBuffers, id)
+}
+
+func (r *Rasterizer) CreateVertexBuffer(size uint32, data unsafe.Pointer) (id uint32, err error) {
+	id = r.newId()
+	r.vertexBuffers[id] = newBuffer(size, data)
+	return
+}
+
+func (r *Rasterizer) UpdateVertexBuffer(id uint32, offset, size uint32, data unsafe.Pointer) {
+	updateBuffer(r.vertexBuffers[id], offset, size, data)
+}
+
+func (r *Rasterizer) DestroyVertexBuffer(id uint32) {
+	delete(r.vertexBuffers, id)
+}
+
+func newBuffer(size uint32, data unsafe.Pointer) []byte {
+	buf := make([]byte, size)
+	if data != nil {
+		copy(buf, (*[1 << 30]byte)(data)[:size:size])
+	}
+	return buf
+}
+
+func updateBuffer(buf []byte, offset, size uint32, data unsafe.Pointer) {
+	if data == nil || offset >= uint32(len(buf)) {
+		return
+	}
+	copy(buf[offset:], (*[1 << 30]byte)(data)[:size:size])
+}
+
+func (r *Rasterizer) CreateTexture(img *image.RGBA) (id uint32, err error) {
+	id = r.newId()
+	tex := image.NewRGBA(image.Rect(0, 0, img.Rect.Dx(), img.Rect.Dy()))
+	for y := 0; y < tex.Rect.Dy(); y++ {
+		copy(tex.Pix[y*tex.Stride:(y+1)*tex.Stride], img.Pix[y*img.Stride:])
+	}
+	r.textures[id] = tex
+	return
+}
+
+// UpdateTexture copies w*h pixels from the beginning of img, same as
+// glTexSubImage2D does. The pixels out of the texture are dropped.
+func (r *Rasterizer) UpdateTexture(id uint32, img *image.RGBA, xoff, yoff, w, h int32) {
+	tex := r.textures[id]
+	if tex == nil || int(xoff) >= tex.Rect.Dx() {
+		return
+	}
+	for y := int32(0); y < h; y++ {
+		if int(yoff+y) >= tex.Rect.Dy() || int(y)*img.Stride >= len(img.Pix) {
+			break
+		}
+		dst := tex.Pix[tex.PixOffset(int(xoff), int(yoff+y)):]
+		src := img.Pix[int(y)*img.Stride:]
+		n := int(w) * 4
+		if max := (tex.Rect.Dx() - int(xoff)) * 4; n > max {
+			n = max
+		}
+		if n > len(src) {
+			n = len(src)
+		}
+		if n > len(dst) {
+			n = len(dst)
+		}
+		copy(dst[:n], src[:n])
+	}
+}
+
+func (r *Rasterizer) DestroyTexture(id uint32) {
+	delete(r.textures, id)
+}
+
+func (r *Rasterizer) CreateProgram(vsh, fsh string) (id uint32, err error) {
+	return r.newId(), nil
+}
+
+func (r *Rasterizer) UseProgram(id uint32) {
+}
+
+func (r *Rasterizer) DestroyProgram(id uint32) {
+	delete(r.uniforms, id)
+	delete(r.attribs, id)
+	delete(r.values, id)
+}
+
+func (r *Rasterizer) UniformLocation(program uint32, name string) int32 {
+	return location(r.uniforms, program, name)
+}
+
+func (r *Rasterizer) AttribLocation(program uint32, name string) int32 {
+	return location(r.attribs, program, name)
+}
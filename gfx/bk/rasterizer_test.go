@@ -0,0 +1,113 @@
+package bk
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"unsafe"
+
+	"korok.io/korok/math/f32"
+)
+
+type testVertex struct {
+	x, y, u, v float32
+	rgba       uint32
+}
+
+func TestRasterizer(t *testing.T) {
+	ras := NewRasterizer()
+	defer SetBackend(CurrentBackend())
+	SetBackend(ras)
+	Init()
+	Reset(8, 8, 1)
+
+	shId, sh := R.AllocShader("", "")
+	sh.AddAttributeBinding("xyuv\x00", 0, VertexComp{4, AttrFloat, 0, 0})
+	sh.AddAttributeBinding("rgba\x00", 0, VertexComp{4, AttrUInt8, 16, 1})
+	umId, _ := R.AllocUniform(shId, "proj\x00", UniformMat4, 1)
+
+	// a quad at the left-bottom corner, (0, 0, 4, 4)
+	red, white := uint32(0xFF0000FF), uint32(0xFFFFFFFF)
+	vertex := []testVertex{
+		{0, 0, 0, 0, red}, {4, 0, 1, 0, red}, {4, 4, 1, 1, red}, {0, 4, 0, 1, red},
+		// a white quad with 50% alpha texture, (4, 4, 4, 4)
+		{4, 4, 0, 0, white}, {8, 4, 1, 0, white}, {8, 8, 1, 1, white}, {4, 8, 0, 1, white},
+	}
+	index := []uint16{0, 1, 2, 0, 2, 3}
+	ibId, _ := R.AllocIndexBuffer(Memory{unsafe.Pointer(&index[0]), uint32(len(index)) * 2})
+	vbId, _ := R.AllocVertexBuffer(Memory{unsafe.Pointer(&vertex[0]), uint32(len(vertex)) * 20}, 20)
+
+	half := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	half.Set(0, 0, color.RGBA{128, 128, 128, 128})
+	texId, _ := R.AllocTexture(half)
+
+	p := f32.Ortho2D(0, 8, 0, 8)
+	SetUniform(umId, unsafe.Pointer(&p[0]))
+	Submit(0, shId, 0)
+
+	SetViewClear(0, ClearColor, 0x0000FFFF, 1, 0)
+	defer SetViewClear(0, ClearNone, 0, 1, 0)
+
+	// opaque red quad
+	SetState(ST_BLEND.ALPHA_PREMULTIPLIED, 0)
+	SetVertexBuffer(0, vbId, 0, 4)
+	SetIndexBuffer(ibId, 0, 6)
+	Submit(0, shId, 0)
+
+	// half transparent quad, scissor the right half
+	SetState(ST_BLEND.ALPHA_PREMULTIPLIED, 0)
+	SetTexture(0, 0, texId, 0)
+	SetVertexBuffer(0, vbId, 4, 4)
+	SetIndexBuffer(ibId, 0, 6)
+	SetScissor(6, 0, 2, 8)
+	Submit(0, shId, 0)
+
+	Flush()
+
+	img := ras.Image(0)
+	if img == nil || img.Rect.Dx() != 8 || img.Rect.Dy() != 8 {
+		t.Fatal("fail to rasterize view-0")
+	}
+
+	// image is top-down, the red quad is at the left-bottom corner
+	for _, c := range []struct {
+		x, y int
+		rgba color.RGBA
+	}{
+		{0, 7, color.RGBA{255, 0, 0, 255}},
+		{3, 4, color.RGBA{255, 0, 0, 255}},
+		{4, 4, color.RGBA{0, 0, 255, 255}},
+		{0, 0, color.RGBA{0, 0, 255, 255}},
+		{5, 0, color.RGBA{0, 0, 255, 255}},
+		{6, 0, color.RGBA{128, 128, 255, 255}},
+		{7, 3, color.RGBA{128, 128, 255, 255}},
+	} {
+		if got := img.RGBAAt(c.x, c.y); got != c.rgba {
+			t.Errorf("pixel (%d, %d), expected %v, got %v", c.x, c.y, c.rgba, got)
+		}
+	}
+}
+
+func TestUpdateTextureClip(t *testing.T) {
+	ras := NewRasterizer()
+	id, _ := ras.CreateTexture(image.NewRGBA(image.Rect(0, 0, 4, 2)))
+
+	// 3x2 sub-rect at x = 2, the right column is out of the texture
+	red := color.RGBA{255, 0, 0, 255}
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			img.SetRGBA(x, y, red)
+		}
+	}
+	ras.UpdateTexture(id, img, 2, 0, 3, 2)
+
+	tex := ras.textures[id]
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			if c, want := tex.RGBAAt(x, y), x >= 2; (c == red) != want {
+				t.Errorf("pixel (%d, %d): got %v", x, y, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package bk
+
+import (
+	"image"
+	"strings"
+	"unsafe"
+)
+
+// Recorder is a headless Backend. It draws nothing, but records the sorted
+// draw-call list flushed each frame, so the render pipeline can be tested
+// on machines without GPU:
+//
+//	rec := bk.NewRecorder()
+//	bk.SetBackend(rec)
+//	bk.Init()
+//	... submit and flush
+//	frame := rec.LastFrame()
+type Recorder struct {
+	// recorded frames, the oldest frame is dropped if MaxFrames > 0
+	// and the number of frames exceeds it.
+	Frames    []FrameRecord
+	MaxFrames int
+
+	// resource id
+	nextId uint32
+
+	// uniform and attribute slot per program
+	uniforms map[uint32][]string
+	attribs  map[uint32][]string
+}
+
+// FrameRecord is the draw-call list of a frame, in the order they
+// are executed.
+type FrameRecord struct {
+	Draws []DrawRecord
+}
+
+// Geometry returns the draw-calls which really draw something. Draw-calls
+// which only update uniform(like Camera's projection) are skipped.
+func (fr *FrameRecord) Geometry() (list []DrawRecord) {
+	for _, d := range fr.Draws {
+		if d.NumIndex > 0 {
+			list = append(list, d)
+		}
+	}
+	return
+}
+
+// DrawRecord is a snapshot of RenderDraw with the decoded sort-key.
+type DrawRecord struct {
+	Key SortKey
+
+	State   uint64
+	Stencil uint32
+
+	// scissor rect in pixel: x, y, w, h. zero means disabled.
+	Scissor [4]uint16
+
+	Textures [2]uint16
+
+	// vertex stream-0
+	VertexBuffer           uint16
+	FirstVertex, NumVertex uint16
+
+	// index range, if IndexBuffer is InvalidId, the range is
+	// used as vertex range.
+	IndexBuffer          uint16
+	FirstIndex, NumIndex uint16
+
+	Uniforms []UniformRecord
+}
+
+// UniformRecord is a uniform value set by a draw-call.
+type UniformRecord struct {
+	Name  string
+	Type  UniformType
+	Count uint8
+	Data  []byte
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{
+		uniforms: make(map[uint32][]string),
+		attribs:  make(map[uint32][]string),
+	}
+}
+
+// LastFrame returns the latest recorded frame.
+func (r *Recorder) LastFrame() (fr *FrameRecord) {
+	if n := len(r.Frames); n > 0 {
+		fr = &r.Frames[n-1]
+	}
+	return
+}
+
+// Clear drops all recorded frames.
+func (r *Recorder) Clear() {
+	r.Frames = r.Frames[:0]
+}
+
+func (r *Recorder) Init() {
+}
+
+func (r *Recorder) Shutdown() {
+}
+
+func (r *Recorder) Reset() {
+}
+
+func (r *Recorder) Draw(frame *Frame) {
+	fr := FrameRecord{Draws: make([]DrawRecord, len(frame.SortKeys))}
+	for i, encodedKey := range frame.SortKeys {
+		var (
+			draw = &frame.DrawList[frame.SortValues[i]]
+			dr   = &fr.Draws[i]
+		)
+		dr.Key.Decode(encodedKey)
+		dr.State = draw.state
+		dr.Stencil = draw.stencil
+		if clip := frame.Clips[draw.scissor]; !clip.isZero() {
+			dr.Scissor = [4]uint16{clip.x, clip.y, clip.w, clip.h}
+		}
+		dr.Textures = draw.textures
+		dr.VertexBuffer = draw.vertexBuffers[0].vertexBuffer
+		dr.FirstVertex = draw.vertexBuffers[0].firstVertex
+		dr.NumVertex = draw.vertexBuffers[0].numVertex
+		dr.IndexBuffer = draw.indexBuffer
+		dr.FirstIndex = draw.firstIndex
+		dr.NumIndex = draw.num
+
+		if draw.uniformBegin < draw.uniformEnd {
+			var program uint32
+			if ok, sh := frame.R.Shader(dr.Key.Shader | IdTypeShader<<IdTypeShift); ok {
+				program = sh.Program
+			}
+			dr.Uniforms = r.readUniform(frame.Uniforms, program, uint32(draw.uniformBegin), uint32(draw.uniformEnd))
+		}
+	}
+
+	r.Frames = append(r.Frames, fr)
+	if max := r.MaxFrames; max > 0 && len(r.Frames) > max {
+		r.Frames = append(r.Frames[:0], r.Frames[len(r.Frames)-max:]...)
+	}
+}
+
+func (r *Recorder) readUniform(ub *UniformBuffer, program uint32, begin, end uint32) (list []UniformRecord) {
+	ub.Seek(begin)
+	names := r.uniforms[program]
+
+	for ub.GetPos() < end {
+		opcode := ub.ReadUInt32()
+		if opcode == uint32(UniformEnd) {
+			break
+		}
+
+		var uType, loc, size, num uint8
+		Uniform_decode(opcode, &uType, &loc, &size, &num)
+		n := uint32(size) * uint32(num)
+		data := make([]byte, n)
+		copy(data, (*[UNIFORM_BUFFER_SIZE]byte)(ub.ReadPointer(n))[:n])
+
+		um := UniformRecord{Type: UniformType(uType), Count: num, Data: data}
+		if int(loc) < len(names) {
+			um.Name = names[loc]
+		}
+		list = append(list, um)
+	}
+	return
+}
+
+/// headless resource, only id is allocated
+
+func (r *Recorder) newId() uint32 {
+	r.nextId++
+	return r.nextId
+}
+
+func (r *Recorder) CreateIndexBuffer(size uint32, data unsafe.Pointer) (id uint32, err error) {
+	return r.newId(), nil
+}
+
+func (r *Recorder) UpdateIndexBuffer(id uint32, offset, size uint32, data unsafe.Pointer) {
+}
+
+func (r *Recorder) DestroyIndexBuffer(id uint32) {
+}
+
+func (r *Recorder) CreateVertexBuffer(size uint32, data unsafe.Pointer) (id uint32, err error) {
+	return r.newId(), nil
+}
+
+func (r *Recorder) UpdateVertexBuffer(id uint32, offset, size uint32, data unsafe.Pointer) {
+}
+
+func (r *Recorder) DestroyVertexBuffer(id uint32) {
+}
+
+func (r *Recorder) CreateTexture(img *image.RGBA) (id uint32, err error) {
+	return r.newId(), nil
+}
+
+func (r *Recorder) UpdateTexture(id uint32, img *image.RGBA, xoff, yoff, w, h int32) {
+}
+
+func (r *Recorder) DestroyTexture(id uint32) {
+}
+
+func (r *Recorder) CreateProgram(vsh, fsh string) (id uint32, err error) {
+	return r.newId(), nil
+}
+
+func (r *Recorder) UseProgram(id uint32) {
+}
+
+func (r *Recorder) DestroyProgram(id uint32) {
+	delete(r.uniforms, id)
+	delete(r.attribs, id)
+}
+
+// UniformLocation returns a stable slot for each uniform name in the program.
+func (r *Recorder) UniformLocation(program uint32, name string) int32 {
+	return location(r.uniforms, program, name)
+}
+
+// AttribLocation returns a stable slot for each attribute name in the program.
+func (r *Recorder) AttribLocation(program uint32, name string) int32 {
+	return location(r.attribs, program, name)
+}
+
+func location(slots map[uint32][]string, program uint32, name string) int32 {
+	name = strings.TrimRight(name, "\x00")
+	names := slots[program]
+	for i, v := range names {
+		if v == name {
+			return int32(i)
+		}
+	}
+	slots[program] = append(names, name)
+	return int32(len(names))
+}
@@ -0,0 +1,66 @@
+package bk
+
+import (
+	"image"
+	"testing"
+	"unsafe"
+)
+
+func TestRecorder(t *testing.T) {
+	rec := NewRecorder()
+	defer SetBackend(CurrentBackend())
+	SetBackend(rec)
+	Init()
+	Reset(480, 320, 1)
+	defer func() {
+		gRenderQ.SortMode = Sequential
+	}()
+
+	shId, _ := R.AllocShader("", "")
+	umId, _ := R.AllocUniform(shId, "proj\x00", UniformVec1, 1)
+	ibId, _ := R.AllocIndexBuffer(Memory{nil, 6 * 2})
+	vbId, _ := R.AllocVertexBuffer(Memory{nil, 4 * 20}, 20)
+	tex1, _ := R.AllocTexture(image.NewRGBA(image.Rect(0, 0, 2, 2)))
+	tex2, _ := R.AllocTexture(image.NewRGBA(image.Rect(0, 0, 2, 2)))
+
+	// uniform only
+	v := float32(2)
+	SetUniform(umId, unsafe.Pointer(&v))
+	Submit(0, shId, 0)
+
+	// draw in reversed order
+	for _, tex := range []uint16{tex2, tex1} {
+		SetTexture(0, 0, tex, 0)
+		SetVertexBuffer(0, vbId, 0, 4)
+		SetIndexBuffer(ibId, 0, 6)
+		SetScissor(0, 0, 10, 10)
+		Submit(0, shId, 0)
+	}
+
+	gRenderQ.SortMode = Ascending
+	Flush()
+
+	frame := rec.LastFrame()
+	if frame == nil || len(frame.Draws) != 3 {
+		t.Fatal("fail to record frame")
+	}
+
+	geometry := frame.Geometry()
+	if len(geometry) != 2 {
+		t.Fatal("fail to record draw-call, num:", len(geometry))
+	}
+	if geometry[0].Textures[0] != tex1&IdMask || geometry[1].Textures[0] != tex2&IdMask {
+		t.Error("fail to sort draw-call by texture")
+	}
+	if d := geometry[0]; d.NumIndex != 6 || d.IndexBuffer != ibId&IdMask || d.VertexBuffer != vbId&IdMask {
+		t.Error("fail to record index range:", d)
+	}
+	if d := geometry[0]; d.Scissor != [4]uint16{0, 0, 10, 10} {
+		t.Error("fail to record scissor:", d.Scissor)
+	}
+
+	um := frame.Draws[0].Uniforms
+	if len(um) != 1 || um[0].Name != "proj" || *(*float32)(unsafe.Pointer(&um[0].Data[0])) != 2 {
+		t.Error("fail to record uniform:", um)
+	}
+}
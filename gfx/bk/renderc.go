@@ -1,11 +1,15 @@
 package bk
 
 import (
+	"fmt"
+	"image"
 	"log"
+	"unsafe"
 
 	"korok.io/korok/hid/gl"
 )
 
+// RenderContext is the OpenGL backend of bk-api.
 type RenderContext struct {
 	// data
 	R  *ResManager
@@ -15,22 +19,13 @@ type RenderContext struct {
 	vao        uint32
 	vaoSupport bool
 
-	// window rect
-	wRect Rect
-	// pixel-ratio = windows-size/frame-buffer-size
-	pixelRatio float32
-
-	// clips rect, index-0 is a default zero-rect.
-	clips []Rect
-
 	backBufferFbo uint32
 }
 
 func NewRenderContext(r *ResManager, ub *UniformBuffer) *RenderContext {
 	return &RenderContext{
-		R:     r,
-		ub:    ub,
-		clips: make([]Rect, 1),
+		R:  r,
+		ub: ub,
 	}
 }
 
@@ -50,22 +45,16 @@ func (ctx *RenderContext) Shutdown() {
 
 // Reset OpenGL state, then each frame has same starting state.
 func (ctx *RenderContext) Reset() {
-	ctx.clips = ctx.clips[:1]
 	gl.Disable(gl.SCISSOR_TEST)
 }
 
-func (ctx *RenderContext) AddClipRect(x, y, w, h uint16) uint16 {
-	index := uint16(len(ctx.clips))
-	ratio := ctx.pixelRatio
-	ctx.clips = append(ctx.clips, Rect{
-		uint16(float32(x) * ratio),
-		uint16(float32(y) * ratio),
-		uint16(float32(w) * ratio),
-		uint16(float32(h) * ratio)})
-	return index
-}
+func (ctx *RenderContext) Draw(frame *Frame) {
+	var (
+		sortKeys   = frame.SortKeys
+		sortValues = frame.SortValues
+		drawList   = frame.DrawList
+	)
 
-func (ctx *RenderContext) Draw(sortKeys []uint64, sortValues []uint16, drawList []RenderDraw) {
 	// if vao support
 	if defaultVao := ctx.vao; 0 != defaultVao {
 		gl.BindVertexArray(defaultVao)
@@ -101,7 +90,7 @@ func (ctx *RenderContext) Draw(sortKeys []uint64, sortValues []uint16, drawList
 		// 2. Scissor
 		if scissor := draw.scissor; currentState.scissor != scissor {
 			currentState.scissor = scissor
-			clip := ctx.clips[scissor]
+			clip := frame.Clips[scissor]
 
 			if clip.isZero() {
 				gl.Disable(gl.SCISSOR_TEST)
@@ -161,15 +150,15 @@ func (ctx *RenderContext) Draw(sortKeys []uint64, sortValues []uint16, drawList
 			if InvalidId != bind {
 				if current != bind || programChanged {
 					texture := ctx.R.textures[bind]
-					texture.Bind(int32(stage))
+					gl.ActiveTexture(gl.TEXTURE0 + uint32(stage))
+					gl.BindTexture(gl.TEXTURE_2D, texture.Id)
 				}
 			}
 			currentState.textures[stage] = bind
 		}
 
 		// 8. index & vertex binding TODO 优化 attribute 绑定
-		shader := ctx.R.shaders[shaderId]
-		shader.BindAttributes(ctx.R, draw.vertexBuffers[:])
+		ctx.bindAttributes(&ctx.R.shaders[shaderId], draw.vertexBuffers[:])
 
 		if ib := draw.indexBuffer; ib != InvalidId && ib != currentState.indexBuffer {
 			gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ctx.R.indexBuffers[ib].Id)
@@ -221,8 +210,49 @@ func (ctx *RenderContext) bindUniform(begin, end uint32) {
 	}
 }
 
-func (ctx *RenderContext) bindAttributes() {
+// 如果 AttrBinds 指定了一个 Stream，但是 Stream 并没有提供相应的数据(stride < Offset)
+// 此时应该 disable 当前 Attribute,
+func (ctx *RenderContext) bindAttributes(sh *Shader, streams []Stream) {
+	var bindStream uint16 = UInt16Max
+	var bindStride uint16
+	for i := uint32(0); i < sh.numAttr; i++ {
+		bind := sh.AttrBinds[i]
+		stream := streams[bind.stream]
+
+		if bind.stream != bindStream {
+			buffer := ctx.R.vertexBuffers[stream.vertexBuffer&IdMask]
+			gl.BindBuffer(gl.ARRAY_BUFFER, buffer.Id)
+			bindStream = bind.stream
+			bindStride = buffer.layout
+		}
+
+		slot := uint32(bind.slot)
+		enable := bindStride != 0
 
+		if enable {
+			gl.EnableVertexAttribArray(slot)
+
+			var (
+				comp   = bind.comp
+				num    = int32(comp.Num)
+				xType  = g_AttrType[comp.Type]
+				offset = int(comp.Offset)
+				base   = int(stream.firstVertex) * int(bindStride)
+			)
+
+			var norm bool
+			if (comp.Normalized & 0x01) != 0 {
+				norm = true
+			}
+			if offset < int(bindStride) {
+				gl.VertexAttribPointer(slot, num, xType, norm, int32(bindStride), base+offset)
+			} else {
+				gl.DisableVertexAttribArray(slot)
+			}
+		} else {
+			gl.DisableVertexAttribArray(slot)
+		}
+	}
 }
 
 func (ctx *RenderContext) bindState(changedFlags, newFlags uint64) {
@@ -273,3 +303,116 @@ func (ctx *RenderContext) bindState(changedFlags, newFlags uint64) {
 		}
 	}
 }
+
+/// OpenGL resource
+
+func (ctx *RenderContext) CreateIndexBuffer(size uint32, data unsafe.Pointer) (id uint32, err error) {
+	return ctx.createBuffer(gl.ELEMENT_ARRAY_BUFFER, size, data)
+}
+
+func (ctx *RenderContext) UpdateIndexBuffer(id uint32, offset, size uint32, data unsafe.Pointer) {
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, id)
+	gl.BufferSubData(gl.ELEMENT_ARRAY_BUFFER, int(offset), int(size), data)
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, 0)
+}
+
+func (ctx *RenderContext) DestroyIndexBuffer(id uint32) {
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, 0)
+	gl.DeleteBuffers(1, &id)
+}
+
+/// draw indirect >= es 3.0 or gl 4.0
+func (ctx *RenderContext) CreateVertexBuffer(size uint32, data unsafe.Pointer) (id uint32, err error) {
+	return ctx.createBuffer(gl.ARRAY_BUFFER, size, data)
+}
+
+func (ctx *RenderContext) UpdateVertexBuffer(id uint32, offset, size uint32, data unsafe.Pointer) {
+	gl.BindBuffer(gl.ARRAY_BUFFER, id)
+	gl.BufferSubData(gl.ARRAY_BUFFER, int(offset), int(size), data)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+}
+
+func (ctx *RenderContext) DestroyVertexBuffer(id uint32) {
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	gl.DeleteBuffers(1, &id)
+}
+
+func (ctx *RenderContext) createBuffer(target uint32, size uint32, data unsafe.Pointer) (id uint32, err error) {
+	gl.GenBuffers(1, &id)
+	if id == 0 {
+		err = fmt.Errorf("failed to generate buffer id")
+		return
+	}
+	gl.BindBuffer(target, id)
+	if data == nil {
+		gl.BufferData(target, int(size), nil, gl.DYNAMIC_DRAW)
+	} else {
+		gl.BufferData(target, int(size), data, gl.STATIC_DRAW)
+	}
+	gl.BindBuffer(target, 0)
+	return
+}
+
+func (ctx *RenderContext) CreateTexture(rgba *image.RGBA) (id uint32, err error) {
+	// 1 apply space
+	gl.GenTextures(1, &id)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, id)
+	// 2 params
+	// 大小插值
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	// 环绕方式
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+
+	// 3 upload
+	gl.TexImage2D(gl.TEXTURE_2D,
+		0,
+		gl.RGBA,
+		int32(rgba.Rect.Dx()),
+		int32(rgba.Rect.Dy()),
+		0,
+		gl.RGBA,
+		gl.UNSIGNED_BYTE,
+		unsafe.Pointer(&rgba.Pix[0]))
+	return
+}
+
+func (ctx *RenderContext) UpdateTexture(id uint32, rgba *image.RGBA, xoff, yoff, w, h int32) {
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, id)
+	gl.TexSubImage2D(gl.TEXTURE_2D,
+		0,
+		xoff,
+		yoff,
+		w,
+		h,
+		gl.RGBA,
+		gl.UNSIGNED_BYTE,
+		unsafe.Pointer(&rgba.Pix[0]))
+}
+
+func (ctx *RenderContext) DestroyTexture(id uint32) {
+	gl.DeleteTextures(1, &id)
+}
+
+func (ctx *RenderContext) CreateProgram(vsh, fsh string) (uint32, error) {
+	return Compile(vsh, fsh)
+}
+
+func (ctx *RenderContext) UseProgram(id uint32) {
+	gl.UseProgram(id)
+}
+
+func (ctx *RenderContext) DestroyProgram(id uint32) {
+
+}
+
+func (ctx *RenderContext) UniformLocation(program uint32, name string) int32 {
+	return gl.GetUniformLocation(program, name)
+}
+
+func (ctx *RenderContext) AttribLocation(program uint32, name string) int32 {
+	return gl.GetAttribLocation(program, name)
+}
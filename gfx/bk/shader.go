@@ -24,51 +24,6 @@ type Shader struct {
 	customUniforms []uint16
 }
 
-// 如果 AttrBinds 指定了一个 Stream，但是 Stream 并没有提供相应的数据(stride < Offset)
-// 此时应该 disable 当前 Attribute,
-func (sh *Shader) BindAttributes(R *ResManager, streams []Stream) {
-	var bindStream uint16 = UInt16Max
-	var bindStride uint16
-	for i := uint32(0); i < sh.numAttr; i++ {
-		bind := sh.AttrBinds[i]
-		stream := streams[bind.stream]
-
-		if bind.stream != bindStream {
-			buffer := R.vertexBuffers[stream.vertexBuffer&IdMask]
-			gl.BindBuffer(gl.ARRAY_BUFFER, buffer.Id)
-			bindStream = bind.stream
-			bindStride = buffer.layout
-		}
-
-		slot := uint32(bind.slot)
-		enable := bindStride != 0
-
-		if enable {
-			gl.EnableVertexAttribArray(slot)
-
-			var (
-				comp   = bind.comp
-				num    = int32(comp.Num)
-				xType  = g_AttrType[comp.Type]
-				offset = int(comp.Offset)
-				base   = int(stream.firstVertex)*int(bindStride)
-			)
-
-			var norm bool
-			if (comp.Normalized & 0x01) != 0 {
-				norm = true
-			}
-			if offset < int(bindStride) {
-				gl.VertexAttribPointer(slot, num, xType, norm, int32(bindStride), base+offset)
-			} else {
-				gl.DisableVertexAttribArray(slot)
-			}
-		} else {
-			gl.DisableVertexAttribArray(slot)
-		}
-	}
-}
-
 type AttribBind struct {
 	slot   uint16 // slot location
 	stream uint16 // stream index
@@ -77,7 +32,7 @@ type AttribBind struct {
 }
 
 func (sh *Shader) AddAttributeBinding(attr string, stream uint32, comp VertexComp) {
-	slot := gl.GetAttribLocation(sh.Program, attr)
+	slot := gBackend.AttribLocation(sh.Program, attr)
 
 	if slot < 0 {
 		log.Printf("fail to bind attribute: %v, %s", comp, attr)
@@ -104,11 +59,11 @@ type GLShader struct {
 }
 
 func (s *GLShader) Use() {
-	gl.UseProgram(s.Program)
+	gBackend.UseProgram(s.Program)
 }
 
 func (s *GLShader) Create(vsh, fsh string) error{
-	if program, err := Compile(vsh, fsh); err == nil {
+	if program, err := gBackend.CreateProgram(vsh, fsh); err == nil {
 		s.Program = program
 		//gl.BindFragDataLocation(program, 0, "gl_FragColor\x00")
 		return nil
@@ -118,7 +73,7 @@ func (s *GLShader) Create(vsh, fsh string) error{
 }
 
 func (s *GLShader) Destroy() {
-
+	gBackend.DestroyProgram(s.Program)
 }
 
 func (s *GLShader) SetFloat(name string, value float32) {
@@ -7,9 +7,7 @@ import (
 	_ "image/jpeg"
 	_ "image/png"
 
-	"korok.io/korok/hid/gl"
 	"korok.io/korok/math/f32"
-	"unsafe"
 )
 
 /**
@@ -41,25 +39,10 @@ func (t *Texture2D) Update(img image.Image, xoff, yoff int32, w, h int32) (err e
 	}
 	draw.Draw(rgba, rgba.Bounds(), img, image.Point{0, 0}, draw.Src)
 
-	gl.ActiveTexture(gl.TEXTURE0)
-	gl.BindTexture(gl.TEXTURE_2D, t.Id);
-	gl.TexSubImage2D(gl.TEXTURE_2D,
-		0,
-		xoff,
-		yoff,
-		w,
-		h,
-		gl.RGBA,
-		gl.UNSIGNED_BYTE,
-		unsafe.Pointer(&rgba.Pix[0]))
+	gBackend.UpdateTexture(t.Id, rgba, xoff, yoff, w, h)
 	return
 }
 
-func (t *Texture2D) Bind(stage int32) {
-	gl.ActiveTexture(gl.TEXTURE0 + uint32(stage))
-	gl.BindTexture(gl.TEXTURE_2D, t.Id)
-}
-
 func (t *Texture2D) Sub(x, y float32, w, h float32) *SubTex {
 	subTex := &SubTex{Texture2D: t}
 	subTex.Min = f32.Vec2{x, y}
@@ -68,7 +51,7 @@ func (t *Texture2D) Sub(x, y float32, w, h float32) *SubTex {
 }
 
 func (t *Texture2D) Destroy() {
-	gl.DeleteTextures(1, &t.Id)
+	gBackend.DestroyTexture(t.Id)
 }
 
 // TODO 提前转换图片格式
@@ -80,30 +63,7 @@ func newTexture(img image.Image) (uint32, error) {
 	}
 	draw.Draw(rgba, rgba.Bounds(), img, image.Point{0, 0}, draw.Src)
 	// 4. upload texture
-	var texture uint32
-	// 4.1 apply space
-	gl.GenTextures(1, &texture)
-	gl.ActiveTexture(gl.TEXTURE0)
-	gl.BindTexture(gl.TEXTURE_2D, texture)
-	// 4.2 params
-	// 大小插值
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-	// 环绕方式
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
-
-	// 4.3 upload
-	gl.TexImage2D(gl.TEXTURE_2D,
-		0,
-		gl.RGBA,
-		int32(rgba.Rect.Dx()),
-		int32(rgba.Rect.Dy()),
-		0,
-		gl.RGBA,
-		gl.UNSIGNED_BYTE,
-		unsafe.Pointer(&rgba.Pix[0]))
-	return texture, nil
+	return gBackend.CreateTexture(rgba)
 }
 
 ///// 还需要抽象 SubTexture 的概念出来
@@ -1,7 +1,6 @@
 package bk
 
 import (
-	"korok.io/korok/hid/gl"
 	"unsafe"
 )
 const (
@@ -36,7 +35,7 @@ type Uniform struct {
 }
 
 func (um *Uniform) create(program uint32, name string, xType UniformType, num uint32) (slot int32) {
-	slot = gl.GetUniformLocation(program, name)
+	slot = gBackend.UniformLocation(program, name)
 	um.Slot = uint8(slot)
 	um.Name = name
 	um.Type = xType
@@ -31,6 +31,9 @@ type Camera struct {
 	}
 	follow engi.Entity
 
+	// follow, shake and zoom, see camera_ctrl.go
+	ctrl cameraCtrl
+
 	desire struct{
 		w, h float32
 	}
@@ -46,20 +49,25 @@ func (c *Camera) initialize() {
 
 	// scale
 	c.mat.sx, c.mat.sy = 1, 1
+
+	c.ctrl.initialize()
 }
 
 func (c *Camera) P() (left, right, bottom, top float32){
+	x, y := c.center()
 	hx := c.view.w*c.mat.sx/2
 	hy := c.view.h*c.mat.sy/2
-	left   = c.mat.x - hx
-	right  = c.mat.x + hx
-	bottom = c.mat.y - hy
-	top    = c.mat.y + hy
+	left   = x - hx
+	right  = x + hx
+	bottom = y - hy
+	top    = y + hy
 	return
 }
 
+// View returns the center(with shake) and size of the view.
 func (c *Camera) View() (x, y, w, h float32) {
-	return c.mat.x, c.mat.y, c.view.w, c.view.h
+	x, y = c.center()
+	return x, y, c.view.w, c.view.h
 }
 
 func (c *Camera) Bounding() (left, top, right, bottom float32){
@@ -68,22 +76,27 @@ func (c *Camera) Bounding() (left, top, right, bottom float32){
 
 // Screen2Scene converts (x,y) in screen coordinate to (x1,y1) in game's world coordinate.
 func (c *Camera) Screen2Scene(x, y float32) (x1, y1 float32) {
-	x1 = c.mat.x - c.view.w/2 + x*c.view.scale[0]
-	y1 = c.mat.y + c.view.h/2 - y*c.view.scale[1]
+	cx, cy := c.center()
+	x1 = cx - c.view.w/2 + x*c.view.scale[0]
+	y1 = cy + c.view.h/2 - y*c.view.scale[1]
 	return
 }
 
 // Scene2Screen converts (x,y) in game's world coordinate to screen coordinate.
 func (c *Camera) Scene2Screen(x, y float32) (x1, y1 float32) {
-	x1 =  (x + c.view.w/2 - c.mat.x)*c.view.invScale[0]
-	y1 = -(y - c.view.h/2 - c.mat.y)*c.view.invScale[1]
+	cx, cy := c.center()
+	x1 =  (x + c.view.w/2 - cx)*c.view.invScale[0]
+	y1 = -(y - c.view.h/2 - cy)*c.view.invScale[1]
 	return
 }
 
+// Flow makes the camera follow the entity, engi.Ghost to stop.
 func (c *Camera) Flow(entity engi.Entity) {
 	c.follow = entity
+	c.ctrl.follow.reset()
 }
 
+// Position returns the position of camera, the shake is not included.
 func (c *Camera) Position() (x,y float32) {
 	return c.mat.x, c.mat.y
 }
@@ -105,11 +118,13 @@ func (c *Camera) Scale() (sx, sy float32) {
 
 func (c *Camera) ScaleTo(sx, sy float32) {
 	c.mat.sx, c.mat.sy = sx, sy
+	c.clamp()
 }
 
 func (c *Camera) ScaleBy(dsx, dsy float32) {
 	c.mat.sx += dsx
 	c.mat.sy += dsy
+	c.clamp()
 }
 
 func (c *Camera) Rotation() float32 {
@@ -190,31 +205,43 @@ func (c *Camera) SetDesiredViewport(w, h float32) {
 }
 
 func (c *Camera) clamp() {
+	c.mat.x, c.mat.y = c.clampCenter(c.mat.x, c.mat.y)
+}
+
+// clampCenter keeps the visible rect centered at (x, y) in the bound.
+func (c *Camera) clampCenter(x, y float32) (float32, float32) {
+	var (
+		hw = c.view.w * c.mat.sx / 2
+		hh = c.view.h * c.mat.sy / 2
+	)
 	// x
-	if left := c.mat.x - c.view.w/2; left < c.bound.left {
-		c.mat.x += c.bound.left - left
-	} else if right := c.mat.x + c.view.w/2; right > c.bound.right {
-		c.mat.x += c.bound.right - right
+	if left := x - hw; left < c.bound.left {
+		x += c.bound.left - left
+	} else if right := x + hw; right > c.bound.right {
+		x += c.bound.right - right
 	}
 
 	// y
-	if bottom := c.mat.y - c.view.h/2; bottom < c.bound.bottom {
-		c.mat.y += c.bound.bottom - bottom
-	} else if top := c.mat.y + c.view.h/2; top > c.bound.top {
-		c.mat.y += c.bound.top - top
+	if bottom := y - hh; bottom < c.bound.bottom {
+		y += c.bound.bottom - bottom
+	} else if top := y + hh; top > c.bound.top {
+		y += c.bound.top - top
 	}
+	return x, y
+}
+
+// the center of view, with shake
+func (c *Camera) center() (x, y float32) {
+	s := &c.ctrl.shake
+	return c.mat.x + s.x, c.mat.y + s.y
 }
 
 func (c *Camera) InView(xf *Transform, size, gravity f32.Vec2) bool {
-	var (
-		w = c.view.w * c.mat.sx
-		h = c.view.h * c.mat.sy
-	)
 	if xf.world.Rotation == 0 { // happy path
 		p := xf.world.Position
 		size[0], size[1] = size[0]*xf.world.Scale[0], size[1]*xf.world.Scale[1]
 		a := AABB{p[0]-size[0]*gravity[0], p[1]-size[1]*gravity[1], size[0], size[1]}
-		b := AABB{c.mat.x-w/2, c.mat.y-h/2, w, h}
+		b := c.viewAABB()
 		return OverlapAB(&a, &b)
 	} else {
 		srt := xf.world
@@ -234,11 +261,21 @@ func (c *Camera) InView(xf *Transform, size, gravity f32.Vec2) bool {
 		}
 		ex, ey = m.TransformNormal(ex, ey)
 		a := AABB{cx-ex, cy-ey, ex*2, ey*2}
-		b := AABB{c.mat.x-w/2, c.mat.y-h/2, w, h}
+		b := c.viewAABB()
 		return OverlapAB(&a, &b)
 	}
 }
 
+// the visible rect of camera in world space
+func (c *Camera) viewAABB() AABB {
+	var (
+		w = c.view.w * c.mat.sx
+		h = c.view.h * c.mat.sy
+	)
+	x, y := c.center()
+	return AABB{x-w/2, y-h/2, w, h}
+}
+
 type mat3 [9]float32 // fast culling matrix, (0, 0) as the center of the local model
 
 func (m *mat3) Initialize(x, y, angle, sx, sy float32) {
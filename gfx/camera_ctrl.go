@@ -0,0 +1,250 @@
+package gfx
+
+import (
+	"korok.io/korok/math"
+	"korok.io/korok/math/ease"
+	"korok.io/korok/math/f32"
+)
+
+// FollowMode is how the camera moves to the followed entity.
+type FollowMode uint8
+
+const (
+	// FollowLock moves the camera to the target directly.
+	FollowLock FollowMode = iota
+
+	// FollowLerp moves the camera a part of the distance every frame,
+	// damping is the speed of approaching.
+	FollowLerp
+
+	// FollowSpring moves the camera with a critically damped spring,
+	// damping is the stiffness of the spring.
+	FollowSpring
+)
+
+// follow, shake and zoom state of the camera
+type cameraCtrl struct {
+	follow cameraFollow
+	shake  cameraShake
+	zoom   cameraZoom
+}
+
+func (ctrl *cameraCtrl) initialize() {
+	ctrl.follow.mode = FollowLerp
+	ctrl.follow.damping = 6 // about 0.1 of the distance per frame at 60fps
+
+	s := &ctrl.shake
+	s.offset, s.frequency, s.decay = 16, 15, 1
+}
+
+type cameraFollow struct {
+	mode    FollowMode
+	damping float32
+
+	// size of the dead-zone
+	deadZone f32.Vec2
+
+	// look-ahead time and max distance
+	lookAhead, maxAhead float32
+
+	// last target position and the smoothed velocity
+	last     f32.Vec2
+	tracking bool
+	velocity f32.Vec2
+	ahead    f32.Vec2
+
+	// camera velocity of the spring
+	spring f32.Vec2
+}
+
+func (f *cameraFollow) reset() {
+	f.tracking = false
+	f.velocity, f.ahead, f.spring = f32.Vec2{}, f32.Vec2{}, f32.Vec2{}
+}
+
+// SetFollowMode sets how the camera follows the entity.
+func (c *Camera) SetFollowMode(mode FollowMode, damping float32) {
+	c.ctrl.follow.mode = mode
+	c.ctrl.follow.damping = damping
+}
+
+// SetDeadZone sets a rect(centered at the camera) in which the followed
+// entity can move without moving the camera.
+func (c *Camera) SetDeadZone(w, h float32) {
+	c.ctrl.follow.deadZone = f32.Vec2{w, h}
+}
+
+// SetLookAhead makes the camera look ahead of the moving direction of the
+// followed entity, the distance is velocity*time and less than max.
+func (c *Camera) SetLookAhead(time, max float32) {
+	c.ctrl.follow.lookAhead = time
+	c.ctrl.follow.maxAhead = max
+}
+
+// track moves the camera to the target position p.
+func (c *Camera) track(p f32.Vec2, dt float32) {
+	f := &c.ctrl.follow
+	if dt <= 0 {
+		return
+	}
+	if f.tracking {
+		v := p.Sub(f.last).Mul(1 / dt)
+		f.velocity = f.velocity.Add(v.Sub(f.velocity).Mul(lerpFactor(8, dt)))
+	}
+	f.last, f.tracking = p, true
+
+	// look-ahead
+	target := p
+	if f.lookAhead > 0 {
+		ahead := f.velocity.Mul(f.lookAhead)
+		if n := ahead.Len(); f.maxAhead > 0 && n > f.maxAhead {
+			ahead = ahead.Mul(f.maxAhead / n)
+		}
+		f.ahead = f.ahead.Add(ahead.Sub(f.ahead).Mul(lerpFactor(4, dt)))
+		target = target.Add(f.ahead)
+	}
+
+	// dead-zone, the camera moves when the target is out of the zone
+	pos := f32.Vec2{c.mat.x, c.mat.y}
+	goal := pos
+	for i := 0; i < 2; i++ {
+		half := f.deadZone[i] / 2
+		if d := target[i] - pos[i]; d > half {
+			goal[i] = target[i] - half
+		} else if d < -half {
+			goal[i] = target[i] + half
+		}
+	}
+
+	switch f.mode {
+	case FollowLock:
+		pos = goal
+	case FollowLerp:
+		pos = pos.Add(goal.Sub(pos).Mul(lerpFactor(f.damping, dt)))
+	case FollowSpring:
+		// Game Programming Gems 4, 1.10
+		omega := f.damping
+		x := omega * dt
+		exp := 1 / (1 + x + 0.48*x*x + 0.235*x*x*x)
+		for i := 0; i < 2; i++ {
+			change := pos[i] - goal[i]
+			temp := (f.spring[i] + omega*change) * dt
+			f.spring[i] = (f.spring[i] - omega*temp) * exp
+			pos[i] = goal[i] + (change+temp)*exp
+		}
+	}
+	c.MoveTo(pos[0], pos[1])
+}
+
+// frame rate independent lerp factor
+func lerpFactor(speed, dt float32) float32 {
+	return 1 - math.Exp(-speed*dt)
+}
+
+// Trauma based screen shake, the shake is trauma^2 and trauma decays
+// over time. See: Math for Game Programmers: Juicing Your Cameras With Math.
+type cameraShake struct {
+	trauma float32
+
+	// max offset, noise frequency, trauma lost per second
+	offset, frequency, decay float32
+
+	time float32
+	x, y float32
+}
+
+// AddTrauma shakes the camera, trauma is clamped to [0, 1].
+func (c *Camera) AddTrauma(trauma float32) {
+	s := &c.ctrl.shake
+	s.trauma = math.Clamp(s.trauma+trauma, 0, 1)
+}
+
+func (c *Camera) Trauma() float32 {
+	return c.ctrl.shake.trauma
+}
+
+// SetShake sets the max offset, the frequency of noise and the trauma
+// lost per second.
+func (c *Camera) SetShake(offset, frequency, decay float32) {
+	s := &c.ctrl.shake
+	s.offset, s.frequency, s.decay = offset, frequency, decay
+}
+
+func (c *Camera) updateShake(dt float32) {
+	s := &c.ctrl.shake
+	if s.trauma <= 0 {
+		s.x, s.y = 0, 0
+		return
+	}
+	s.time += dt
+	shake := s.trauma * s.trauma
+	t := s.time * s.frequency
+	s.x = s.offset * shake * noise(t, 0)
+	s.y = s.offset * shake * noise(t, 1)
+	if s.trauma -= s.decay * dt; s.trauma < 0 {
+		s.trauma = 0
+	}
+
+	// the shaken view should be in the bound too
+	x, y := c.clampCenter(c.mat.x+s.x, c.mat.y+s.y)
+	s.x, s.y = x-c.mat.x, y-c.mat.y
+}
+
+// smooth noise in [-1, 1], sum of sine waves
+func noise(t float32, seed int) float32 {
+	phase := float32(seed) * 12.9898
+	return (math.Sin(t+phase) + math.Sin(t*1.73+phase*2)*.5 + math.Sin(t*2.97+phase*3)*.25) / 1.75
+}
+
+type cameraZoom struct {
+	from, to       f32.Vec2
+	time, duration float32
+	fn             ease.Function
+	active         bool
+}
+
+// ZoomTo changes the scale to (scale, scale) in duration seconds with the
+// easing function, fn is ease.InOutSine if nil.
+func (c *Camera) ZoomTo(scale, duration float32, fn ease.Function) {
+	if duration <= 0 {
+		c.ctrl.zoom.active = false
+		c.ScaleTo(scale, scale)
+		return
+	}
+	if fn == nil {
+		fn = ease.InOutSine
+	}
+	c.ctrl.zoom = cameraZoom{
+		from:     f32.Vec2{c.mat.sx, c.mat.sy},
+		to:       f32.Vec2{scale, scale},
+		duration: duration,
+		fn:       fn,
+		active:   true,
+	}
+}
+
+func (c *Camera) Zooming() bool {
+	return c.ctrl.zoom.active
+}
+
+func (c *Camera) updateZoom(dt float32) {
+	z := &c.ctrl.zoom
+	if !z.active {
+		return
+	}
+	z.time += dt
+	t := z.time / z.duration
+	if t >= 1 {
+		t, z.active = 1, false
+	}
+	k := float32(z.fn(float64(t)))
+	s := z.from.Add(z.to.Sub(z.from).Mul(k))
+	c.ScaleTo(s[0], s[1])
+}
+
+// Update updates the zoom transition and shake of the camera, the main
+// camera is updated by RenderSystem.
+func (c *Camera) Update(dt float32) {
+	c.updateZoom(dt)
+	c.updateShake(dt)
+}
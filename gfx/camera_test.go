@@ -0,0 +1,135 @@
+package gfx
+
+import (
+	"testing"
+
+	"korok.io/korok/math"
+	"korok.io/korok/math/ease"
+	"korok.io/korok/math/f32"
+)
+
+func newTestCamera() *Camera {
+	c := &Camera{}
+	c.initialize()
+	c.SetViewPort(480, 320)
+	c.MoveTo(0, 0)
+	return c
+}
+
+func TestCameraDeadZone(t *testing.T) {
+	c := newTestCamera()
+	c.SetFollowMode(FollowLock, 0)
+	c.SetDeadZone(100, 60)
+
+	c.track(f32.Vec2{40, -20}, 1.0/60)
+	if x, y := c.Position(); x != 0 || y != 0 {
+		t.Errorf("camera moved in dead-zone: %f, %f", x, y)
+	}
+	c.track(f32.Vec2{80, -50}, 1.0/60)
+	if x, y := c.Position(); x != 30 || y != -20 {
+		t.Errorf("camera should stop at the edge of dead-zone: %f, %f", x, y)
+	}
+}
+
+func TestCameraFollowDamping(t *testing.T) {
+	for _, mode := range []FollowMode{FollowLerp, FollowSpring} {
+		c := newTestCamera()
+		c.SetFollowMode(mode, 8)
+		c.track(f32.Vec2{100, 50}, 1.0/60)
+		if x, _ := c.Position(); x <= 0 || x >= 100 {
+			t.Errorf("mode %d: camera should move smoothly, x: %f", mode, x)
+		}
+		for i := 0; i < 180; i++ {
+			c.track(f32.Vec2{100, 50}, 1.0/60)
+		}
+		if x, y := c.Position(); math.ABS(x-100) > .1 || math.ABS(y-50) > .1 {
+			t.Errorf("mode %d: camera should reach the target: %f, %f", mode, x, y)
+		}
+	}
+}
+
+func TestCameraLookAhead(t *testing.T) {
+	c := newTestCamera()
+	c.SetFollowMode(FollowLock, 0)
+	c.SetLookAhead(.5, 40)
+
+	// moving right at 600 pixel/s
+	p := f32.Vec2{}
+	for i := 0; i < 120; i++ {
+		p[0] += 10
+		c.track(p, 1.0/60)
+	}
+	x, _ := c.Position()
+	if d := x - p[0]; d <= 30 || d > 40 {
+		t.Errorf("look-ahead distance should be close to max: %f", d)
+	}
+}
+
+func TestCameraShake(t *testing.T) {
+	c := newTestCamera()
+	c.SetShake(10, 20, 2)
+	c.AddTrauma(.8)
+	c.AddTrauma(.8)
+	if c.Trauma() != 1 {
+		t.Errorf("trauma should be clamped: %f", c.Trauma())
+	}
+
+	shaken := false
+	for i := 0; i < 10; i++ {
+		c.Update(1.0 / 60)
+		x, y, _, _ := c.View()
+		if x != 0 || y != 0 {
+			shaken = true
+		}
+		if math.ABS(x) > 10 || math.ABS(y) > 10 {
+			t.Errorf("shake out of range: %f, %f", x, y)
+		}
+	}
+	if !shaken {
+		t.Error("camera not shaken")
+	}
+	if x, y := c.Position(); x != 0 || y != 0 {
+		t.Errorf("shake should not move the camera: %f, %f", x, y)
+	}
+
+	for i := 0; i < 60; i++ {
+		c.Update(1.0 / 60)
+	}
+	if x, y, _, _ := c.View(); c.Trauma() != 0 || x != 0 || y != 0 {
+		t.Errorf("shake should stop: %f, %f, %f", c.Trauma(), x, y)
+	}
+}
+
+func TestCameraShakeBound(t *testing.T) {
+	c := newTestCamera()
+	c.SetBound(0, 320, 960, 0)
+	c.MoveTo(240, 160)
+	c.AddTrauma(1)
+	const e = .001
+	for i := 0; i < 30; i++ {
+		c.Update(1.0 / 60)
+		if l, r, b, tp := c.P(); l < -e || r > 960+e || b < -e || tp > 320+e {
+			t.Fatalf("shaken view out of bound: %f, %f, %f, %f", l, r, b, tp)
+		}
+	}
+}
+
+func TestCameraZoom(t *testing.T) {
+	c := newTestCamera()
+	c.SetBound(-480, 320, 480, -320)
+	c.ZoomTo(2, 1, ease.Linear)
+	c.Update(.5)
+	if sx, sy := c.Scale(); sx != 1.5 || sy != 1.5 {
+		t.Errorf("zoom at half time: %f, %f", sx, sy)
+	}
+	c.Update(.6)
+	if sx, _ := c.Scale(); sx != 2 || c.Zooming() {
+		t.Errorf("zoom should be done: %f", sx)
+	}
+
+	// the scaled view is clamped in the bound
+	c.MoveTo(400, 0)
+	if l, r, _, _ := c.P(); l != -480 || r != 480 {
+		t.Errorf("scaled view out of bound: %f, %f", l, r)
+	}
+}
@@ -79,6 +79,19 @@ type AABB struct {
 	width, height float32
 }
 
+func NewAABB(x, y, width, height float32) AABB {
+	return AABB{x, y, width, height}
+}
+
+// Rect returns the left-bottom corner and size.
+func (ab *AABB) Rect() (x, y, width, height float32) {
+	return ab.x, ab.y, ab.width, ab.height
+}
+
+func (ab *AABB) Contains(x, y float32) bool {
+	return x >= ab.x && x <= ab.x+ab.width && y >= ab.y && y <= ab.y+ab.height
+}
+
 func OverlapAB(a, b *AABB) bool {
 	if a.x < b.x+b.width && a.x+a.width>b.x && a.y < b.y+b.height && a.y+a.height > b.y {
 		return true
@@ -19,14 +19,20 @@ import (
 //
 // Supported image formats are 32-bit RGBA as PNG, JPEG.
 func LoadBitmap(img, config io.Reader, scale int) (Font, error) {
-	f := &fontAtlas{glyphs: make(map[rune]Glyph)}
-
-	// load texture
-	pix, _, err := image.Decode(img)
+	d, err := DecodeBitmap(img, config, scale)
 	if err != nil {
 		return nil, err
 	}
-	err = f.loadTex(toRGBA(pix, scale))
+	return d.Upload()
+}
+
+// DecodeBitmap is same as LoadBitmap, but the sprite sheet is not
+// uploaded to GPU, it's safe to call it in other goroutine.
+func DecodeBitmap(img, config io.Reader, scale int) (*Decoded, error) {
+	f := &fontAtlas{glyphs: make(map[rune]Glyph)}
+
+	// decode texture
+	pix, _, err := image.Decode(img)
 	if err != nil {
 		return nil, err
 	}
@@ -62,7 +68,7 @@ func LoadBitmap(img, config io.Reader, scale int) (Font, error) {
 	f.gWidth = float32(gw)
 	f.gHeight = float32(gh)
 	// log.Println("dump:", f)
-	return f, nil
+	return &Decoded{f, toRGBA(pix, scale)}, nil
 }
 
 type fontConfig struct {
@@ -56,6 +56,21 @@ type fontAtlas struct {
 	// fallback
 }
 
+// Decoded is a font decoded in memory, the texture is not uploaded yet.
+// Call Upload in the render thread to get the Font.
+type Decoded struct {
+	f   *fontAtlas
+	img *image.RGBA
+}
+
+// Upload uploads the glyph texture to GPU and returns the Font.
+func (d *Decoded) Upload() (Font, error) {
+	if err := d.f.loadTex(d.img); err != nil {
+		return nil, err
+	}
+	return d.f, nil
+}
+
 func (f *fontAtlas) Tex2D() (id uint16, tex *bk.Texture2D) {
 	id = f.id
 	if ok, t := bk.R.Texture(id); ok {
@@ -21,6 +21,22 @@ import (
 // The low and high values determine the lower and upper rune limits
 // we should load for this Font. For standard ASCII this would be:32, 127.
 func LoadTrueType(r io.Reader, lc TTFConfig) (*fontAtlas, error) {
+	d, err := DecodeTrueType(r, lc)
+	if err != nil {
+		return nil, err
+	}
+	// load image
+	if err = d.f.loadTex(d.img); err != nil {
+		return nil, err
+	}
+	// save baked fontAtlas-image
+	//savePng(d.img)
+	return d.f, nil
+}
+
+// DecodeTrueType is same as LoadTrueType, but the baked image is not
+// uploaded to GPU, it's safe to call it in other goroutine.
+func DecodeTrueType(r io.Reader, lc TTFConfig) (*Decoded, error) {
 	data, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, err
@@ -110,14 +126,7 @@ func LoadTrueType(r io.Reader, lc TTFConfig) (*fontAtlas, error) {
 	f.gWidth = fixed2f32(gw)
 	f.gHeight = fixed2f32(gh)
 
-	// load image
-	err = f.loadTex(img)
-	if err != nil {
-		return nil, err
-	}
-	// save baked fontAtlas-image
-	//savePng(img)
-	return f, nil
+	return &Decoded{f, img}, nil
 }
 
 func fixed2f32(fixed fixed.Int26_6) float32 {
@@ -125,6 +125,9 @@ type MeshTable struct {
 	comps []MeshComp
 	_map  map[uint32]int
 	index, cap int
+
+	// changed when a comp is added or deleted
+	version uint32
 }
 
 func NewMeshTable(cap int) *MeshTable {
@@ -146,12 +149,13 @@ func (mt *MeshTable) NewComp(entity engi.Entity) (mc *MeshComp) {
 	mc.visible = true
 	mt._map[ei] = mt.index
 	mt.index ++
+	mt.version ++
 	return
 }
 
 func (mt *MeshTable) Alive(entity engi.Entity) bool {
 	if v, ok := mt._map[entity.Index()]; ok {
-		return mt.comps[v].Entity == 0
+		return mt.comps[v].Entity == entity
 	}
 	return false
 }
@@ -178,6 +182,7 @@ func (mt *MeshTable) Delete(entity engi.Entity) {
 		}
 
 		mt.index -= 1
+		mt.version ++
 		delete(mt._map, ei)
 	}
 }
@@ -186,12 +191,18 @@ func (mt *MeshTable) Destroy() {
 	mt.comps = make([]MeshComp, 0)
 	mt._map = make(map[uint32]int)
 	mt.index = 0
+	mt.version ++
 }
 
 func (mt *MeshTable) Size() (size, cap int) {
 	return mt.index, mt.cap
 }
 
+// EntityAt returns the entity of the i-th comp, i in [0, size)
+func (mt *MeshTable) EntityAt(i int) engi.Entity {
+	return mt.comps[i].Entity
+}
+
 func meshResize(slice []MeshComp, size int) []MeshComp {
 	newSlice := make([]MeshComp, size)
 	copy(newSlice, slice)
@@ -206,6 +217,8 @@ type MeshRenderFeature struct {
 	R *MeshRender
 	mt *MeshTable
 	xt *TransformTable
+
+	unmanaged unmanagedList
 }
 
 // 此处初始化所有的依赖
@@ -236,13 +249,24 @@ func (f *MeshRenderFeature) Extract(v *View) {
 		xt     = f.xt
 		fi = uint32(f.id) << 16
 	)
-	for i, m := range f.mt.comps[:f.mt.index] {
+	for _, i := range f.unmanaged.update(v, f.mt.version, f.mt.index, f.mt.EntityAt) {
+		m := &f.mt.comps[i]
 		if xf := xt.Comp(m.Entity); m.visible && camera.InView(xf,m.size,f32.Vec2{.5, .5}) {
 			sid := PackSortId(m.zOrder.value, 0)
 			val := fi + uint32(i)
 			v.RenderNodes = append(v.RenderNodes, SortObject{sid, val})
 		}
 	}
+	// culled by visibility system
+	for _, e := range v.Visible {
+		if i, ok := f.mt._map[e.Index()]; ok {
+			if m := &f.mt.comps[i]; m.visible && m.Entity == e {
+				sid := PackSortId(m.zOrder.value, 0)
+				val := fi + uint32(i)
+				v.RenderNodes = append(v.RenderNodes, SortObject{sid, val})
+			}
+		}
+	}
 }
 
 func (f *MeshRenderFeature) Draw(nodes RenderNodes) {
@@ -68,6 +68,16 @@ func (mr *MeshRender) SetCamera(camera *Camera) {
 	bk.Submit(0, mr.program, 0)
 }
 
+// Destroy frees the shader program and uniforms of the render.
+func (mr *MeshRender) Destroy() {
+	for _, id := range []uint16{mr.umhProjection, mr.umhModel, mr.umhSampler0, mr.program} {
+		if id != 0 {
+			bk.R.Free(id)
+		}
+	}
+	mr.program, mr.umhProjection, mr.umhModel, mr.umhSampler0 = 0, 0, 0, 0
+}
+
 type RenderMesh struct {
 	*Mesh
 	Matrix []float32
@@ -38,6 +38,47 @@ func (nodes RenderNodes) Less(i, j int) bool { return nodes[i].SortId < nodes[j]
 type View struct {
 	*Camera
 	RenderNodes
+
+	// entities culled by the VisibilitySystem, RenderFeature
+	// should only test entities which are not Managed.
+	V       VisibilitySystem
+	Visible []engi.Entity
+}
+
+// Managed returns true if the entity is culled by the VisibilitySystem.
+func (v *View) Managed(entity engi.Entity) bool {
+	return v.V != nil && v.V.Registered(entity)
+}
+
+// unmanagedList caches the index of comps which are not Managed, it's
+// rebuilt only when the table or the VisibilitySystem changes, so the
+// RenderFeature doesn't test every comp each frame.
+type unmanagedList struct {
+	index    []int
+	v        VisibilitySystem
+	vVersion uint32
+	tVersion uint32
+	built    bool
+}
+
+// returns the index of unmanaged comps, version is the version of table,
+// n is the size of table
+func (l *unmanagedList) update(v *View, version uint32, n int, entityAt func(i int) engi.Entity) []int {
+	var vVersion uint32
+	if v.V != nil {
+		vVersion = v.V.Version()
+	}
+	if l.built && l.v == v.V && l.vVersion == vVersion && l.tVersion == version {
+		return l.index
+	}
+	l.index = l.index[:0]
+	for i := 0; i < n; i++ {
+		if !v.Managed(entityAt(i)) {
+			l.index = append(l.index, i)
+		}
+	}
+	l.v, l.vVersion, l.tVersion, l.built = v.V, vVersion, version, true
+	return l.index
 }
 
 // 传入参数是经过可见性系统筛选后的 Entity，这是一个很小的数组，可以
@@ -74,6 +115,9 @@ type RenderSystem struct {
 
 func (th *RenderSystem) RequireTable(tables []interface{}) {
 	th.TableList = tables
+	if th.V != nil {
+		th.V.RequireTable(tables)
+	}
 	for _, table := range tables {
 		if t, ok := table.(*TransformTable); ok {
 			th.xfs = t; break
@@ -94,13 +138,13 @@ func (th *RenderSystem) RegisterRender(t RenderType, render Render) {
 
 func (th *RenderSystem) Update(dt float32) {
 	// update camera
-	if c := &th.MainCamera; c.follow != engi.Ghost {
-		xf := th.xfs.Comp(c.follow)
-		p  := xf.Position()
-		dx := (p[0]-c.mat.x)*.1
-		dy := (p[1]-c.mat.y)*.1
-		c.MoveBy(dx, dy)
+	c := &th.MainCamera
+	if c.follow != engi.Ghost && th.xfs != nil {
+		if xf := th.xfs.Comp(c.follow); xf != nil {
+			c.track(xf.Position(), dt)
+		}
 	}
+	c.Update(dt)
 
 	// main camera
 	for _, r := range th.RenderList {
@@ -112,6 +156,9 @@ func (th *RenderSystem) Update(dt float32) {
 
 	// build view
 	v := th.View
+	if v.V = th.V; v.V != nil {
+		v.Visible = th.V.Collect(&th.MainCamera)
+	}
 
 	// extract
 	for _, f := range th.FeatureList {
@@ -148,8 +195,13 @@ func (th *RenderSystem) Update(dt float32) {
 	th.View.RenderNodes = th.View.RenderNodes[:0]
 }
 
+// Destroy releases the resource held by the features.
 func (th *RenderSystem) Destroy() {
-
+	for _, f := range th.FeatureList {
+		if d, ok := f.(interface{ Destroy() }); ok {
+			d.Destroy()
+		}
+	}
 }
 
 func NewRenderSystem() (rs *RenderSystem) {
@@ -157,5 +209,6 @@ func NewRenderSystem() (rs *RenderSystem) {
 	rs.View.Camera = &rs.MainCamera
 	rs.View.RenderNodes = make([]SortObject, 0)
 	rs.MainCamera.initialize()
+	rs.V = NewVisibilitySystem(DefaultCellSize)
 	return
 }
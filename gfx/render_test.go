@@ -0,0 +1,65 @@
+package gfx
+
+import (
+	"image"
+	"testing"
+
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx/bk"
+	"korok.io/korok/gfx/dbg"
+	"korok.io/korok/math/f32"
+)
+
+// Render a sprite scene with the headless backend, sprites with the
+// same texture should be batched in one draw-call.
+func TestSpriteRenderRecord(t *testing.T) {
+	rec := bk.NewRecorder()
+	defer bk.SetBackend(bk.CurrentBackend())
+	bk.SetBackend(rec)
+	Init(1)
+	dbg.Init(480, 320)
+
+	em := engi.NewEntityManager()
+	st, xt := NewSpriteTable(1024), NewTransformTable(1024)
+
+	rs := NewRenderSystem()
+	rs.MainCamera.SetViewPort(480, 320)
+	rs.MainCamera.MoveTo(240, 160)
+	rs.RequireTable([]interface{}{st, xt})
+	rs.RegisterRender(RenderType(0), NewBatchRender("", ""))
+
+	srf := &SpriteRenderFeature{}
+	srf.Register(rs)
+
+	tex1, _ := bk.R.AllocTexture(image.NewRGBA(image.Rect(0, 0, 16, 16)))
+	tex2, _ := bk.R.AllocTexture(image.NewRGBA(image.Rect(0, 0, 16, 16)))
+
+	for i, tex := range []uint16{tex1, tex1, tex2, tex1} {
+		e := em.New()
+		st.NewCompX(e, NewTex(tex)).SetSize(10, 10)
+		xt.NewComp(e).SetPosition(f32.Vec2{float32(i * 20), 100})
+	}
+	// out of camera
+	e := em.New()
+	st.NewCompX(e, NewTex(tex2)).SetSize(10, 10)
+	xt.NewComp(e).SetPosition(f32.Vec2{-1000, 100})
+
+	rec.Clear()
+	rs.Update(0)
+	Flush()
+
+	frame := rec.LastFrame()
+	if frame == nil {
+		t.Fatal("fail to record frame")
+	}
+	draws := frame.Geometry()
+	if len(draws) != 2 {
+		t.Fatal("sprite batch draw-call, expected 2, got:", len(draws))
+	}
+	if d := draws[0]; d.Textures[0] != tex1&bk.IdMask || d.NumIndex != 3*6 {
+		t.Error("batch for tex1, got:", d.Textures[0], d.NumIndex)
+	}
+	if d := draws[1]; d.Textures[0] != tex2&bk.IdMask || d.NumIndex != 1*6 {
+		t.Error("batch for tex2, got:", d.Textures[0], d.NumIndex)
+	}
+}
@@ -87,10 +87,17 @@ func (sc *SpriteComp) Flip(flipX, flipY bool) {
 	}
 }
 
+func (sc *SpriteComp) Flipped() (flipX, flipY bool) {
+	return sc.flipX == 1, sc.flipY == 1
+}
+
 type SpriteTable struct {
 	comps []SpriteComp
 	_map   map[uint32]int
 	index, cap int
+
+	// changed when a comp is added or deleted
+	version uint32
 }
 
 func NewSpriteTable(cap int) *SpriteTable {
@@ -116,6 +123,7 @@ func (st *SpriteTable) NewComp(entity engi.Entity) (sc *SpriteComp) {
 	sc.visible = true
 	st._map[ei] = st.index
 	st.index ++
+	st.version ++
 	return
 }
 
@@ -129,7 +137,7 @@ func (st *SpriteTable) NewCompX(entity engi.Entity, spt Tex2D) (sc *SpriteComp)
 func (st *SpriteTable) Alive(entity engi.Entity) bool {
 	ei := entity.Index()
 	if v, ok := st._map[ei]; ok {
-		return st.comps[v].Entity != 0
+		return st.comps[v].Entity == entity
 	}
 	return false
 }
@@ -157,6 +165,7 @@ func (st *SpriteTable) Delete(entity engi.Entity) {
 		}
 
 		st.index -= 1
+		st.version ++
 		delete(st._map, ei)
 	}
 }
@@ -165,10 +174,16 @@ func (st *SpriteTable) Size() (size, cap int) {
 	return st.index, st.cap
 }
 
+// EntityAt returns the entity of the i-th comp, i in [0, size)
+func (st *SpriteTable) EntityAt(i int) engi.Entity {
+	return st.comps[i].Entity
+}
+
 func (st *SpriteTable) Destroy() {
 	st.comps = make([]SpriteComp, 0)
 	st._map = make(map[uint32]int)
 	st.index = 0
+	st.version ++
 }
 
 func spriteResize(slice []SpriteComp, size int) []SpriteComp {
@@ -185,6 +200,8 @@ type SpriteRenderFeature struct {
 	R *BatchRender
 	st *SpriteTable
 	xt *TransformTable
+
+	unmanaged unmanagedList
 }
 
 func (f *SpriteRenderFeature) SetRender(render *BatchRender) {
@@ -223,7 +240,8 @@ func (f *SpriteRenderFeature) Extract(v *View) {
 		xt     = f.xt
 		fi = uint32(f.id) << 16
 	)
-	for i, spr := range f.st.comps[:f.st.index] {
+	for _, i := range f.unmanaged.update(v, f.st.version, f.st.index, f.st.EntityAt) {
+		spr := &f.st.comps[i]
 		xf := xt.Comp(spr.Entity)
 		sz := f32.Vec2{spr.width, spr.height}
 		g  := f32.Vec2{spr.gravity.x, spr.gravity.y}
@@ -234,6 +252,16 @@ func (f *SpriteRenderFeature) Extract(v *View) {
 			v.RenderNodes = append(v.RenderNodes, SortObject{sid, val})
 		}
 	}
+	// culled by visibility system
+	for _, e := range v.Visible {
+		if i, ok := f.st._map[e.Index()]; ok {
+			if spr := &f.st.comps[i]; spr.visible && spr.Entity == e {
+				sid := PackSortId(spr.zOrder.value, spr.batchId.value)
+				val := fi + uint32(i)
+				v.RenderNodes = append(v.RenderNodes, SortObject{sid, val})
+			}
+		}
+	}
 }
 
 func (f *SpriteRenderFeature) Draw(nodes RenderNodes) {
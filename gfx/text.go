@@ -167,6 +167,8 @@ type TextTable struct {
 	_map   map[uint32]int
 	index, cap int
 
+	// changed when a comp is added or deleted
+	version uint32
 }
 
 func NewTextTable(cap int) *TextTable {
@@ -190,12 +192,13 @@ func (tt *TextTable) NewComp(entity engi.Entity) (tc *TextComp) {
 	tc.visible = true
 	tt._map[ei] = tt.index;
 	tt.index ++
+	tt.version ++
 	return
 }
 
 func (tt *TextTable) Alive(entity engi.Entity) bool {
 	if v, ok := tt._map[entity.Index()]; ok {
-		return tt.comps[v].Entity == 0
+		return tt.comps[v].Entity == entity
 	}
 	return false
 }
@@ -207,7 +210,7 @@ func (tt *TextTable) Comp(entity engi.Entity) (tc *TextComp) {
 	return
 }
 
-func (tt *TextTable) Delete(entity engi.Entity) (tc *TextComp) {
+func (tt *TextTable) Delete(entity engi.Entity) {
 	ei := entity.Index()
 	if v, ok := tt._map[ei]; ok {
 		if tail := tt.index -1; v != tail && tail > 0 {
@@ -222,9 +225,9 @@ func (tt *TextTable) Delete(entity engi.Entity) (tc *TextComp) {
 		}
 
 		tt.index -= 1
+		tt.version ++
 		delete(tt._map, ei)
 	}
-	return
 }
 
 // Destroy Table
@@ -232,12 +235,18 @@ func (tt *TextTable) Destroy() {
 	tt.comps = make([]TextComp, 0)
 	tt._map = make(map[uint32]int)
 	tt.index = 0
+	tt.version ++
 }
 
 func (tt *TextTable) Size() (size, cap int) {
 	return tt.index, tt.cap
 }
 
+// EntityAt returns the entity of the i-th comp, i in [0, size)
+func (tt *TextTable) EntityAt(i int) engi.Entity {
+	return tt.comps[i].Entity
+}
+
 func textResize(slice []TextComp, size int) []TextComp {
 	newSlice := make([]TextComp, size)
 	copy(newSlice, slice)
@@ -252,6 +261,8 @@ type TextRenderFeature struct {
 
 	tt *TextTable
 	xt *TransformTable
+
+	unmanaged unmanagedList
 }
 
 // 此处初始化所有的依赖
@@ -283,7 +294,8 @@ func (f *TextRenderFeature) Extract(v *View) {
 		fi     = uint32(f.id)<<16
 	)
 
-	for i, spr := range f.tt.comps[:f.tt.index] {
+	for _, i := range f.unmanaged.update(v, f.tt.version, f.tt.index, f.tt.EntityAt) {
+		spr := &f.tt.comps[i]
 		xf := xt.Comp(spr.Entity)
 		sz := f32.Vec2{spr.width, spr.height}
 		g  := f32.Vec2{spr.gravity.x, spr.gravity.y}
@@ -294,6 +306,16 @@ func (f *TextRenderFeature) Extract(v *View) {
 			v.RenderNodes = append(v.RenderNodes, SortObject{sid, val})
 		}
 	}
+	// culled by visibility system
+	for _, e := range v.Visible {
+		if i, ok := f.tt._map[e.Index()]; ok {
+			if spr := &f.tt.comps[i]; spr.visible && spr.Entity == e {
+				sid := PackSortId(spr.zOrder.value, spr.batchId.value)
+				val := fi + uint32(i)
+				v.RenderNodes = append(v.RenderNodes, SortObject{sid, val})
+			}
+		}
+	}
 }
 
 func (f *TextRenderFeature) Draw(nodes RenderNodes) {
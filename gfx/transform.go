@@ -290,33 +290,42 @@ func (tt *TransformTable) Comp(entity engi.Entity) (xf *Transform) {
 func (tt *TransformTable) Alive(entity engi.Entity) bool {
 	ei := entity.Index()
 	if v, ok := tt._map[ei]; ok {
-		return tt.comps[v].Entity != 0
+		return tt.comps[v].Entity == entity
 	}
 	return false
 }
 
 // Swap erase the TransformComp if exist
-// Delete will unlink the parent-child relation
+// Delete will unlink the parent-child relation, children of
+// the deleted TransformComp become root node.
 func (tt *TransformTable) Delete(entity engi.Entity) {
 	ei := entity.Index()
 	if v, ok := tt._map[ei]; ok {
-		if tail := tt.index -1; v != tail && tail > 0 {
+		xf := &tt.comps[v]
+		if p := xf.parent; p != none {
+			tt.comps[p].RemoveChild(xf)
+		}
+		for child := xf.firstChild; child != none; {
+			node := &tt.comps[child]
+			child = node.nxtSibling
+			node.parent, node.preSibling, node.nxtSibling = none, none, none
+		}
+
+		tail := tt.index - 1
+		if v != tail {
 			tt.comps[v] = tt.comps[tail]
 			tt.relink(uint16(tail), uint16(v))
 
 			// remap index
-			tComp := &tt.comps[tail]
-			ei := tComp.Entity.Index()
-			tt._map[ei] = v
-			tt.comps[tail].reset()
-		} else {
-			tt.comps[tail].reset()
+			tt._map[tt.comps[v].Entity.Index()] = v
 		}
+		tt.comps[tail] = Transform{}
 		tt.index -= 1
 		delete(tt._map, ei)
 	}
 }
 
+// the node at old is moved to new, fix all the links refer to it
 func (tt *TransformTable) relink(old, new uint16) {
 	xf := &tt.comps[old]
 	// relink parent
@@ -328,10 +337,15 @@ func (tt *TransformTable) relink(old, new uint16) {
 			prev.nxtSibling = new
 		}
 	}
+	// relink sibling
+	if nxt := xf.nxtSibling; nxt != none {
+		tt.comps[nxt].preSibling = new
+	}
 	// relink children
-	if child := xf.firstChild; child != none {
+	for child := xf.firstChild; child != none; {
 		node := &tt.comps[child]
 		node.parent = new
+		child = node.nxtSibling
 	}
 }
 
@@ -346,6 +360,11 @@ func (tt *TransformTable) Size() (size, cap int) {
 	return tt.index-1, tt.cap
 }
 
+// EntityAt returns the entity of the i-th comp, i in [0, size)
+func (tt *TransformTable) EntityAt(i int) engi.Entity {
+	return tt.comps[i+1].Entity
+}
+
 func transformResize(slice []Transform, size int) []Transform {
 	newSlice := make([]Transform, size)
 	copy(newSlice, slice)
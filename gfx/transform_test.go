@@ -238,4 +238,38 @@ func TestParentChildLink(t *testing.T) {
 	if pre, nxt := xf.FirstChild().Sibling(); pre != nil || nxt != xf4 {
 		t.Error("fail to keep wheel2 and wheel4")
 	}
-}
\ No newline at end of file
+}
+
+// Delete moves the tail node, links refer to the tail should be fixed.
+func TestDeleteRelink(t *testing.T) {
+	em := engi.NewEntityManager()
+	tt := NewTransformTable(1024)
+
+	// a, car -> (wheel1, wheel2, wheel3)
+	a, car := em.New(), em.New()
+	wheel1, wheel2, wheel3 := em.New(), em.New(), em.New()
+	tt.NewComp(a)
+	tt.NewComp(car)
+	tt.NewComp(wheel1)
+	tt.NewComp(wheel2)
+	tt.NewComp(wheel3)
+	tt.Comp(car).LinkChildren(tt.Comp(wheel1), tt.Comp(wheel2), tt.Comp(wheel3))
+
+	// wheel3(tail) is moved to the slot of a
+	tt.Delete(a)
+	xf, xf2, xf3 := tt.Comp(car), tt.Comp(wheel2), tt.Comp(wheel3)
+	if _, nxt := xf2.Sibling(); nxt != xf3 {
+		t.Error("fail to relink sibling")
+	}
+	if pre, _ := xf3.Sibling(); pre != xf2 || xf3.Parent() != xf {
+		t.Error("fail to relink moved node")
+	}
+
+	// delete parent, children become root
+	tt.Delete(car)
+	for _, e := range []engi.Entity{wheel1, wheel2, wheel3} {
+		if xf := tt.Comp(e); xf.Parent() != nil {
+			t.Error("fail to unlink child:", e)
+		}
+	}
+}
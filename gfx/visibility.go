@@ -3,6 +3,8 @@ package gfx
 import (
 	"korok.io/korok/math/f32"
 	"korok.io/korok/engi"
+
+	"math"
 )
 
 /// 可见性系统，以一个组件的形式呈现
@@ -23,7 +25,10 @@ func (bc *BoundingComp) SetBounding(bb *BoundingBox) {
 
 // 合并两个矩形
 func (bc *BoundingComp) Add(bb *BoundingBox) {
-
+	bc.Min[0] = float32(math.Min(float64(bc.Min[0]), float64(bb.Min[0])))
+	bc.Min[1] = float32(math.Min(float64(bc.Min[1]), float64(bb.Min[1])))
+	bc.Max[0] = float32(math.Max(float64(bc.Max[0]), float64(bb.Max[0])))
+	bc.Max[1] = float32(math.Max(float64(bc.Max[1]), float64(bb.Max[1])))
 }
 
 // 减去一个矩形的贡献
@@ -49,10 +54,29 @@ func (bt *BoundingTable) Comp(entity engi.Entity) (bc *BoundingComp) {
 	return
 }
 
-
+// VisibilitySystem 负责筛选注册过的 Entity，RenderFeature 会跳过这些 Entity
+// 的可见性测试，直接使用 Collect 的结果; 没有注册的 Entity 仍由 RenderFeature
+// 逐个测试。
 type VisibilitySystem interface {
+	RequireTable(tables []interface{})
+
+	// 静态对象的 BoundingBox 是世界坐标，动态对象的 BoundingBox 是相对于
+	// Transform 的局部坐标，会随 Entity 移动
+	RegisterStatic(entity engi.Entity, bb BoundingBox) int32
+	RegisterDynamic(entity engi.Entity, bb BoundingBox) int32
 	UpdateBounding(id int32, bb BoundingBox)
-	UpdateTransform()
+	Unregister(id int32)
+
+	// unregister the entity when it's destroyed, see engi.CompTable
+	Delete(entity engi.Entity)
+
+	// 是否由可见性系统负责
+	Registered(entity engi.Entity) bool
+
+	// Version is changed when an entity is registered or unregistered
+	Version() uint32
+
+	// 返回相机可见的对象集合, 结果在下次调用前有效
 	Collect(camera *Camera) []engi.Entity
 }
 
@@ -60,29 +84,265 @@ type VisibilitySystem interface {
 // 对于动态的对象，最好的做法还是跑一遍 O(N) 的循环，
 // 这样可以避免因维护算法带来的开销
 
+const DefaultCellSize = 256
+
+type visibleObject struct {
+	engi.Entity
+	BoundingBox
+	static bool
+	// the last Collect this object is added, avoid duplicated
+	// result when the object overlaps several cells
+	stamp uint32
+}
+
+type cellKey struct {
+	x, y int32
+}
+
+// 静态对象保存在均匀网格中，每个格子记录与其相交的对象
 type visibilitySystem struct {
+	cellSize float32
+
+	xt *TransformTable
+
+	objects []visibleObject
+	free    []int32
+
+	// entity-index -> object id + 1, zero means unregistered
+	lookup []int32
+
+	grid    map[cellKey][]int32
+	dynamic []int32
+
+	stamp   uint32
+	visible []engi.Entity
+
+	version uint32
+}
+
+func NewVisibilitySystem(cellSize float32) VisibilitySystem {
+	if cellSize <= 0 {
+		cellSize = DefaultCellSize
+	}
+	return &visibilitySystem{
+		cellSize: cellSize,
+		grid:     make(map[cellKey][]int32),
+	}
+}
+
+func (vs *visibilitySystem) RequireTable(tables []interface{}) {
+	for _, t := range tables {
+		switch table := t.(type) {
+		case *TransformTable:
+			vs.xt = table
+		}
+	}
 }
 
 // 返回相机可见的对象集合
-func (*visibilitySystem) Collect(camera *Camera) []engi.Entity{
-	return nil
+func (vs *visibilitySystem) Collect(camera *Camera) []engi.Entity {
+	vs.stamp++
+	vs.visible = vs.visible[:0]
+	view := camera.viewAABB()
+
+	// static: grid
+	x0, y0 := vs.cell(view.x, view.y)
+	x1, y1 := vs.cell(view.x+view.width, view.y+view.height)
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			for _, id := range vs.grid[cellKey{x, y}] {
+				obj := &vs.objects[id]
+				if obj.stamp == vs.stamp {
+					continue
+				}
+				obj.stamp = vs.stamp
+				if a := obj.aabb(); OverlapAB(&a, &view) {
+					vs.visible = append(vs.visible, obj.Entity)
+				}
+			}
+		}
+	}
+
+	// dynamic: O(N)
+	for _, id := range vs.dynamic {
+		obj := &vs.objects[id]
+		var a AABB
+		if xf := vs.transform(obj.Entity); xf != nil {
+			a = transformBounding(xf, &obj.BoundingBox)
+		} else {
+			a = obj.aabb()
+		}
+		if OverlapAB(&a, &view) {
+			vs.visible = append(vs.visible, obj.Entity)
+		}
+	}
+	return vs.visible
 }
 
 // 在此注册一个 静态的游戏对象
-func (*visibilitySystem) RegisterStatic(entity engi.Entity, bb BoundingBox) int32{
-	return 0
+func (vs *visibilitySystem) RegisterStatic(entity engi.Entity, bb BoundingBox) int32 {
+	id := vs.register(entity, bb, true)
+	vs.insert(id)
+	return id
 }
 
 // 注册一个 动态的游戏对象
-func (*visibilitySystem) RegisterDynamic(entity engi.Entity, bb BoundingBox) int32 {
-	return 0
+func (vs *visibilitySystem) RegisterDynamic(entity engi.Entity, bb BoundingBox) int32 {
+	id := vs.register(entity, bb, false)
+	vs.dynamic = append(vs.dynamic, id)
+	return id
 }
 
 // 更新游戏对象的 AABB
-func (*visibilitySystem) UpdateBounding(id int32, bb BoundingBox) {
+func (vs *visibilitySystem) UpdateBounding(id int32, bb BoundingBox) {
+	if !vs.valid(id) {
+		return
+	}
+	if obj := &vs.objects[id]; obj.static {
+		vs.remove(id)
+		obj.BoundingBox = bb
+		vs.insert(id)
+	} else {
+		obj.BoundingBox = bb
+	}
+}
 
+func (vs *visibilitySystem) Unregister(id int32) {
+	if !vs.valid(id) {
+		return
+	}
+	obj := &vs.objects[id]
+	if obj.static {
+		vs.remove(id)
+	} else {
+		for i, v := range vs.dynamic {
+			if v == id {
+				vs.dynamic = append(vs.dynamic[:i], vs.dynamic[i+1:]...)
+				break
+			}
+		}
+	}
+	if ei := obj.Entity.Index(); int(ei) < len(vs.lookup) && vs.lookup[ei] == id+1 {
+		vs.lookup[ei] = 0
+	}
+	*obj = visibleObject{Entity: engi.Ghost}
+	vs.free = append(vs.free, id)
+	vs.version++
 }
 
+func (vs *visibilitySystem) Delete(entity engi.Entity) {
+	if vs.Registered(entity) {
+		vs.Unregister(vs.lookup[entity.Index()] - 1)
+	}
+}
 
+func (vs *visibilitySystem) Registered(entity engi.Entity) bool {
+	ei := entity.Index()
+	if int(ei) >= len(vs.lookup) {
+		return false
+	}
+	id := vs.lookup[ei]
+	return id > 0 && vs.objects[id-1].Entity == entity
+}
 
+func (vs *visibilitySystem) register(entity engi.Entity, bb BoundingBox, static bool) (id int32) {
+	// an entity can only be registered once
+	vs.Delete(entity)
+	if n := len(vs.free); n > 0 {
+		id = vs.free[n-1]
+		vs.free = vs.free[:n-1]
+	} else {
+		id = int32(len(vs.objects))
+		vs.objects = append(vs.objects, visibleObject{})
+	}
+	vs.objects[id] = visibleObject{Entity: entity, BoundingBox: bb, static: static}
+
+	ei := int(entity.Index())
+	if ei >= len(vs.lookup) {
+		vs.lookup = append(vs.lookup, make([]int32, ei+1-len(vs.lookup))...)
+	}
+	vs.lookup[ei] = id + 1
+	vs.version++
+	return
+}
+
+func (vs *visibilitySystem) Version() uint32 {
+	return vs.version
+}
+
+func (vs *visibilitySystem) valid(id int32) bool {
+	return id >= 0 && int(id) < len(vs.objects) && vs.objects[id].Entity != engi.Ghost
+}
+
+// add static object to the cells it overlaps
+func (vs *visibilitySystem) insert(id int32) {
+	bb := &vs.objects[id].BoundingBox
+	x0, y0 := vs.cell(bb.Min[0], bb.Min[1])
+	x1, y1 := vs.cell(bb.Max[0], bb.Max[1])
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			key := cellKey{x, y}
+			vs.grid[key] = append(vs.grid[key], id)
+		}
+	}
+}
 
+func (vs *visibilitySystem) remove(id int32) {
+	bb := &vs.objects[id].BoundingBox
+	x0, y0 := vs.cell(bb.Min[0], bb.Min[1])
+	x1, y1 := vs.cell(bb.Max[0], bb.Max[1])
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			key := cellKey{x, y}
+			list := vs.grid[key]
+			for i, v := range list {
+				if v == id {
+					list[i] = list[len(list)-1]
+					list = list[:len(list)-1]
+					break
+				}
+			}
+			if len(list) == 0 {
+				delete(vs.grid, key)
+			} else {
+				vs.grid[key] = list
+			}
+		}
+	}
+}
+
+func (vs *visibilitySystem) cell(x, y float32) (cx, cy int32) {
+	cx = int32(math.Floor(float64(x / vs.cellSize)))
+	cy = int32(math.Floor(float64(y / vs.cellSize)))
+	return
+}
+
+func (vs *visibilitySystem) transform(entity engi.Entity) *Transform {
+	if vs.xt == nil {
+		return nil
+	}
+	return vs.xt.Comp(entity)
+}
+
+func (obj *visibleObject) aabb() AABB {
+	return AABB{obj.Min[0], obj.Min[1], obj.Max[0] - obj.Min[0], obj.Max[1] - obj.Min[1]}
+}
+
+// 把局部坐标的 BoundingBox 转换到世界坐标
+func transformBounding(xf *Transform, bb *BoundingBox) AABB {
+	srt := xf.world
+	m := mat3{}; m.Initialize(srt.Position[0], srt.Position[1], srt.Rotation, srt.Scale[0], srt.Scale[1])
+
+	// center and extent
+	cx, cy := (bb.Min[0]+bb.Max[0])/2, (bb.Min[1]+bb.Max[1])/2
+	ex, ey := (bb.Max[0]-bb.Min[0])/2, (bb.Max[1]-bb.Min[1])/2
+
+	cx, cy = m.TransformCoord(cx, cy)
+	for i, v := range m {
+		if v < 0 {
+			m[i] = -v
+		}
+	}
+	ex, ey = m.TransformNormal(ex, ey)
+	return AABB{cx-ex, cy-ey, ex*2, ey*2}
+}
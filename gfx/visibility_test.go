@@ -0,0 +1,149 @@
+package gfx
+
+import (
+	"testing"
+
+	"korok.io/korok/engi"
+	"korok.io/korok/math/f32"
+)
+
+func TestVisibilitySystem(t *testing.T) {
+	em := engi.NewEntityManager()
+	st, xt := NewSpriteTable(1024), NewTransformTable(1024)
+
+	rs := NewRenderSystem()
+	rs.MainCamera.SetViewPort(480, 320)
+	rs.MainCamera.MoveTo(240, 160)
+	rs.RequireTable([]interface{}{st, xt})
+
+	srf := &SpriteRenderFeature{}
+	srf.Register(rs)
+
+	// 100x100 static tiles, 32x32 each
+	var expected int
+	view := rs.MainCamera.viewAABB()
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			e := em.New()
+			st.NewComp(e).SetSize(32, 32)
+			xt.NewComp(e).SetPosition(f32.Vec2{float32(x*32 + 16), float32(y*32 + 16)})
+
+			bb := BoundingBox{f32.Vec2{float32(x * 32), float32(y * 32)}, f32.Vec2{float32(x*32 + 32), float32(y*32 + 32)}}
+			rs.V.RegisterStatic(e, bb)
+
+			if a := (AABB{bb.Min[0], bb.Min[1], 32, 32}); OverlapAB(&a, &view) {
+				expected++
+			}
+		}
+	}
+
+	// a dynamic sprite, out of camera
+	player := em.New()
+	st.NewComp(player).SetSize(32, 32)
+	xf := xt.NewComp(player)
+	xf.SetPosition(f32.Vec2{-100, -100})
+	rs.V.RegisterDynamic(player, BoundingBox{f32.Vec2{-16, -16}, f32.Vec2{16, 16}})
+
+	// an unregistered sprite, in camera
+	e := em.New()
+	st.NewComp(e).SetSize(32, 32)
+	xt.NewComp(e).SetPosition(f32.Vec2{100, 100})
+
+	extract := func() int {
+		v := View{Camera: &rs.MainCamera, V: rs.V}
+		v.Visible = rs.V.Collect(&rs.MainCamera)
+		srf.Extract(&v)
+		return len(v.RenderNodes)
+	}
+
+	if n := extract(); n != expected+1 {
+		t.Errorf("visible sprite, expected %d, got %d", expected+1, n)
+	}
+
+	xf.SetPosition(f32.Vec2{240, 160})
+	if n := extract(); n != expected+2 {
+		t.Errorf("dynamic sprite should be visible, expected %d, got %d", expected+2, n)
+	}
+
+	// move static tile out of camera
+	id := rs.V.RegisterStatic(em.New(), BoundingBox{f32.Vec2{0, 0}, f32.Vec2{10, 10}})
+	if n := len(rs.V.Collect(&rs.MainCamera)); n != expected+2 {
+		t.Error("new static tile should be collected, got:", n)
+	}
+	rs.V.UpdateBounding(id, BoundingBox{f32.Vec2{-100, -100}, f32.Vec2{-90, -90}})
+	if n := len(rs.V.Collect(&rs.MainCamera)); n != expected+1 {
+		t.Error("moved static tile should not be collected, got:", n)
+	}
+	rs.V.Unregister(id)
+	if n := len(rs.V.Collect(&rs.MainCamera)); n != expected+1 {
+		t.Error("unregister static tile, got:", n)
+	}
+}
+
+func TestVisibilityUnregisterTwice(t *testing.T) {
+	em := engi.NewEntityManager()
+	vs := NewVisibilitySystem(0)
+	bb := BoundingBox{f32.Vec2{0, 0}, f32.Vec2{10, 10}}
+
+	a := em.New()
+	id := vs.RegisterStatic(a, bb)
+	vs.Unregister(id)
+	vs.Unregister(id)
+	if vs.Registered(a) {
+		t.Error("entity should be unregistered")
+	}
+
+	// the slot is reused only once
+	b, c := em.New(), em.New()
+	ib, ic := vs.RegisterDynamic(b, bb), vs.RegisterDynamic(c, bb)
+	if ib == ic {
+		t.Errorf("two entities share the slot %d", ib)
+	}
+	if !vs.Registered(b) || !vs.Registered(c) {
+		t.Error("entities should be registered")
+	}
+}
+
+func TestUnmanagedList(t *testing.T) {
+	em := engi.NewEntityManager()
+	st, xt := NewSpriteTable(16), NewTransformTable(16)
+	rs := NewRenderSystem()
+	rs.MainCamera.SetViewPort(480, 320)
+	rs.MainCamera.MoveTo(240, 160)
+	rs.RequireTable([]interface{}{st, xt})
+	srf := &SpriteRenderFeature{}
+	srf.Register(rs)
+
+	newSprite := func() engi.Entity {
+		e := em.New()
+		st.NewComp(e).SetSize(32, 32)
+		xt.NewComp(e).SetPosition(f32.Vec2{100, 100})
+		return e
+	}
+	extract := func() int {
+		v := View{Camera: &rs.MainCamera, V: rs.V}
+		v.Visible = rs.V.Collect(&rs.MainCamera)
+		srf.Extract(&v)
+		return len(v.RenderNodes)
+	}
+
+	a, _ := newSprite(), newSprite()
+	id := rs.V.RegisterDynamic(a, BoundingBox{f32.Vec2{-16, -16}, f32.Vec2{16, 16}})
+	if n := extract(); n != 2 || len(srf.unmanaged.index) != 1 {
+		t.Errorf("registered sprite: %d, unmanaged %d", n, len(srf.unmanaged.index))
+	}
+
+	// the cache is rebuilt when the registration or table changes
+	rs.V.Unregister(id)
+	if n := extract(); n != 2 || len(srf.unmanaged.index) != 2 {
+		t.Errorf("unregistered sprite: %d, unmanaged %d", n, len(srf.unmanaged.index))
+	}
+	newSprite()
+	if n := extract(); n != 3 {
+		t.Errorf("new sprite: %d", n)
+	}
+	st.Delete(a)
+	if n := extract(); n != 2 {
+		t.Errorf("deleted sprite: %d", n)
+	}
+}
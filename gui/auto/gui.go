@@ -60,8 +60,11 @@ func TextSizeColored(id gui.ID, text string, color gfx.Color, size float32, opt
 }
 
 // Widgets: InputEditor
-func InputText(hint string, style *gui.InputStyle, p *Options) {
-
+func InputText(id gui.ID, text *string, hint string, style *gui.InputStyle, p *Options) gui.EventType {
+	if style == nil {
+		style = &gContext.Theme.Input
+	}
+	return gLayoutMan.InputText(id, text, hint, style, p)
 }
 
 // Widget: Image
@@ -103,9 +106,13 @@ func Slider(id gui.ID, value *float32, style *gui.SliderStyle, p *Options) (v gu
 	return gLayoutMan.Slider(id, value, style, p)
 }
 
-// Widget: ListView TODO
-func ListView() {
-
+// Widget: ListView, the item should be drawn with the immediate gui
+// functions, bb of the item is relative to the list.
+func ListView(id gui.ID, count int, itemHeight float32, item func(i int, bb gui.Rect), style *gui.ListViewStyle, p *Options) gui.EventType {
+	if style == nil {
+		style = &gContext.Theme.ListView
+	}
+	return gLayoutMan.ListView(id, count, itemHeight, item, style, p)
 }
 
 // Layout & Group
@@ -50,8 +50,29 @@ func (lm *LayoutMan) Text(id g.ID, text string, style *g.TextStyle, opt *Options
 }
 
 // Widgets: InputEditor
-func (lm *LayoutMan) InputText(hint string, lyt layout, style *g.InputStyle) {
+func (lm *LayoutMan) InputText(id g.ID, text *string, hint string, style *g.InputStyle, opt *Options) (e g.EventType) {
+	var (
+		elem, ready = lm.BeginElement(id, opt)
+	)
+
+	if ready {
+		e = lm.Context.InputText(id, &elem.Rect, text, hint, style)
+	} else {
+		if opt != nil {
+			elem.W = opt.W
+			elem.H = opt.H
+		}
+		// 默认宽度 120，高度由字体大小决定
+		if elem.W == 0 {
+			elem.W = 120
+		}
+		if elem.H == 0 {
+			elem.H = style.Size + style.Padding.Top + style.Padding.Bottom
+		}
+	}
 
+	lm.EndElement(elem)
+	return
 }
 
 // Widget: Image
@@ -161,6 +182,31 @@ func (lm *LayoutMan) Slider(id g.ID, value *float32, style *g.SliderStyle, opt *
 	return
 }
 
+// ListView 需要固定的大小，默认为 120x160
+func (lm *LayoutMan) ListView(id g.ID, count int, itemHeight float32, item func(i int, bb g.Rect), style *g.ListViewStyle, opt *Options) (e g.EventType) {
+	var (
+		elem, ready = lm.BeginElement(id, opt)
+	)
+
+	if ready {
+		e = lm.Context.ListView(id, &elem.Rect, count, itemHeight, item, style)
+	} else {
+		if opt != nil {
+			elem.W = opt.W
+			elem.H = opt.H
+		}
+		if elem.W == 0 {
+			elem.W = 120
+		}
+		if elem.H == 0 {
+			elem.H = 160
+		}
+	}
+
+	lm.EndElement(elem)
+	return
+}
+
 func (lm *LayoutMan) DefineLayout(name string, xt ViewType) {
 	if l, ok := lm.layouts[name]; ok {
 		lm.current = l
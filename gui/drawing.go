@@ -206,10 +206,9 @@ func (dl *DrawList) PushClipRect(min, max f32.Vec2, intersectCurrentClip bool) {
 		}
 		cr[2] = math.Max(cr[0], cr[2])
 		cr[3] = math.Max(cr[1], cr[3])
-
-		dl.ClipRectStack = append(dl.ClipRectStack, cr)
-		dl.UpdateClipRect()
 	}
+	dl.ClipRectStack = append(dl.ClipRectStack, cr)
+	dl.UpdateClipRect()
 }
 
 func (dl *DrawList) PushClipRectFullScreen() {
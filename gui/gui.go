@@ -23,9 +23,9 @@ func TextSizeColored(id ID, bb Rect, text string, color gfx.Color, size float32)
 	gContext.Text(id, &bb,text, &sty)
 }
 
-// Widgets: InputEditor
-func InputText(id ID, bb Rect, hint string, style *InputStyle) {
-	gContext.InputText(id, &bb, hint, style)
+// Widgets: InputEditor, edits the text when it has the focus.
+func InputText(id ID, bb Rect, text *string, hint string, style *InputStyle) EventType {
+	return gContext.InputText(id, &bb, text, hint, style)
 }
 
 // Widget: Image
@@ -60,9 +60,9 @@ func ColorRect(bb Rect, fill gfx.Color, rounding float32) {
 	gContext.DrawRect(&bb, fill, rounding)
 }
 
-// Widget: ListView TODO
-func ListView() {
-
+// Widget: ListView, only the visible items are drawn by the item callback.
+func ListView(id ID, bb Rect, count int, itemHeight float32, item func(i int, bb Rect), style *ListViewStyle) EventType {
+	return gContext.ListView(id, &bb, count, itemHeight, item, style)
 }
 
 // Offset move the ui coordinate's origin by (dx, dy)
@@ -77,6 +77,15 @@ func Move(x, y float32) {
 	gContext.Cursor.Y = y
 }
 
+// Focus: the focused widget receives the keyboard input.
+func SetFocus(id ID) {
+	gContext.SetFocus(id)
+}
+
+func ClearFocus() {
+	gContext.ClearFocus()
+}
+
 // Theme:
 func UseTheme(style *Theme) {
 	gContext.UseTheme(style)
@@ -95,6 +104,11 @@ func SetZOrder(z int16) (old int16){
 	return
 }
 
+// for internal usage, DO NOT call.
+func SetDeltaTime(dt float32) {
+	gContext.dt = dt
+}
+
 // for internal usage, DO NOT call.
 func SetScreenSize(w, h float32) {
 	screen.SetRealSize(w, h)
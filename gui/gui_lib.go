@@ -16,6 +16,8 @@ const (
 	EventStartDrag
 	EventEndDrag
 	EventDragging
+	EventChanged
+	EventSubmit
 )
 
 const EventNone = EventType(0)
@@ -44,6 +46,14 @@ func (et EventType) Dragging() bool {
 	return (et & EventDragging) != 0
 }
 
+func (et EventType) Changed() bool {
+	return (et & EventChanged) != 0
+}
+
+func (et EventType) Submit() bool {
+	return (et & EventSubmit) != 0
+}
+
 // UI绘制边界
 type Rect struct {
 	X, Y float32
@@ -94,19 +104,37 @@ type Context struct {
 
 		isLastEventPointerType bool
 		pointerCapture ID
+
+		// focus state, focusNext moves the focus to next InputText
+		focus ID
+		focusNext bool
 	}
 
+	// the focused text editor
+	edit textEdit
+
 	// sqNum should be same for  layout and drawing
 	sqNum int
+
+	// scroll state of list views
+	scrolls map[ID]*scrollState
+	// cursor saved by StartScroll
+	scrollStack []Cursor
+
+	// frame time in seconds
+	dt float32
 }
 
 func NewContext(style *Theme) *Context {
 	c := &Context{
 		Theme: style,
+		scrolls: make(map[ID]*scrollState),
 	}
 	c.state.draggingPointer = -1
 	c.state.isLastEventPointerType = false
 	c.state.pointerCapture = -1
+	c.state.focus = -1
+	c.edit.id = -1
 	c.DrawList.Initialize()
 	return c
 }
@@ -127,9 +155,227 @@ func (ctx *Context) Text(id ID, bb *Rect, text string, style *TextStyle) {
 	return
 }
 
-// Widgets: InputEditor
-func (ctx *Context) InputText(id ID, bb *Rect, hint string, style *InputStyle) {
+// Widgets: InputEditor, the text is edited when the widget has the focus.
+// Returns EventChanged if the text is changed, EventSubmit if enter is pressed.
+func (ctx *Context) InputText(id ID, bb *Rect, text *string, hint string, style *InputStyle) (e EventType) {
+	if style == nil {
+		style = &ctx.Theme.Input
+	}
+	var (
+		fnt = style.Font
+		pad = style.Padding
+		te  = &ctx.edit
+	)
+	if fnt == nil {
+		fnt = ctx.Theme.Font
+	}
+	if bb.W == 0 {
+		bb.W = 120
+	}
+	if bb.H == 0 {
+		bb.H = style.Size + pad.Top + pad.Bottom
+	}
+
+	// focus by tab or click, a click outside loses the focus
+	tabbed := ctx.state.focusNext
+	if tabbed {
+		ctx.state.focusNext = false
+		ctx.SetFocus(id)
+	}
+	event := ctx.ClickEvent(id, bb)
+	if (event & EventWentDown) != 0 {
+		ctx.SetFocus(id)
+	} else if ctx.state.focus == id && input.PointerButton(0).JustPressed() {
+		ctx.ClearFocus()
+	}
+
+	focused := ctx.state.focus == id
+	if focused {
+		if te.id != id || te.String() != *text {
+			te.reset(id, *text)
+		}
+		te.maxLength = style.MaxLength
+		te.password = style.Password
+
+		// move caret to the pointer
+		if (event & (EventWentDown | EventDown)) != 0 {
+			offsets := textOffsets(maskText(te.text, style.Password), fnt, style.Size)
+			x := input.PointerPosition(0).MousePos[0]/screen.scaleX - ctx.Cursor.X - bb.X - pad.Left + te.scroll
+			shift := (event&EventWentDown) == 0 || input.KeyDown(input.LeftShift) || input.KeyDown(input.RightShift)
+			te.moveTo(nearestOffset(offsets, x), shift)
+		}
+		// the keys moved the focus here are not for this widget
+		if !tabbed {
+			if e = ctx.editText(te); (e & EventChanged) != 0 {
+				*text = te.String()
+			}
+		}
+		focused = ctx.state.focus == id
+	}
+
+	// the displayed text
+	var display []rune
+	if focused {
+		display = te.text
+	} else {
+		display = []rune(*text)
+	}
+	display = maskText(display, style.Password)
+	offsets := textOffsets(display, fnt, style.Size)
+	inner := Rect{bb.X + pad.Left, bb.Y + pad.Top, bb.W - pad.Left - pad.Right, bb.H - pad.Top - pad.Bottom}
+
+	// frame
+	ctx.DrawRect(bb, style.Background, style.Rounding)
+	ctx.PushClipRect(f32.Vec2{inner.X, bb.Y}, f32.Vec2{inner.X + inner.W, bb.Y + bb.H}, true)
+
+	var scroll float32
+	if focused {
+		// keep the caret visible
+		cx := offsets[te.caret]
+		if cx-te.scroll > inner.W {
+			te.scroll = cx - inner.W
+		}
+		if cx < te.scroll {
+			te.scroll = cx
+		}
+		scroll = te.scroll
+		if te.hasSelection() {
+			start, end := te.selection()
+			ctx.DrawRect(&Rect{inner.X - scroll + offsets[start], inner.Y, offsets[end] - offsets[start], inner.H}, style.Selection, 0)
+		}
+	}
+	if len(display) > 0 {
+		ctx.drawString(inner.X-scroll, inner.Y, string(display), fnt, style.Size, style.Color)
+	} else if hint != "" {
+		ctx.drawString(inner.X, inner.Y, hint, fnt, style.Size, style.HintColor)
+	}
+	// caret blinks every 0.5 second
+	if focused {
+		if int(te.blink*2)%2 == 0 {
+			ctx.DrawRect(&Rect{inner.X - scroll + offsets[te.caret], inner.Y, 1, inner.H}, style.Caret, 0)
+		}
+		te.blink += ctx.dt
+	}
+	ctx.PopClipRect()
+	e |= event
+	return
+}
+
+// editText handles the keys and characters of the frame.
+func (ctx *Context) editText(te *textEdit) (e EventType) {
+	var (
+		shift   = input.KeyDown(input.LeftShift) || input.KeyDown(input.RightShift)
+		ctrl    = input.KeyDown(input.LeftControl) || input.KeyDown(input.RightControl) || input.KeyDown(input.LeftSuper) || input.KeyDown(input.RightSuper)
+		changed bool
+	)
+	for _, k := range input.PressedKeys() {
+		switch k {
+		case input.ArrowLeft:
+			te.moveLeft(shift)
+		case input.ArrowRight:
+			te.moveRight(shift)
+		case input.Home:
+			te.moveTo(0, shift)
+		case input.End:
+			te.moveTo(len(te.text), shift)
+		case input.Backspace:
+			changed = te.backspace() || changed
+		case input.Delete:
+			changed = te.delete() || changed
+		case input.Enter:
+			e |= EventSubmit
+			ctx.ClearFocus()
+		case input.Escape:
+			ctx.ClearFocus()
+		case input.Tab:
+			ctx.ClearFocus()
+			ctx.state.focusNext = true
+		case input.A:
+			if ctrl {
+				te.selectAll()
+			}
+		case input.C:
+			if ctrl {
+				te.copy()
+			}
+		case input.X:
+			if ctrl {
+				changed = te.cut() || changed
+			}
+		case input.V:
+			if ctrl {
+				changed = te.paste() || changed
+			}
+		}
+	}
+	if chars := input.Chars(); len(chars) > 0 && !ctrl {
+		changed = te.insert(chars) || changed
+	}
+	if changed {
+		e |= EventChanged
+	}
+	return
+}
+
+// SetFocus moves the focus to the widget.
+func (ctx *Context) SetFocus(id ID) {
+	if ctx.state.focus != id {
+		ctx.edit.id = -1
+	}
+	ctx.state.focus = id
+}
+
+// ClearFocus removes the focus of the focused widget.
+func (ctx *Context) ClearFocus() {
+	ctx.state.focus = -1
+}
 
+// Focus returns the id of the focused widget, -1 if no widget has the focus.
+func (ctx *Context) Focus() ID {
+	return ctx.state.focus
+}
+
+// replaces each rune with '*' if masked
+func maskText(text []rune, masked bool) []rune {
+	if !masked {
+		return text
+	}
+	mask := make([]rune, len(text))
+	for i := range mask {
+		mask[i] = '*'
+	}
+	return mask
+}
+
+// returns the x offset of each caret position, len(offsets) = len(text)+1
+func textOffsets(text []rune, fnt font.Font, fontSize float32) []float32 {
+	offsets := make([]float32, len(text)+1)
+	if fnt == nil || len(text) == 0 {
+		return offsets
+	}
+	_, gh := fnt.Bounds()
+	scale := fontSize / gh
+	for i, r := range text {
+		g, _ := fnt.Glyph(r)
+		offsets[i+1] = offsets[i] + float32(g.Advance)*scale
+	}
+	return offsets
+}
+
+// returns the caret position nearest to x
+func nearestOffset(offsets []float32, x float32) int {
+	for i := 1; i < len(offsets); i++ {
+		if x < (offsets[i-1]+offsets[i])/2 {
+			return i - 1
+		}
+	}
+	return len(offsets) - 1
+}
+
+func (ctx *Context) drawString(x, y float32, text string, fnt font.Font, size float32, color uint32) {
+	x, y = Gui2Game(x+ctx.Cursor.X, y+ctx.Cursor.Y)
+	pos := f32.Vec2{x * screen.scaleX, y * screen.scaleY}
+	ctx.DrawList.AddText(pos, text, fnt, size*screen.scaleX, color, 0)
 }
 
 // Widget: Image
@@ -223,14 +469,165 @@ func (ctx *Context) Slider(id ID, bb *Rect, value *float32, style *SliderStyle)
 	return
 }
 
+// ListView draws a vertical list of fixed height items, only the visible
+// items are drawn by the item callback, bb of the item is relative to the
+// list. The list can be scrolled by dragging and flings after release.
+func (ctx *Context) ListView(id ID, bb *Rect, count int, itemHeight float32, item func(i int, bb Rect), style *ListViewStyle) (e EventType) {
+	if style == nil {
+		style = &ctx.Theme.ListView
+	}
+	s, ok := ctx.scrolls[id]
+	if !ok {
+		s = &scrollState{}
+		ctx.scrolls[id] = s
+	}
+	content := float32(count) * itemHeight
+	e = ctx.checkScroll(id, bb, s)
+	s.update(ctx.dt, content-bb.H, style.Friction)
+
+	if style.Background != (gfx.Color{}) {
+		ctx.DrawRect(bb, style.Background, 0)
+	}
+
+	// draw visible items in the clipped region
+	cursor := ctx.Cursor
+	ctx.Cursor.X += bb.X
+	ctx.Cursor.Y += bb.Y
+	ctx.StartScroll(f32.Vec2{bb.W, bb.H}, f32.Vec2{0, s.offset})
+	first, last := visibleRange(s.offset, bb.H, itemHeight, count)
+	for i := first; i < last; i++ {
+		item(i, Rect{0, float32(i) * itemHeight, bb.W, itemHeight})
+	}
+	ctx.EndScroll()
+
+	// scroll bar
+	if content > bb.H && style.BarWidth > 0 {
+		h := bb.H * bb.H / content
+		y := s.offset / content * bb.H
+		ctx.DrawRect(&Rect{bb.W - style.BarWidth, y, style.BarWidth, h}, style.Bar, style.BarWidth/2)
+	}
+	ctx.Cursor = cursor
+	return
+}
+
+// checkScroll updates the scroll offset by dragging.
+func (ctx *Context) checkScroll(id ID, bound *Rect, s *scrollState) (e EventType) {
+	event := ctx.DraggingEvent(id, bound)
+	y := input.PointerPosition(0).MousePos[1] / screen.scaleY
+	if (event & EventStartDrag) != 0 {
+		ctx.state.pointerCapture = id
+		s.dragging = true
+		s.velocity = 0
+		s.last = y
+	}
+	if (event & EventDragging) != 0 {
+		dy := y - s.last
+		s.offset -= dy
+		if ctx.dt > 0 {
+			// smooth the velocity, it's used to fling
+			s.velocity = s.velocity*.2 - dy/ctx.dt*.8
+		}
+		s.last = y
+	}
+	if (event & EventEndDrag) != 0 {
+		ctx.state.pointerCapture = -1
+		s.dragging = false
+	}
+	// tap to stop flinging
+	if btn := input.PointerButton(0); btn.JustPressed() && !s.dragging {
+		c := ctx.Cursor
+		bb := Rect{(c.X+bound.X)*screen.scaleX, (c.Y+bound.Y)*screen.scaleY, bound.W*screen.scaleX, bound.H*screen.scaleY}
+		if bb.InRange(input.PointerPosition(0).MousePos) {
+			s.velocity = 0
+		}
+	}
+	e = event
+	return
+}
+
 // Scroll 效果的关键是使用裁切限制滚动区域，然后
 // 通过计算拖拽，来得到争取的偏移
 func (ctx *Context) StartScroll(size, offset f32.Vec2) {
-
+	ctx.PushClipRect(f32.Vec2{0, 0}, size, true)
+	ctx.scrollStack = append(ctx.scrollStack, ctx.Cursor)
+	ctx.Cursor.X -= offset[0]
+	ctx.Cursor.Y -= offset[1]
 }
 
 func (ctx *Context) EndScroll() {
+	if n := len(ctx.scrollStack); n > 0 {
+		ctx.Cursor = ctx.scrollStack[n-1]
+		ctx.scrollStack = ctx.scrollStack[:n-1]
+		ctx.PopClipRect()
+	}
+}
+
+// ScrollOffset returns the scroll offset of the list view.
+func (ctx *Context) ScrollOffset(id ID) float32 {
+	if s, ok := ctx.scrolls[id]; ok {
+		return s.offset
+	}
+	return 0
+}
+
+// SetScrollOffset scrolls the list view to the offset and stops flinging.
+func (ctx *Context) SetScrollOffset(id ID, offset float32) {
+	s, ok := ctx.scrolls[id]
+	if !ok {
+		s = &scrollState{}
+		ctx.scrolls[id] = s
+	}
+	s.offset, s.velocity = offset, 0
+}
+
+// 滚动状态, 拖拽结束之后按照速度继续滚动并逐渐减速
+type scrollState struct {
+	offset, velocity float32
+	// last pointer position in dragging
+	last     float32
+	dragging bool
+}
 
+// max is the max offset, friction is the ratio of velocity lost per second.
+func (s *scrollState) update(dt, max, friction float32) {
+	if !s.dragging && s.velocity != 0 {
+		s.offset += s.velocity * dt
+		if k := 1 - friction*dt; k > 0 {
+			s.velocity *= k
+		} else {
+			s.velocity = 0
+		}
+		if s.velocity > -1 && s.velocity < 1 {
+			s.velocity = 0
+		}
+	}
+	if max < 0 {
+		max = 0
+	}
+	if s.offset < 0 {
+		s.offset, s.velocity = 0, 0
+	} else if s.offset > max {
+		s.offset, s.velocity = max, 0
+	}
+}
+
+// returns the visible items [first, last)
+func visibleRange(offset, height, itemHeight float32, count int) (first, last int) {
+	if itemHeight <= 0 || count == 0 {
+		return
+	}
+	first = int(offset / itemHeight)
+	last = int((offset + height) / itemHeight) + 1
+	if first < 0 {
+		first = 0
+	}
+	if last > count {
+		last = count
+	}
+	if first > last {
+		first = last
+	}
+	return
 }
 
 func (ctx *Context) CheckSlider(id ID, bound *Rect) (v float32, e EventType) {
@@ -488,9 +885,14 @@ func (ctx *Context) ColorBackground(event EventType, bb *Rect, normal, pressed g
 	}
 }
 
-// Clip:
+// Clip: minClip and maxClip are the top-left and bottom-right corner in
+// ui coordinate, relative to the cursor.
 func (ctx *Context) PushClipRect(minClip, maxClip f32.Vec2, intersectCurrent bool) {
-
+	x0, y0 := Gui2Game(minClip[0]+ctx.Cursor.X, maxClip[1]+ctx.Cursor.Y)
+	x1, y1 := Gui2Game(maxClip[0]+ctx.Cursor.X, minClip[1]+ctx.Cursor.Y)
+	min := f32.Vec2{x0 * screen.scaleX, y0 * screen.scaleY}
+	max := f32.Vec2{x1 * screen.scaleX, y1 * screen.scaleY}
+	ctx.DrawList.PushClipRect(min, max, intersectCurrent)
 }
 
 // Theme:
@@ -0,0 +1,81 @@
+package gui
+
+import (
+	"testing"
+
+	"korok.io/korok/math/f32"
+)
+
+func TestVisibleRange(t *testing.T) {
+	cases := []struct {
+		offset, height, itemHeight float32
+		count                      int
+		first, last                int
+	}{
+		{0, 100, 20, 100, 0, 6},
+		{30, 100, 20, 100, 1, 7},
+		{1900, 100, 20, 100, 95, 100},
+		{0, 100, 20, 3, 0, 3},
+		{0, 100, 20, 0, 0, 0},
+	}
+	for i, c := range cases {
+		first, last := visibleRange(c.offset, c.height, c.itemHeight, c.count)
+		if first != c.first || last != c.last {
+			t.Errorf("case %d: got [%d, %d), want [%d, %d)", i, first, last, c.first, c.last)
+		}
+	}
+}
+
+func TestScrollFling(t *testing.T) {
+	s := &scrollState{velocity: 600}
+	for i := 0; i < 240; i++ {
+		s.update(1.0/60, 1000, 3)
+	}
+	if s.velocity != 0 {
+		t.Errorf("fling should stop, velocity: %f", s.velocity)
+	}
+	if s.offset <= 100 || s.offset >= 1000 {
+		t.Errorf("unexpected offset: %f", s.offset)
+	}
+
+	// clamped at the edge
+	s = &scrollState{offset: 990, velocity: 600}
+	s.update(1.0/60, 1000, 3)
+	s.update(1.0/60, 1000, 3)
+	if s.offset != 1000 || s.velocity != 0 {
+		t.Errorf("should stop at the end, offset: %f velocity: %f", s.offset, s.velocity)
+	}
+
+	// content smaller than the view
+	s = &scrollState{offset: 50, velocity: -100}
+	s.update(1.0/60, -20, 3)
+	if s.offset != 0 {
+		t.Errorf("should stay at the top, offset: %f", s.offset)
+	}
+
+	// no fling while dragging
+	s = &scrollState{offset: 10, velocity: 600, dragging: true}
+	s.update(1.0/60, 1000, 3)
+	if s.offset != 10 {
+		t.Errorf("offset changed while dragging: %f", s.offset)
+	}
+}
+
+func TestStartScroll(t *testing.T) {
+	ctx := NewContext(ThemeLight)
+	ctx.Cursor = Cursor{10, 20}
+	ctx.StartScroll(f32.Vec2{100, 50}, f32.Vec2{0, 30})
+	if ctx.Cursor != (Cursor{10, -10}) {
+		t.Errorf("cursor not scrolled: %v", ctx.Cursor)
+	}
+	if n := len(ctx.ClipRectStack); n != 1 {
+		t.Fatalf("clip rect not pushed: %d", n)
+	}
+	ctx.EndScroll()
+	if ctx.Cursor != (Cursor{10, 20}) {
+		t.Errorf("cursor not restored: %v", ctx.Cursor)
+	}
+	if n := len(ctx.ClipRectStack); n != 0 {
+		t.Errorf("clip rect not popped: %d", n)
+	}
+}
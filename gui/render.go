@@ -91,10 +91,31 @@ func (f *UIRenderFeature) Draw(nodes gfx.RenderNodes) {
 		mesh.NumIndex = cmd.ElemCount
 		mesh.SetTexture(cmd.TextureId)
 
+		// zero clip rect means no clipping
+		if clip := cmd.ClipRect; clip != (f32.Vec4{}) {
+			bk.SetScissor(scissorRect(clip))
+		}
 		f.MeshRender.Draw(mesh, mat4, int32(cmd.zOrder))
 	}
 }
 
+// clip rect(x0, y0, x1, y1) -> scissor rect(x, y, w, h)
+func scissorRect(clip f32.Vec4) (x, y, w, h uint16) {
+	for i := range clip {
+		if clip[i] < 0 {
+			clip[i] = 0
+		}
+	}
+	x, y = uint16(clip[0]), uint16(clip[1])
+	if clip[2] > clip[0] {
+		w = uint16(clip[2] - clip[0])
+	}
+	if clip[3] > clip[1] {
+		h = uint16(clip[3] - clip[1])
+	}
+	return
+}
+
 func (f *UIRenderFeature) Flush() {
 	isz, vsz := f.DrawList.Size()
 	dbg.Hud("gui DrawList: %d, %d", isz, vsz)
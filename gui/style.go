@@ -20,6 +20,8 @@ type Theme struct {
 	Image       ImageStyle
 	ImageButton ImageButtonStyle
 	Slider      SliderStyle
+	ListView    ListViewStyle
+	Input       InputStyle
 
 	// global config..
 	Normal  gfx.Color
@@ -58,10 +60,21 @@ func (text *TextStyle) SetSize(size float32) *TextStyle {
 	return text
 }
 
+// InputStyle, Color and HintColor are 0xAABBGGRR values.
 type InputStyle struct {
 	Visibility
 	Color, HintColor uint32
 	Size             float32
+	Font             font.Font
+	Padding
+
+	Background, Selection, Caret gfx.Color
+	Rounding                     float32
+
+	// mask the text with '*'
+	Password bool
+	// max length in runes, 0 means no limit
+	MaxLength int
 }
 
 type ButtonStyle struct {
@@ -94,6 +107,15 @@ type SliderStyle struct {
 	Bar, Knob gfx.Color
 }
 
+type ListViewStyle struct {
+	Background gfx.Color
+	// scroll bar
+	Bar      gfx.Color
+	BarWidth float32
+	// ratio of the fling velocity lost per second
+	Friction float32
+}
+
 //// 这样
 func newLightTheme() *Theme {
 	return &Theme{
@@ -116,6 +138,21 @@ func newLightTheme() *Theme {
 		Slider: SliderStyle{
 			gfx.LTGray, gfx.Gray,
 		},
+		Input: InputStyle{
+			Color:      gfx.Black.U32(),
+			HintColor:  gfx.Gray.U32(),
+			Size:       12,
+			Padding:    Padding{4, 4, 4, 4},
+			Background: gfx.White,
+			Selection:  gfx.Color{R: 0x99, G: 0xCC, B: 0xFF, A: 0xFF},
+			Caret:      gfx.Black,
+			Rounding:   3,
+		},
+		ListView: ListViewStyle{
+			Bar:      gfx.Gray,
+			BarWidth: 4,
+			Friction: 3,
+		},
 		Normal:  gfx.LTGray,
 		Pressed: gfx.Gray,
 		Spacing: 4,
@@ -0,0 +1,196 @@
+package gui
+
+// Clipboard is used by InputText to copy and paste text.
+type Clipboard interface {
+	GetText() string
+	SetText(text string)
+}
+
+// memory clipboard, used if no platform clipboard is set
+type memClipboard struct {
+	text string
+}
+
+func (c *memClipboard) GetText() string {
+	return c.text
+}
+
+func (c *memClipboard) SetText(text string) {
+	c.text = text
+}
+
+var clipboard Clipboard = &memClipboard{}
+
+// SetClipboard sets the clipboard used by InputText.
+func SetClipboard(cb Clipboard) {
+	if cb == nil {
+		cb = &memClipboard{}
+	}
+	clipboard = cb
+}
+
+// 文本编辑状态, 使用 rune 为单位来处理 UTF-8 字符串
+// caret 为光标位置, [anchor, caret) 或 [caret, anchor) 为选中的文本
+type textEdit struct {
+	id     ID
+	text   []rune
+	caret  int
+	anchor int
+
+	// max length in runes, 0 means no limit
+	maxLength int
+	// copy and cut are disabled for password
+	password bool
+
+	// caret blink time, horizontal scroll offset
+	blink  float32
+	scroll float32
+}
+
+func (te *textEdit) reset(id ID, text string) {
+	te.id = id
+	te.text = append(te.text[:0], []rune(text)...)
+	te.caret = len(te.text)
+	te.anchor = te.caret
+	te.blink, te.scroll = 0, 0
+}
+
+func (te *textEdit) String() string {
+	return string(te.text)
+}
+
+// selection returns the selected range [start, end)
+func (te *textEdit) selection() (start, end int) {
+	if te.caret < te.anchor {
+		return te.caret, te.anchor
+	}
+	return te.anchor, te.caret
+}
+
+func (te *textEdit) hasSelection() bool {
+	return te.caret != te.anchor
+}
+
+func (te *textEdit) selectAll() {
+	te.anchor, te.caret = 0, len(te.text)
+}
+
+func (te *textEdit) selectedText() string {
+	start, end := te.selection()
+	return string(te.text[start:end])
+}
+
+// moves the caret, keeps the selection if shift is down
+func (te *textEdit) moveTo(i int, shift bool) {
+	if i < 0 {
+		i = 0
+	}
+	if n := len(te.text); i > n {
+		i = n
+	}
+	te.caret = i
+	if !shift {
+		te.anchor = i
+	}
+	te.blink = 0
+}
+
+func (te *textEdit) moveLeft(shift bool) {
+	if te.hasSelection() && !shift {
+		start, _ := te.selection()
+		te.moveTo(start, false)
+	} else {
+		te.moveTo(te.caret-1, shift)
+	}
+}
+
+func (te *textEdit) moveRight(shift bool) {
+	if te.hasSelection() && !shift {
+		_, end := te.selection()
+		te.moveTo(end, false)
+	} else {
+		te.moveTo(te.caret+1, shift)
+	}
+}
+
+func (te *textEdit) deleteSelection() bool {
+	if !te.hasSelection() {
+		return false
+	}
+	start, end := te.selection()
+	te.text = append(te.text[:start], te.text[end:]...)
+	te.moveTo(start, false)
+	return true
+}
+
+// insert replaces the selection with the runes, returns false if nothing
+// is inserted or deleted.
+func (te *textEdit) insert(runes []rune) bool {
+	deleted := te.deleteSelection()
+	if max := te.maxLength; max > 0 {
+		if left := max - len(te.text); left <= 0 {
+			return deleted
+		} else if len(runes) > left {
+			runes = runes[:left]
+		}
+	}
+	if len(runes) == 0 {
+		return deleted
+	}
+	i := te.caret
+	te.text = append(te.text, runes...)
+	copy(te.text[i+len(runes):], te.text[i:])
+	copy(te.text[i:], runes)
+	te.moveTo(i+len(runes), false)
+	return true
+}
+
+func (te *textEdit) backspace() bool {
+	if te.deleteSelection() {
+		return true
+	}
+	if i := te.caret; i > 0 {
+		te.text = append(te.text[:i-1], te.text[i:]...)
+		te.moveTo(i-1, false)
+		return true
+	}
+	return false
+}
+
+func (te *textEdit) delete() bool {
+	if te.deleteSelection() {
+		return true
+	}
+	if i := te.caret; i < len(te.text) {
+		te.text = append(te.text[:i], te.text[i+1:]...)
+		te.blink = 0
+		return true
+	}
+	return false
+}
+
+func (te *textEdit) copy() {
+	if te.hasSelection() && !te.password {
+		clipboard.SetText(te.selectedText())
+	}
+}
+
+func (te *textEdit) cut() bool {
+	if te.password {
+		return false
+	}
+	te.copy()
+	return te.deleteSelection()
+}
+
+// paste inserts the text in clipboard, control characters are dropped
+// since the text field has only one line.
+func (te *textEdit) paste() bool {
+	var runes []rune
+	for _, r := range clipboard.GetText() {
+		if r >= 0x20 && r != 0x7F {
+			runes = append(runes, r)
+		}
+	}
+	return te.insert(runes)
+}
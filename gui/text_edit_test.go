@@ -0,0 +1,120 @@
+package gui
+
+import "testing"
+
+func TestTextEdit(t *testing.T) {
+	te := &textEdit{}
+	te.reset(1, "héllo")
+	if te.caret != 5 {
+		t.Fatalf("caret should be at the end: %d", te.caret)
+	}
+
+	te.moveLeft(false)
+	te.moveLeft(true)
+	te.moveLeft(true)
+	if s := te.selectedText(); s != "ll" {
+		t.Errorf("selected text: %q", s)
+	}
+	te.insert([]rune("世界"))
+	if s := te.String(); s != "hé世界o" {
+		t.Errorf("insert: %q", s)
+	}
+	if te.caret != 4 || te.hasSelection() {
+		t.Errorf("caret after insert: %d, %d", te.caret, te.anchor)
+	}
+
+	te.backspace()
+	te.moveTo(0, false)
+	te.delete()
+	if s := te.String(); s != "é世o" {
+		t.Errorf("delete: %q", s)
+	}
+
+	// nothing to delete
+	te.moveTo(0, false)
+	if te.backspace() {
+		t.Error("backspace at the start")
+	}
+	te.moveTo(len(te.text), false)
+	if te.delete() {
+		t.Error("delete at the end")
+	}
+}
+
+func TestTextEditMaxLength(t *testing.T) {
+	te := &textEdit{maxLength: 4}
+	te.reset(1, "ab")
+	if !te.insert([]rune("cdef")) {
+		t.Error("should insert")
+	}
+	if s := te.String(); s != "abcd" {
+		t.Errorf("max length: %q", s)
+	}
+	if te.insert([]rune("g")) {
+		t.Error("should not insert when full")
+	}
+
+	// replacing the selection is allowed
+	te.moveTo(1, false)
+	te.moveTo(3, true)
+	te.insert([]rune("xyz"))
+	if s := te.String(); s != "axyd" {
+		t.Errorf("replace selection: %q", s)
+	}
+}
+
+func TestTextEditClipboard(t *testing.T) {
+	defer SetClipboard(nil)
+	cb := &memClipboard{}
+	SetClipboard(cb)
+
+	te := &textEdit{}
+	te.reset(1, "copy me")
+	te.selectAll()
+	te.copy()
+	if cb.text != "copy me" {
+		t.Errorf("copy: %q", cb.text)
+	}
+
+	te.moveTo(4, false)
+	te.moveTo(0, true)
+	te.cut()
+	if s := te.String(); s != " me" || cb.text != "copy" {
+		t.Errorf("cut: %q, clipboard: %q", s, cb.text)
+	}
+
+	// password can't be copied
+	te.password = true
+	te.selectAll()
+	te.copy()
+	if te.cut() || cb.text != "copy" || te.String() != " me" {
+		t.Errorf("password copied: %q, clipboard: %q", te.String(), cb.text)
+	}
+	te.password = false
+
+	cb.text = "pa\nste"
+	te.moveTo(len(te.text), false)
+	te.paste()
+	if s := te.String(); s != " mepaste" {
+		t.Errorf("paste: %q", s)
+	}
+}
+
+func TestMaskText(t *testing.T) {
+	if s := string(maskText([]rune("密码"), true)); s != "**" {
+		t.Errorf("mask: %q", s)
+	}
+	if s := string(maskText([]rune("abc"), false)); s != "abc" {
+		t.Errorf("no mask: %q", s)
+	}
+}
+
+func TestNearestOffset(t *testing.T) {
+	offsets := []float32{0, 10, 20, 30}
+	cases := map[float32]int{-5: 0, 4: 0, 6: 1, 14: 1, 26: 3, 100: 3}
+	for x, i := range cases {
+		if got := nearestOffset(offsets, x); got != i {
+			t.Errorf("x=%f: got %d, want %d", x, got, i)
+		}
+	}
+}
@@ -13,6 +13,9 @@ type SparseMap struct {
 }
 
 func (m *SparseMap) Put(k Key, st bool) {
+	if m.used == len(m.keys) {
+		return
+	}
 	m.keys[m.used] = k
 	m.stat[m.used] = st
 	m.used ++
@@ -51,12 +54,18 @@ type InputSystem struct {
 	active int
 	pointerButton [10]button
 	pointers [10]PointerInput
+
+	// 文本输入, 记录一帧之内按下的键(包括重复)和输入的字符
+	keyDown map[Key]bool
+	pressed []Key
+	chars []rune
 }
 
 func NewInputSystem() *InputSystem {
 	in := &InputSystem{
 		buttons:make(map[string]*button),
 		axes:make(map[string]*VAxis),
+		keyDown:make(map[Key]bool),
 	}
 	Input = in
 	return in
@@ -113,6 +122,8 @@ func (in *InputSystem) Reset() {
 	// clear dirty map!!
 	in.mutex.Lock()
 	in.dirty.Clear()
+	in.pressed = in.pressed[:0]
+	in.chars = in.chars[:0]
 	in.mutex.Unlock()
 	// reset button state
 	for _, v := range in.buttons {
@@ -127,9 +138,42 @@ func (in *InputSystem) Reset() {
 func (in *InputSystem) SetKeyEvent(key int, pressed bool) {
 	in.mutex.Lock()
 	in.dirty.Put(Key(key), pressed)
+	in.keyDown[Key(key)] = pressed
+	if pressed {
+		in.pressed = append(in.pressed, Key(key))
+	}
+	in.mutex.Unlock()
+}
+
+// 更新字符输入, 控制字符会被忽略
+func (in *InputSystem) SetCharEvent(char rune) {
+	if char < 0x20 || char == 0x7F {
+		return
+	}
+	in.mutex.Lock()
+	in.chars = append(in.chars, char)
 	in.mutex.Unlock()
 }
 
+// KeyDown returns true if the key is held down.
+func (in *InputSystem) KeyDown(k Key) bool {
+	in.mutex.RLock()
+	down := in.keyDown[k]
+	in.mutex.RUnlock()
+	return down
+}
+
+// PressedKeys returns the keys pressed in this frame in order, a key
+// is reported again when it's repeated.
+func (in *InputSystem) PressedKeys() []Key {
+	return in.pressed
+}
+
+// Chars returns the characters typed in this frame.
+func (in *InputSystem) Chars() []rune {
+	return in.chars
+}
+
 // 更新 Mouse/Touch 状态
 func (in *InputSystem) SetPointerEvent(key int, pressed bool, x, y float32) {
 	if key != -1000 {
@@ -167,6 +211,18 @@ func RegisterButton(name string, keys...Key) {
 	Input.RegisterButton(name, keys...)
 }
 
+func KeyDown(k Key) bool {
+	return Input.KeyDown(k)
+}
+
+func PressedKeys() []Key {
+	return Input.PressedKeys()
+}
+
+func Chars() []rune {
+	return Input.Chars()
+}
+
 func PointerButton(pb KeyPoint) button {
 	return Input.pointerButton[pb]
 }
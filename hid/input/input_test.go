@@ -0,0 +1,42 @@
+package input
+
+import "testing"
+
+func TestTextInput(t *testing.T) {
+	in := NewInputSystem()
+	in.SetKeyEvent(int(LeftShift), true)
+	in.SetKeyEvent(int(A), true)
+	in.SetCharEvent('A')
+	in.SetCharEvent('\b')        // control character is ignored
+	in.SetKeyEvent(int(A), true) // repeat
+	in.SetCharEvent('世')
+
+	if keys := in.PressedKeys(); len(keys) != 3 || keys[0] != LeftShift || keys[1] != A || keys[2] != A {
+		t.Errorf("pressed keys: %v", keys)
+	}
+	if chars := string(in.Chars()); chars != "A世" {
+		t.Errorf("chars: %q", chars)
+	}
+	if !in.KeyDown(LeftShift) {
+		t.Error("shift should be down")
+	}
+
+	in.Reset()
+	if len(in.PressedKeys()) != 0 || len(in.Chars()) != 0 {
+		t.Error("text input not cleared")
+	}
+	in.SetKeyEvent(int(LeftShift), false)
+	if in.KeyDown(LeftShift) {
+		t.Error("shift should be up")
+	}
+}
+
+func TestSparseMapOverflow(t *testing.T) {
+	m := SparseMap{}
+	for i := 0; i < 10; i++ {
+		m.Put(Key(i), true)
+	}
+	if m.used != len(m.keys) {
+		t.Errorf("used: %d", m.used)
+	}
+}
@@ -5,7 +5,29 @@ package input
 const (
 	Back = Key(4)
 	Menu = Key(82)
+)
 
-	// Home key. This key is handled by the framework and is never delivered to applications.
-	Home = Key(3)
+// keyboard keys, x/mobile reports HID usage codes
+const (
+	Backspace    = Key(42)
+	Tab          = Key(43)
+	Enter        = Key(40)
+	Escape       = Key(41)
+	Delete       = Key(76)
+	Home         = Key(74)
+	End          = Key(77)
+	ArrowRight   = Key(79)
+	ArrowLeft    = Key(80)
+	ArrowDown    = Key(81)
+	ArrowUp      = Key(82)
+	LeftControl  = Key(224)
+	LeftShift    = Key(225)
+	LeftSuper    = Key(227)
+	RightControl = Key(228)
+	RightShift   = Key(229)
+	RightSuper   = Key(231)
+	A            = Key(4)
+	C            = Key(6)
+	V            = Key(25)
+	X            = Key(27)
 )
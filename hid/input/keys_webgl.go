@@ -7,6 +7,30 @@ const (
 	Back = Key(4)
 	Menu = Key(82)
 
-	// Home key. This key is handled by the framework and is never delivered to applications.
-	Home = Key(3)
+	// Home key, same as the keyboard home key in browser.
+	Home = Key(36)
+)
+
+// keyboard keys, the keyCode of KeyboardEvent
+const (
+	Backspace    = Key(8)
+	Tab          = Key(9)
+	Enter        = Key(13)
+	Escape       = Key(27)
+	Delete       = Key(46)
+	End          = Key(35)
+	ArrowLeft    = Key(37)
+	ArrowUp      = Key(38)
+	ArrowRight   = Key(39)
+	ArrowDown    = Key(40)
+	LeftShift    = Key(16)
+	RightShift   = Key(16)
+	LeftControl  = Key(17)
+	RightControl = Key(17)
+	LeftSuper    = Key(91)
+	RightSuper   = Key(93)
+	A            = Key(65)
+	C            = Key(67)
+	V            = Key(86)
+	X            = Key(88)
 )
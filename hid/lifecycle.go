@@ -28,4 +28,7 @@ type WindowCallback interface {
 type InputCallback interface {
 	OnKeyEvent(key int, pressed bool)
 	OnPointEvent(key int, pressed bool, x, y float32)
+
+	// 字符输入
+	OnCharEvent(char rune)
 }
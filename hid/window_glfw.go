@@ -117,7 +117,7 @@ func CreateWindow(option *WindowOptions) {
 		//	}
 		//}
 		if inputCallback != nil {
-			if action == glfw.Press {
+			if action == glfw.Press || action == glfw.Repeat {
 				inputCallback.OnKeyEvent(int(key), true)
 			} else if action == glfw.Release {
 				inputCallback.OnKeyEvent(int(key), false)
@@ -125,6 +125,12 @@ func CreateWindow(option *WindowOptions) {
 		}
 	})
 
+	window.SetCharCallback(func(w *glfw.Window, char rune) {
+		if inputCallback != nil {
+			inputCallback.OnCharEvent(char)
+		}
+	})
+
 	window.SetMouseButtonCallback(func(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mod glfw.ModifierKey) {
 		if inputCallback != nil {
 			x, y := w.GetCursorPos()
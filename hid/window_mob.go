@@ -144,5 +144,14 @@ func onTouch(e touch.Event) {
 }
 
 func onKey(e key.Event) {
-	inputCallback.OnKeyEvent(int(e.Code), e.Direction == key.DirPress)
+	// DirNone is a typed character, not a key state change
+	switch e.Direction {
+	case key.DirPress:
+		inputCallback.OnKeyEvent(int(e.Code), true)
+	case key.DirRelease:
+		inputCallback.OnKeyEvent(int(e.Code), false)
+	}
+	if e.Direction != key.DirRelease && e.Rune > 0 {
+		inputCallback.OnCharEvent(e.Rune)
+	}
 }
@@ -14,6 +14,7 @@ import (
 
 	"strconv"
 	"time"
+	"unicode/utf8"
 )
 
 var windowCallback WindowCallback
@@ -131,9 +132,14 @@ func CreateWindow(option *WindowOptions) {
 			AudioCtx.Call("resume")
 		}
 		consume(arg[0])
-		// TODO 这里需要处理特殊按键
-		button := arg[0].Get("key").String()
-		inputCallback.OnKeyEvent(int(button[0]), true)
+		e := arg[0]
+		inputCallback.OnKeyEvent(e.Get("keyCode").Int(), true)
+
+		// printable key, "key" is the character
+		if key := e.Get("key").String(); utf8.RuneCountInString(key) == 1 && !e.Get("ctrlKey").Bool() && !e.Get("metaKey").Bool() {
+			r, _ := utf8.DecodeRuneInString(key)
+			inputCallback.OnCharEvent(r)
+		}
 
 		return nil
 	})
@@ -143,9 +149,7 @@ func CreateWindow(option *WindowOptions) {
 			AudioCtx.Call("resume")
 		}
 		consume(arg[0])
-		// TODO 这里需要处理特殊按键
-		button := arg[0].Get("key").String()
-		inputCallback.OnKeyEvent(int(button[0]), false)
+		inputCallback.OnKeyEvent(arg[0].Get("keyCode").Int(), false)
 
 		return nil
 	})
@@ -11,6 +11,9 @@ import (
 	"korok.io/korok/effect"
 	"korok.io/korok/hid/input"
 	"korok.io/korok/anim/frame"
+	"korok.io/korok/anim/spine"
+	"korok.io/korok/physics"
+	"korok.io/korok/audio"
 )
 
 const VERSION_CODE  = 2
@@ -30,26 +33,21 @@ func Run(options *Options, sc game.Scene)  {
 	SceneMan.SetDefault(sc)
 
 	// init table shortcut
-	for _, table := range g.DB.Tables {
-		switch t := table.(type) {
-		case *gfx.SpriteTable:
-			Sprite = t
-		case *gfx.MeshTable:
-			Mesh = t
-		case *gfx.TransformTable:
-			Transform = t
-		case *gfx.TextTable:
-			Text = t
-		case *effect.ParticleSystemTable:
-			ParticleSystem = t
-		case *game.TagTable:
-			Tag = t
-		case *game.ScriptTable:
-			Script = t
-		case *frame.FlipbookTable:
-			Flipbook = t
-		}
-	}
+	db := &g.DB
+	db.LookupTable(&Sprite)
+	db.LookupTable(&Mesh)
+	db.LookupTable(&Transform)
+	db.LookupTable(&Text)
+	db.LookupTable(&ParticleSystem)
+	db.LookupTable(&Tag)
+	db.LookupTable(&Script)
+	db.LookupTable(&Flipbook)
+	db.LookupTable(&Animator)
+	db.LookupTable(&Skeleton)
+	db.LookupTable(&RigidBody)
+	db.LookupTable(&Collider)
+	db.LookupTable(&Trigger)
+	db.LookupTable(&Source)
 
 	log.Printf("Load table: %v", len(g.DB.Tables))
 	for i, v := range g.DB.Tables {
@@ -78,9 +76,19 @@ var Text       *gfx.TextTable
 
 // animation system
 var Flipbook *frame.FlipbookTable
+var Animator *frame.AnimatorTable
+var Skeleton *spine.SkeletonTable
 
 // particle system
 var ParticleSystem *effect.ParticleSystemTable
 
+// physics system
+var RigidBody *physics.RigidBodyTable
+var Collider  *physics.ColliderTable
+var Trigger   *game.TriggerTable
+
+// positional audio
+var Source *audio.SourceTable
+
 // input system
 var Input *input.InputSystem
@@ -73,6 +73,10 @@ func Atan2(y, x float32) float32 {
 	return float32(math.Atan2(float64(y), float64(x)))
 }
 
+func Exp(v float32) float32 {
+	return float32(math.Exp(float64(v)))
+}
+
 func Floor(v float32) float32 {
 	return float32(math.Floor(float64(v)))
 }
@@ -0,0 +1,224 @@
+package physics
+
+import (
+	"korok.io/korok/engi"
+	"korok.io/korok/math/f32"
+)
+
+// BodyType 决定刚体如何参与模拟:
+// Static 不会移动; Kinematic 按速度移动, 但不受力和碰撞影响;
+// Dynamic 受重力, 力和碰撞影响.
+type BodyType uint8
+
+const (
+	Dynamic BodyType = iota
+	Kinematic
+	Static
+)
+
+// RigidBody Component, the position and rotation are in world space,
+// they're initialized from the Transform of the entity(if any) in the
+// first step, and written back to the Transform each frame.
+type RigidBody struct {
+	engi.Entity
+	typ BodyType
+
+	position f32.Vec2
+	rotation float32
+
+	velocity        f32.Vec2
+	angularVelocity float32
+
+	force  f32.Vec2
+	torque float32
+
+	mass, invMass       float32
+	inertia, invInertia float32
+
+	GravityScale   float32
+	LinearDamping  float32
+	AngularDamping float32
+	FixedRotation  bool
+
+	// position is synced from Transform
+	init bool
+	// mass need to be computed again
+	dirty bool
+}
+
+func (rb *RigidBody) Type() BodyType {
+	return rb.typ
+}
+
+func (rb *RigidBody) SetType(typ BodyType) {
+	rb.typ = typ
+	rb.dirty = true
+}
+
+func (rb *RigidBody) Position() f32.Vec2 {
+	return rb.position
+}
+
+// SetPosition teleports the body, the Transform is updated in next step.
+func (rb *RigidBody) SetPosition(p f32.Vec2) {
+	rb.position = p
+	rb.init = true
+}
+
+func (rb *RigidBody) Rotation() float32 {
+	return rb.rotation
+}
+
+func (rb *RigidBody) SetRotation(r float32) {
+	rb.rotation = r
+	rb.init = true
+}
+
+func (rb *RigidBody) Velocity() f32.Vec2 {
+	return rb.velocity
+}
+
+func (rb *RigidBody) SetVelocity(v f32.Vec2) {
+	rb.velocity = v
+}
+
+func (rb *RigidBody) AngularVelocity() float32 {
+	return rb.angularVelocity
+}
+
+func (rb *RigidBody) SetAngularVelocity(w float32) {
+	rb.angularVelocity = w
+}
+
+// ApplyForce applies a force at the center of mass, forces are cleared
+// after each step.
+func (rb *RigidBody) ApplyForce(f f32.Vec2) {
+	rb.force = rb.force.Add(f)
+}
+
+// ApplyForceAt applies a force at a world point.
+func (rb *RigidBody) ApplyForceAt(f, point f32.Vec2) {
+	rb.force = rb.force.Add(f)
+	rb.torque += point.Sub(rb.position).Cross(f)
+}
+
+func (rb *RigidBody) ApplyTorque(t float32) {
+	rb.torque += t
+}
+
+// ApplyImpulse changes the velocity immediately.
+func (rb *RigidBody) ApplyImpulse(impulse f32.Vec2) {
+	rb.velocity = rb.velocity.Add(impulse.Mul(rb.invMass))
+}
+
+// ApplyImpulseAt applies an impulse at a world point.
+func (rb *RigidBody) ApplyImpulseAt(impulse, point f32.Vec2) {
+	rb.velocity = rb.velocity.Add(impulse.Mul(rb.invMass))
+	rb.angularVelocity += rb.invInertia * point.Sub(rb.position).Cross(impulse)
+}
+
+// Mass is computed from the Collider's density and shape, a body without
+// Collider has mass 1. Static and kinematic bodies have infinite mass.
+func (rb *RigidBody) Mass() float32 {
+	return rb.mass
+}
+
+func (rb *RigidBody) setMass(mass, inertia float32) {
+	rb.mass, rb.inertia = mass, inertia
+	rb.invMass, rb.invInertia = 0, 0
+	if rb.typ != Dynamic {
+		rb.mass, rb.inertia = 0, 0
+		return
+	}
+	if mass > 0 {
+		rb.invMass = 1 / mass
+	}
+	if inertia > 0 && !rb.FixedRotation {
+		rb.invInertia = 1 / inertia
+	}
+}
+
+// RigidBodyTable
+type RigidBodyTable struct {
+	comps      []RigidBody
+	_map       map[uint32]int
+	index, cap int
+}
+
+func NewRigidBodyTable(cap int) *RigidBodyTable {
+	return &RigidBodyTable{
+		cap:  cap,
+		_map: make(map[uint32]int),
+	}
+}
+
+func (bt *RigidBodyTable) NewComp(entity engi.Entity) (rb *RigidBody) {
+	if size := len(bt.comps); bt.index >= size {
+		bt.comps = bodyResize(bt.comps, size+64)
+	}
+	ei := entity.Index()
+	if v, ok := bt._map[ei]; ok {
+		rb = &bt.comps[v]
+		return
+	}
+	rb = &bt.comps[bt.index]
+	rb.Entity = entity
+	rb.GravityScale = 1
+	rb.dirty = true
+	bt._map[ei] = bt.index
+	bt.index++
+	return
+}
+
+func (bt *RigidBodyTable) Alive(entity engi.Entity) bool {
+	if v, ok := bt._map[entity.Index()]; ok {
+		return bt.comps[v].Entity == entity
+	}
+	return false
+}
+
+func (bt *RigidBodyTable) Comp(entity engi.Entity) (rb *RigidBody) {
+	if v, ok := bt._map[entity.Index()]; ok {
+		rb = &bt.comps[v]
+	}
+	return
+}
+
+func (bt *RigidBodyTable) Delete(entity engi.Entity) {
+	ei := entity.Index()
+	if v, ok := bt._map[ei]; ok {
+		if tail := bt.index - 1; v != tail && tail > 0 {
+			bt.comps[v] = bt.comps[tail]
+			// remap index
+			tComp := bt.comps[tail]
+			ei := tComp.Entity.Index()
+			bt._map[ei] = v
+			bt.comps[tail] = RigidBody{}
+		} else {
+			bt.comps[tail] = RigidBody{}
+		}
+		bt.index -= 1
+		delete(bt._map, ei)
+	}
+}
+
+func (bt *RigidBodyTable) Size() (size, cap int) {
+	return bt.index, bt.cap
+}
+
+// EntityAt returns the entity of the i-th comp, i in [0, size)
+func (bt *RigidBodyTable) EntityAt(i int) engi.Entity {
+	return bt.comps[i].Entity
+}
+
+func (bt *RigidBodyTable) Destroy() {
+	bt.comps = make([]RigidBody, 0)
+	bt._map = make(map[uint32]int)
+	bt.index = 0
+}
+
+func bodyResize(slice []RigidBody, size int) []RigidBody {
+	newSlice := make([]RigidBody, size)
+	copy(newSlice, slice)
+	return newSlice
+}
@@ -0,0 +1,275 @@
+package physics
+
+import (
+	"korok.io/korok/math"
+	"korok.io/korok/math/f32"
+)
+
+// Manifold describes the contact points of two colliders, Normal points
+// from A to B, Depth is the penetration of each point.
+type Manifold struct {
+	Normal f32.Vec2
+	Points [2]f32.Vec2
+	Depth  [2]float32
+	Count  int
+}
+
+// Bounds is an axis aligned bounding box in world space.
+type Bounds struct {
+	Min, Max f32.Vec2
+}
+
+func (b *Bounds) Overlap(o *Bounds) bool {
+	return b.Min[0] <= o.Max[0] && o.Min[0] <= b.Max[0] &&
+		b.Min[1] <= o.Max[1] && o.Min[1] <= b.Max[1]
+}
+
+func (b *Bounds) Contains(p f32.Vec2) bool {
+	return p[0] >= b.Min[0] && p[0] <= b.Max[0] && p[1] >= b.Min[1] && p[1] <= b.Max[1]
+}
+
+// world space shape, AABB is converted to a polygon
+type proxy struct {
+	c    *Collider
+	body *RigidBody
+
+	circle bool
+	center f32.Vec2
+	radius float32
+
+	n       int
+	verts   [MaxPolygonVertices]f32.Vec2
+	normals [MaxPolygonVertices]f32.Vec2
+
+	bounds Bounds
+}
+
+// compute the world geometry of the collider at position and rotation
+func (p *proxy) update(position f32.Vec2, rotation float32) {
+	s := &p.c.shape
+	cos, sin := math.Cos(rotation), math.Sin(rotation)
+	rotate := func(v f32.Vec2) f32.Vec2 {
+		return f32.Vec2{cos*v[0] - sin*v[1], sin*v[0] + cos*v[1]}
+	}
+	switch s.Type {
+	case ShapeCircle:
+		p.circle = true
+		p.center = position.Add(rotate(p.c.offset))
+		p.radius = s.Radius
+		r := f32.Vec2{s.Radius, s.Radius}
+		p.bounds = Bounds{p.center.Sub(r), p.center.Add(r)}
+		return
+	case ShapeAABB:
+		c := position.Add(p.c.offset)
+		hx, hy := s.HalfSize[0], s.HalfSize[1]
+		p.n = 4
+		p.verts[0] = f32.Vec2{c[0] - hx, c[1] - hy}
+		p.verts[1] = f32.Vec2{c[0] + hx, c[1] - hy}
+		p.verts[2] = f32.Vec2{c[0] + hx, c[1] + hy}
+		p.verts[3] = f32.Vec2{c[0] - hx, c[1] + hy}
+	case ShapePolygon:
+		p.n = len(s.Vertices)
+		for i, v := range s.Vertices {
+			p.verts[i] = position.Add(rotate(v.Add(p.c.offset)))
+		}
+	}
+	p.circle = false
+	if p.n == 0 {
+		p.bounds = Bounds{position, position}
+		return
+	}
+	min, max := p.verts[0], p.verts[0]
+	for i := 0; i < p.n; i++ {
+		v := p.verts[i]
+		min[0], min[1] = math.Min(min[0], v[0]), math.Min(min[1], v[1])
+		max[0], max[1] = math.Max(max[0], v[0]), math.Max(max[1], v[1])
+		e := p.verts[(i+1)%p.n].Sub(v)
+		if l := e.Len(); l > 0 {
+			p.normals[i] = f32.Vec2{e[1] / l, -e[0] / l}
+		}
+	}
+	p.bounds = Bounds{min, max}
+}
+
+// collide two proxies, returns false if not touching
+func collide(a, b *proxy, m *Manifold) bool {
+	switch {
+	case a.circle && b.circle:
+		return collideCircles(a, b, m)
+	case !a.circle && b.circle:
+		return collidePolygonCircle(a, b, m)
+	case a.circle && !b.circle:
+		if collidePolygonCircle(b, a, m) {
+			m.Normal = m.Normal.Mul(-1)
+			return true
+		}
+		return false
+	default:
+		return collidePolygons(a, b, m)
+	}
+}
+
+func collideCircles(a, b *proxy, m *Manifold) bool {
+	d := b.center.Sub(a.center)
+	r := a.radius + b.radius
+	if d.Dot(d) > r*r {
+		return false
+	}
+	dist := d.Len()
+	if dist > 0 {
+		m.Normal = d.Mul(1 / dist)
+	} else {
+		m.Normal = f32.Vec2{0, 1}
+	}
+	m.Count = 1
+	m.Points[0] = a.center.Add(m.Normal.Mul(a.radius))
+	m.Depth[0] = r - dist
+	return true
+}
+
+// a is polygon, b is circle
+func collidePolygonCircle(a, b *proxy, m *Manifold) bool {
+	if a.n == 0 {
+		return false
+	}
+	c, r := b.center, b.radius
+
+	// face of max separation
+	face, separation := 0, -math.MaxFloat32
+	for i := 0; i < a.n; i++ {
+		if s := a.normals[i].Dot(c.Sub(a.verts[i])); s > separation {
+			face, separation = i, s
+		}
+	}
+	if separation > r {
+		return false
+	}
+	v1, v2 := a.verts[face], a.verts[(face+1)%a.n]
+	m.Count = 1
+
+	// center is inside the polygon
+	if separation < 1e-6 {
+		m.Normal = a.normals[face]
+		m.Depth[0] = r - separation
+		m.Points[0] = c.Sub(m.Normal.Mul(r))
+		return true
+	}
+
+	u1 := c.Sub(v1).Dot(v2.Sub(v1))
+	u2 := c.Sub(v2).Dot(v1.Sub(v2))
+	var vertex f32.Vec2
+	switch {
+	case u1 <= 0:
+		vertex = v1
+	case u2 <= 0:
+		vertex = v2
+	default:
+		m.Normal = a.normals[face]
+		m.Depth[0] = r - separation
+		m.Points[0] = c.Sub(m.Normal.Mul(r))
+		return true
+	}
+	d := c.Sub(vertex)
+	if d.Dot(d) > r*r {
+		return false
+	}
+	dist := d.Len()
+	m.Normal = d.Mul(1 / dist)
+	m.Depth[0] = r - dist
+	m.Points[0] = vertex
+	return true
+}
+
+// the max separation of b's vertices along a's face normals
+func maxSeparation(a, b *proxy) (face int, separation float32) {
+	separation = -math.MaxFloat32
+	for i := 0; i < a.n; i++ {
+		n, v := a.normals[i], a.verts[i]
+		min := math.MaxFloat32
+		for j := 0; j < b.n; j++ {
+			if s := n.Dot(b.verts[j].Sub(v)); s < min {
+				min = s
+			}
+		}
+		if min > separation {
+			face, separation = i, min
+		}
+	}
+	return
+}
+
+// SAT and clip the incident edge with the reference face
+func collidePolygons(a, b *proxy, m *Manifold) bool {
+	if a.n == 0 || b.n == 0 {
+		return false
+	}
+	faceA, sepA := maxSeparation(a, b)
+	if sepA > 0 {
+		return false
+	}
+	faceB, sepB := maxSeparation(b, a)
+	if sepB > 0 {
+		return false
+	}
+
+	ref, inc, face, flip := a, b, faceA, false
+	if sepB > sepA+0.1*linearSlop {
+		ref, inc, face, flip = b, a, faceB, true
+	}
+	normal := ref.normals[face]
+
+	// incident edge is the most anti-parallel one
+	incFace, min := 0, math.MaxFloat32
+	for i := 0; i < inc.n; i++ {
+		if d := normal.Dot(inc.normals[i]); d < min {
+			incFace, min = i, d
+		}
+	}
+	clip := [2]f32.Vec2{inc.verts[incFace], inc.verts[(incFace+1)%inc.n]}
+
+	// clip with the side planes of reference face
+	v1, v2 := ref.verts[face], ref.verts[(face+1)%ref.n]
+	tangent := v2.Sub(v1).Norm()
+	var ok bool
+	if clip, ok = clipSegment(clip, tangent.Mul(-1), -tangent.Dot(v1)); !ok {
+		return false
+	}
+	if clip, ok = clipSegment(clip, tangent, tangent.Dot(v2)); !ok {
+		return false
+	}
+
+	m.Count = 0
+	for _, p := range clip {
+		if s := normal.Dot(p.Sub(v1)); s <= 0 {
+			m.Points[m.Count] = p
+			m.Depth[m.Count] = -s
+			m.Count++
+		}
+	}
+	if flip {
+		m.Normal = normal.Mul(-1)
+	} else {
+		m.Normal = normal
+	}
+	return m.Count > 0
+}
+
+// keep the part of segment which n·p <= offset
+func clipSegment(in [2]f32.Vec2, n f32.Vec2, offset float32) (out [2]f32.Vec2, ok bool) {
+	d0, d1 := n.Dot(in[0])-offset, n.Dot(in[1])-offset
+	count := 0
+	if d0 <= 0 {
+		out[count] = in[0]
+		count++
+	}
+	if d1 <= 0 {
+		out[count] = in[1]
+		count++
+	}
+	if d0*d1 < 0 && count < 2 {
+		t := d0 / (d0 - d1)
+		out[count] = in[0].Add(in[1].Sub(in[0]).Mul(t))
+		count++
+	}
+	return out, count == 2
+}
@@ -0,0 +1,256 @@
+package physics
+
+import (
+	"korok.io/korok/engi"
+	"korok.io/korok/math"
+	"korok.io/korok/math/f32"
+)
+
+type ShapeType uint8
+
+const (
+	ShapeAABB ShapeType = iota
+	ShapeCircle
+	ShapePolygon
+)
+
+// MaxPolygonVertices is the max vertex number of a convex polygon.
+const MaxPolygonVertices = 8
+
+// Shape is the local geometry of a Collider. AABB is always axis aligned,
+// it doesn't rotate with the body. Polygon must be convex and in
+// counter-clockwise order.
+type Shape struct {
+	Type ShapeType
+
+	// half width and height of AABB
+	HalfSize f32.Vec2
+	// radius of circle
+	Radius float32
+	// vertices of convex polygon
+	Vertices []f32.Vec2
+}
+
+func NewAABB(w, h float32) Shape {
+	return Shape{Type: ShapeAABB, HalfSize: f32.Vec2{w / 2, h / 2}}
+}
+
+func NewCircle(radius float32) Shape {
+	return Shape{Type: ShapeCircle, Radius: radius}
+}
+
+// NewBox returns a box polygon which rotates with the body.
+func NewBox(w, h float32) Shape {
+	hw, hh := w/2, h/2
+	return NewPolygon([]f32.Vec2{{-hw, -hh}, {hw, -hh}, {hw, hh}, {-hw, hh}})
+}
+
+// NewPolygon returns a convex polygon, the vertices are copied.
+func NewPolygon(vertices []f32.Vec2) Shape {
+	if len(vertices) > MaxPolygonVertices {
+		vertices = vertices[:MaxPolygonVertices]
+	}
+	v := make([]f32.Vec2, len(vertices))
+	copy(v, vertices)
+	return Shape{Type: ShapePolygon, Vertices: v}
+}
+
+// mass and inertia of the shape with density, inertia is about the
+// origin of the shape.
+func (s *Shape) massData(density float32) (mass, inertia float32) {
+	switch s.Type {
+	case ShapeCircle:
+		mass = density * math.Pi * s.Radius * s.Radius
+		inertia = mass * s.Radius * s.Radius / 2
+	case ShapeAABB:
+		w, h := s.HalfSize[0]*2, s.HalfSize[1]*2
+		mass = density * w * h
+		inertia = mass * (w*w + h*h) / 12
+	case ShapePolygon:
+		// triangle fan from origin
+		var area, I float32
+		n := len(s.Vertices)
+		for i := 0; i < n; i++ {
+			p1, p2 := s.Vertices[i], s.Vertices[(i+1)%n]
+			d := p1.Cross(p2)
+			area += d / 2
+			I += d / 12 * (p1.Dot(p1) + p1.Dot(p2) + p2.Dot(p2))
+		}
+		mass = density * area
+		inertia = density * I
+	}
+	return
+}
+
+// Collider Component, a Collider without RigidBody is a static collider,
+// its position is the world position of the Transform.
+type Collider struct {
+	engi.Entity
+	shape  Shape
+	offset f32.Vec2
+
+	friction    float32
+	restitution float32
+	density     float32
+
+	// sensor only reports contact, no collision response
+	sensor bool
+
+	// two colliders collide if (a.category & b.mask) != 0 and
+	// (b.category & a.mask) != 0
+	category, mask uint16
+
+	// mass need to be computed again
+	dirty bool
+}
+
+func (c *Collider) Shape() Shape {
+	return c.shape
+}
+
+func (c *Collider) SetShape(s Shape) {
+	c.shape = s
+	c.dirty = true
+}
+
+// Offset is the position of shape relative to the body.
+func (c *Collider) Offset() f32.Vec2 {
+	return c.offset
+}
+
+func (c *Collider) SetOffset(offset f32.Vec2) {
+	c.offset = offset
+	c.dirty = true
+}
+
+func (c *Collider) Friction() float32 {
+	return c.friction
+}
+
+func (c *Collider) SetFriction(f float32) {
+	c.friction = f
+}
+
+func (c *Collider) Restitution() float32 {
+	return c.restitution
+}
+
+func (c *Collider) SetRestitution(r float32) {
+	c.restitution = r
+}
+
+func (c *Collider) Density() float32 {
+	return c.density
+}
+
+func (c *Collider) SetDensity(d float32) {
+	c.density = d
+	c.dirty = true
+}
+
+func (c *Collider) Sensor() bool {
+	return c.sensor
+}
+
+func (c *Collider) SetSensor(sensor bool) {
+	c.sensor = sensor
+}
+
+func (c *Collider) Filter() (category, mask uint16) {
+	return c.category, c.mask
+}
+
+func (c *Collider) SetFilter(category, mask uint16) {
+	c.category, c.mask = category, mask
+}
+
+func (c *Collider) shouldCollide(o *Collider) bool {
+	return c.category&o.mask != 0 && o.category&c.mask != 0
+}
+
+// ColliderTable
+type ColliderTable struct {
+	comps      []Collider
+	_map       map[uint32]int
+	index, cap int
+}
+
+func NewColliderTable(cap int) *ColliderTable {
+	return &ColliderTable{
+		cap:  cap,
+		_map: make(map[uint32]int),
+	}
+}
+
+func (ct *ColliderTable) NewComp(entity engi.Entity) (c *Collider) {
+	if size := len(ct.comps); ct.index >= size {
+		ct.comps = colliderResize(ct.comps, size+64)
+	}
+	ei := entity.Index()
+	if v, ok := ct._map[ei]; ok {
+		c = &ct.comps[v]
+		return
+	}
+	c = &ct.comps[ct.index]
+	c.Entity = entity
+	c.friction = .2
+	c.density = 1
+	c.category, c.mask = 1, 0xFFFF
+	c.dirty = true
+	ct._map[ei] = ct.index
+	ct.index++
+	return
+}
+
+func (ct *ColliderTable) Alive(entity engi.Entity) bool {
+	if v, ok := ct._map[entity.Index()]; ok {
+		return ct.comps[v].Entity == entity
+	}
+	return false
+}
+
+func (ct *ColliderTable) Comp(entity engi.Entity) (c *Collider) {
+	if v, ok := ct._map[entity.Index()]; ok {
+		c = &ct.comps[v]
+	}
+	return
+}
+
+func (ct *ColliderTable) Delete(entity engi.Entity) {
+	ei := entity.Index()
+	if v, ok := ct._map[ei]; ok {
+		if tail := ct.index - 1; v != tail && tail > 0 {
+			ct.comps[v] = ct.comps[tail]
+			// remap index
+			tComp := ct.comps[tail]
+			ei := tComp.Entity.Index()
+			ct._map[ei] = v
+			ct.comps[tail] = Collider{}
+		} else {
+			ct.comps[tail] = Collider{}
+		}
+		ct.index -= 1
+		delete(ct._map, ei)
+	}
+}
+
+func (ct *ColliderTable) Size() (size, cap int) {
+	return ct.index, ct.cap
+}
+
+// EntityAt returns the entity of the i-th comp, i in [0, size)
+func (ct *ColliderTable) EntityAt(i int) engi.Entity {
+	return ct.comps[i].Entity
+}
+
+func (ct *ColliderTable) Destroy() {
+	ct.comps = make([]Collider, 0)
+	ct._map = make(map[uint32]int)
+	ct.index = 0
+}
+
+func colliderResize(slice []Collider, size int) []Collider {
+	newSlice := make([]Collider, size)
+	copy(newSlice, slice)
+	return newSlice
+}
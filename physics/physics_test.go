@@ -0,0 +1,133 @@
+package physics
+
+import (
+	"testing"
+
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx"
+	"korok.io/korok/math"
+	"korok.io/korok/math/f32"
+)
+
+type contactLog struct {
+	begin, end int
+}
+
+func (l *contactLog) OnContactBegin(c *Contact) { l.begin++ }
+func (l *contactLog) OnContactEnd(c *Contact)   { l.end++ }
+
+func newWorld() (*engi.EntityManager, *PhysicsSystem, *RigidBodyTable, *ColliderTable, *gfx.TransformTable) {
+	em := engi.NewEntityManager()
+	bt, ct, xt := NewRigidBodyTable(64), NewColliderTable(64), gfx.NewTransformTable(64)
+	ps := NewPhysicsSystem()
+	ps.RequireTable([]interface{}{bt, ct, xt})
+	return em, ps, bt, ct, xt
+}
+
+func TestFallOnGround(t *testing.T) {
+	em, ps, bt, ct, xt := newWorld()
+	cl := &contactLog{}
+	ps.AddContactListener(cl)
+
+	// static ground, top at y = 0
+	ground := em.New()
+	xt.NewComp(ground).SetPosition(f32.Vec2{0, -10})
+	ct.NewComp(ground).SetShape(NewAABB(1000, 20))
+
+	shapes := []Shape{NewBox(20, 20), NewCircle(10), NewAABB(20, 20)}
+	boxes := make([]engi.Entity, len(shapes))
+	for i, s := range shapes {
+		e := em.New()
+		boxes[i] = e
+		xt.NewComp(e).SetPosition(f32.Vec2{float32(i * 100), 100})
+		bt.NewComp(e)
+		ct.NewComp(e).SetShape(s)
+	}
+
+	for i := 0; i < 180; i++ {
+		ps.Update(1.0 / 60)
+	}
+	for i, e := range boxes {
+		p := xt.Comp(e).World().Position
+		if math.ABS(p[1]-10) > 1 || math.ABS(p[0]-float32(i*100)) > 1 {
+			t.Errorf("shape %d should rest on the ground, got: %v", i, p)
+		}
+		if v := bt.Comp(e).Velocity(); v.Len() > 1 {
+			t.Errorf("shape %d should be at rest, velocity: %v", i, v)
+		}
+	}
+	if cl.begin != 3 || cl.end != 0 {
+		t.Errorf("contact events, begin: %d, end: %d", cl.begin, cl.end)
+	}
+	// resting contacts don't create garbage
+	if n := testing.AllocsPerRun(10, func() { ps.Update(1.0 / 60) }); n != 0 {
+		t.Errorf("step allocates %v times", n)
+	}
+
+	// jump
+	bt.Comp(boxes[0]).SetVelocity(f32.Vec2{0, 300})
+	ps.Update(1.0 / 60)
+	ps.Update(1.0 / 60)
+	if cl.end != 1 {
+		t.Error("contact should end, got:", cl.end)
+	}
+}
+
+func TestCollideBounce(t *testing.T) {
+	em, ps, bt, ct, _ := newWorld()
+	ps.Gravity = f32.Vec2{}
+
+	a, b := em.New(), em.New()
+	ba, bb := bt.NewComp(a), bt.NewComp(b)
+	ba.SetPosition(f32.Vec2{0, 0})
+	ba.SetVelocity(f32.Vec2{100, 0})
+	bb.SetPosition(f32.Vec2{50, 0})
+	for _, e := range []engi.Entity{a, b} {
+		c := ct.NewComp(e)
+		c.SetShape(NewCircle(10))
+		c.SetRestitution(1)
+	}
+	for i := 0; i < 60; i++ {
+		ps.Step(1.0 / 60)
+	}
+	// equal mass, elastic: velocity exchanged
+	va, vb := bt.Comp(a).Velocity(), bt.Comp(b).Velocity()
+	if math.ABS(va[0]) > 1 || math.ABS(vb[0]-100) > 1 {
+		t.Errorf("velocity should be exchanged, got: %v, %v", va, vb)
+	}
+
+	// filter
+	ct.Comp(a).SetFilter(1, 0xFFFF^2)
+	ct.Comp(b).SetFilter(2, 0xFFFF)
+	bt.Comp(a).SetPosition(f32.Vec2{200, 0})
+	bt.Comp(b).SetPosition(f32.Vec2{205, 0})
+	ps.Step(1.0 / 60)
+	if len(ps.Touching(a)) != 0 {
+		t.Error("filtered colliders should not collide")
+	}
+}
+
+func TestCollidePolygons(t *testing.T) {
+	a := proxy{c: &Collider{shape: NewBox(2, 2)}}
+	b := proxy{c: &Collider{shape: NewBox(2, 2)}}
+	a.update(f32.Vec2{0, 0}, 0)
+	b.update(f32.Vec2{1.5, 0.5}, 0)
+
+	m := Manifold{}
+	if !collide(&a, &b, &m) {
+		t.Fatal("boxes should collide")
+	}
+	if m.Normal != (f32.Vec2{1, 0}) || m.Count != 2 {
+		t.Errorf("wrong manifold: %+v", m)
+	}
+	for i := 0; i < m.Count; i++ {
+		if math.ABS(m.Depth[i]-.5) > 1e-5 {
+			t.Errorf("depth, expected 0.5, got: %v", m.Depth[i])
+		}
+	}
+
+	b.update(f32.Vec2{2.5, 0}, math.Pi/4)
+	if collide(&a, &b, &m) {
+		t.Error("separated boxes should not collide")
+	}
+}
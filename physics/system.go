@@ -0,0 +1,505 @@
+package physics
+
+import (
+	"korok.io/korok/engi"
+	"korok.io/korok/gfx"
+	"korok.io/korok/math"
+	"korok.io/korok/math/f32"
+
+	"sort"
+)
+
+// 物理系统使用固定步长模拟, 每帧可能执行多步, 模拟结束后把刚体的位置和
+// 旋转写回 gfx.Transform.
+//
+// 单位是像素, 默认重力为 (0, -980), 即 100 像素 = 1 米.
+
+const (
+	// allowed penetration, avoid jitter
+	linearSlop = 0.5
+	// percent of penetration to correct each step
+	baumgarte = 0.4
+)
+
+// Contact is reported to ContactListener, Normal points from A to B.
+type Contact struct {
+	A, B engi.Entity
+	Manifold
+	// one of the collider is a sensor
+	Sensor bool
+}
+
+// ContactListener receives contact events, OnContactBegin is called when
+// two colliders start touching, OnContactEnd is called when they separate
+// or one of them is removed.
+type ContactListener interface {
+	OnContactBegin(c *Contact)
+	OnContactEnd(c *Contact)
+}
+
+type PhysicsSystem struct {
+	Gravity    f32.Vec2
+	TimeStep   float32
+	MaxSteps   int
+	Iterations int
+
+	bt *RigidBodyTable
+	ct *ColliderTable
+	xt *gfx.TransformTable
+
+	// time not simulated
+	accumulator float32
+
+	proxies []proxy
+	sorted  byMinX
+	pairs   []pair
+	solver  []contactSolver
+
+	// touching pairs of last step
+	touching map[pairKey]Contact
+	current  map[pairKey]Contact
+
+	listeners []ContactListener
+}
+
+type pair struct {
+	a, b int
+}
+
+// entity pair, the smaller one first
+type pairKey struct {
+	a, b engi.Entity
+}
+
+func NewPhysicsSystem() *PhysicsSystem {
+	return &PhysicsSystem{
+		Gravity:    f32.Vec2{0, -980},
+		TimeStep:   1.0 / 60,
+		MaxSteps:   4,
+		Iterations: 8,
+		touching:   make(map[pairKey]Contact),
+		current:    make(map[pairKey]Contact),
+	}
+}
+
+func (ps *PhysicsSystem) RequireTable(tables []interface{}) {
+	for _, t := range tables {
+		switch table := t.(type) {
+		case *RigidBodyTable:
+			ps.bt = table
+		case *ColliderTable:
+			ps.ct = table
+		case *gfx.TransformTable:
+			ps.xt = table
+		}
+	}
+}
+
+func (ps *PhysicsSystem) AddContactListener(l ContactListener) {
+	ps.listeners = append(ps.listeners, l)
+}
+
+func (ps *PhysicsSystem) RemoveContactListener(l ContactListener) {
+	for i, v := range ps.listeners {
+		if v == l {
+			ps.listeners = append(ps.listeners[:i], ps.listeners[i+1:]...)
+			return
+		}
+	}
+}
+
+func (ps *PhysicsSystem) Update(dt float32) {
+	if ps.bt == nil || ps.ct == nil {
+		return
+	}
+	ps.accumulator += dt
+	steps := 0
+	for ps.accumulator >= ps.TimeStep && steps < ps.MaxSteps {
+		ps.Step(ps.TimeStep)
+		ps.accumulator -= ps.TimeStep
+		steps++
+	}
+	// drop the time can't catch up
+	if steps == ps.MaxSteps {
+		ps.accumulator = 0
+	}
+	ps.writeTransform()
+}
+
+// Step advances the simulation with dt.
+func (ps *PhysicsSystem) Step(dt float32) {
+	if ps.bt == nil || ps.ct == nil {
+		return
+	}
+	bodies := ps.bt.comps[:ps.bt.index]
+
+	// sync and integrate velocity
+	for i := range bodies {
+		b := &bodies[i]
+		if !b.init {
+			ps.readTransform(b)
+		}
+		if b.dirty || ps.colliderDirty(b.Entity) {
+			ps.updateMass(b)
+		}
+		if b.typ != Dynamic {
+			continue
+		}
+		acc := ps.Gravity.Mul(b.GravityScale).Add(b.force.Mul(b.invMass))
+		b.velocity = b.velocity.Add(acc.Mul(dt))
+		b.angularVelocity += b.torque * b.invInertia * dt
+		b.velocity = b.velocity.Mul(1 / (1 + dt*b.LinearDamping))
+		b.angularVelocity *= 1 / (1 + dt*b.AngularDamping)
+	}
+
+	ps.updateProxies()
+	ps.findPairs()
+	ps.narrowPhase()
+
+	// velocity constraints
+	for i := range ps.solver {
+		ps.solver[i].prepare(dt)
+	}
+	for it := 0; it < ps.Iterations; it++ {
+		for i := range ps.solver {
+			ps.solver[i].solve()
+		}
+	}
+
+	// integrate position
+	for i := range bodies {
+		b := &bodies[i]
+		if b.typ == Static {
+			continue
+		}
+		b.position = b.position.Add(b.velocity.Mul(dt))
+		b.rotation += b.angularVelocity * dt
+		b.force, b.torque = f32.Vec2{}, 0
+	}
+
+	// position correction
+	for i := range ps.solver {
+		ps.solver[i].correct()
+	}
+
+	ps.notify()
+}
+
+func (ps *PhysicsSystem) readTransform(b *RigidBody) {
+	b.init = true
+	if ps.xt == nil {
+		return
+	}
+	if xf := ps.xt.Comp(b.Entity); xf != nil {
+		world := xf.World()
+		b.position, b.rotation = world.Position, world.Rotation
+	}
+}
+
+// Transform is additive, world = parent.world + local
+func (ps *PhysicsSystem) writeTransform() {
+	if ps.xt == nil {
+		return
+	}
+	bodies := ps.bt.comps[:ps.bt.index]
+	for i := range bodies {
+		b := &bodies[i]
+		if b.typ == Static {
+			continue
+		}
+		xf := ps.xt.Comp(b.Entity)
+		if xf == nil {
+			continue
+		}
+		if p := xf.Parent(); p != nil {
+			w := p.World()
+			xf.SetPosition(b.position.Sub(w.Position))
+			xf.SetRotation(b.rotation - w.Rotation)
+		} else {
+			xf.SetPosition(b.position)
+			xf.SetRotation(b.rotation)
+		}
+	}
+}
+
+func (ps *PhysicsSystem) colliderDirty(e engi.Entity) bool {
+	c := ps.ct.Comp(e)
+	return c != nil && c.dirty
+}
+
+func (ps *PhysicsSystem) updateMass(b *RigidBody) {
+	b.dirty = false
+	mass, inertia := float32(1), float32(0)
+	if c := ps.ct.Comp(b.Entity); c != nil {
+		c.dirty = false
+		mass, inertia = c.shape.massData(c.density)
+		// parallel axis
+		inertia += mass * c.offset.Dot(c.offset)
+	}
+	b.setMass(mass, inertia)
+}
+
+// world geometry of all colliders
+func (ps *PhysicsSystem) updateProxies() {
+	colliders := ps.ct.comps[:ps.ct.index]
+	ps.proxies = ps.proxies[:0]
+	for i := range colliders {
+		c := &colliders[i]
+		p := proxy{c: c, body: ps.bt.Comp(c.Entity)}
+		if p.body != nil {
+			if !p.body.init {
+				ps.readTransform(p.body)
+			}
+			p.update(p.body.position, p.body.rotation)
+		} else if xf := ps.transform(c.Entity); xf != nil {
+			world := xf.World()
+			p.update(world.Position, world.Rotation)
+		} else {
+			p.update(f32.Vec2{}, 0)
+		}
+		ps.proxies = append(ps.proxies, p)
+	}
+}
+
+func (ps *PhysicsSystem) transform(e engi.Entity) *gfx.Transform {
+	if ps.xt == nil {
+		return nil
+	}
+	return ps.xt.Comp(e)
+}
+
+// sweep and prune along the x axis
+func (ps *PhysicsSystem) findPairs() {
+	proxies := ps.proxies
+	sorted := &ps.sorted
+	sorted.proxies, sorted.index = proxies, sorted.index[:0]
+	for i := range proxies {
+		sorted.index = append(sorted.index, i)
+	}
+	sort.Sort(sorted)
+
+	ps.pairs = ps.pairs[:0]
+	for i, ai := range sorted.index {
+		a := &proxies[ai]
+		for _, bi := range sorted.index[i+1:] {
+			b := &proxies[bi]
+			if b.bounds.Min[0] > a.bounds.Max[0] {
+				break
+			}
+			if !a.bounds.Overlap(&b.bounds) || !a.c.shouldCollide(b.c) {
+				continue
+			}
+			// at least one is moving, or a sensor
+			if !a.movable() && !b.movable() && !a.c.sensor && !b.c.sensor {
+				continue
+			}
+			ps.pairs = append(ps.pairs, pair{ai, bi})
+		}
+	}
+}
+
+// proxy indices sorted by the left of bounds
+type byMinX struct {
+	index   []int
+	proxies []proxy
+}
+
+func (s *byMinX) Len() int      { return len(s.index) }
+func (s *byMinX) Swap(i, j int) { s.index[i], s.index[j] = s.index[j], s.index[i] }
+func (s *byMinX) Less(i, j int) bool {
+	return s.proxies[s.index[i]].bounds.Min[0] < s.proxies[s.index[j]].bounds.Min[0]
+}
+
+func (p *proxy) movable() bool {
+	return p.body != nil && p.body.typ != Static
+}
+
+func (ps *PhysicsSystem) narrowPhase() {
+	ps.solver = ps.solver[:0]
+	for _, pr := range ps.pairs {
+		a, b := &ps.proxies[pr.a], &ps.proxies[pr.b]
+		m := Manifold{}
+		if !collide(a, b, &m) {
+			continue
+		}
+		// make the key stable, A is always the smaller entity
+		if b.c.Entity < a.c.Entity {
+			a, b = b, a
+			m.Normal = m.Normal.Mul(-1)
+		}
+		sensor := a.c.sensor || b.c.sensor
+		ps.current[pairKey{a.c.Entity, b.c.Entity}] = Contact{a.c.Entity, b.c.Entity, m, sensor}
+
+		if sensor || (!a.dynamic() && !b.dynamic()) {
+			continue
+		}
+		ps.solver = append(ps.solver, newContactSolver(a, b, &m))
+	}
+}
+
+func (p *proxy) dynamic() bool {
+	return p.body != nil && p.body.typ == Dynamic
+}
+
+// compare touching pairs with last step
+func (ps *PhysicsSystem) notify() {
+	if len(ps.listeners) > 0 {
+		for k, c := range ps.current {
+			if _, ok := ps.touching[k]; !ok {
+				c := c
+				for _, l := range ps.listeners {
+					l.OnContactBegin(&c)
+				}
+			}
+		}
+		for k, c := range ps.touching {
+			if _, ok := ps.current[k]; !ok {
+				c := c
+				for _, l := range ps.listeners {
+					l.OnContactEnd(&c)
+				}
+			}
+		}
+	}
+	ps.touching, ps.current = ps.current, ps.touching
+	for k := range ps.current {
+		delete(ps.current, k)
+	}
+}
+
+// Touching returns the contacts of the entity in last step.
+func (ps *PhysicsSystem) Touching(e engi.Entity) (list []Contact) {
+	for k, c := range ps.touching {
+		if k.a == e || k.b == e {
+			list = append(list, c)
+		}
+	}
+	return
+}
+
+// sequential impulse solver of one manifold
+type contactSolver struct {
+	a, b        *RigidBody
+	normal      f32.Vec2
+	count       int
+	friction    float32
+	restitution float32
+	points      [2]solverPoint
+}
+
+type solverPoint struct {
+	rA, rB         f32.Vec2
+	depth          float32
+	normalMass     float32
+	tangentMass    float32
+	bias           float32
+	normalImpulse  float32
+	tangentImpulse float32
+}
+
+// static body used by colliders without RigidBody
+var staticBody = &RigidBody{typ: Static}
+
+func newContactSolver(a, b *proxy, m *Manifold) contactSolver {
+	cs := contactSolver{a: a.body, b: b.body, normal: m.Normal, count: m.Count}
+	if cs.a == nil {
+		cs.a = staticBody
+	}
+	if cs.b == nil {
+		cs.b = staticBody
+	}
+	cs.friction = (a.c.friction + b.c.friction) / 2
+	cs.restitution = math.Max(a.c.restitution, b.c.restitution)
+	for i := 0; i < m.Count; i++ {
+		sp := &cs.points[i]
+		sp.rA = m.Points[i].Sub(cs.a.position)
+		sp.rB = m.Points[i].Sub(cs.b.position)
+		sp.depth = m.Depth[i]
+	}
+	return cs
+}
+
+func cross(w float32, v f32.Vec2) f32.Vec2 {
+	return f32.Vec2{-w * v[1], w * v[0]}
+}
+
+func (cs *contactSolver) relativeVelocity(sp *solverPoint) f32.Vec2 {
+	a, b := cs.a, cs.b
+	va := a.velocity.Add(cross(a.angularVelocity, sp.rA))
+	vb := b.velocity.Add(cross(b.angularVelocity, sp.rB))
+	return vb.Sub(va)
+}
+
+func (cs *contactSolver) prepare(dt float32) {
+	a, b := cs.a, cs.b
+	n := cs.normal
+	t := f32.Vec2{n[1], -n[0]}
+	for i := 0; i < cs.count; i++ {
+		sp := &cs.points[i]
+		rnA, rnB := sp.rA.Cross(n), sp.rB.Cross(n)
+		k := a.invMass + b.invMass + a.invInertia*rnA*rnA + b.invInertia*rnB*rnB
+		if k > 0 {
+			sp.normalMass = 1 / k
+		}
+		rtA, rtB := sp.rA.Cross(t), sp.rB.Cross(t)
+		k = a.invMass + b.invMass + a.invInertia*rtA*rtA + b.invInertia*rtB*rtB
+		if k > 0 {
+			sp.tangentMass = 1 / k
+		}
+		// restitution, ignore small velocity to avoid jitter
+		if vn := cs.relativeVelocity(sp).Dot(n); vn < -1 {
+			sp.bias = -cs.restitution * vn
+		}
+	}
+}
+
+func (cs *contactSolver) apply(sp *solverPoint, p f32.Vec2) {
+	a, b := cs.a, cs.b
+	a.velocity = a.velocity.Sub(p.Mul(a.invMass))
+	a.angularVelocity -= a.invInertia * sp.rA.Cross(p)
+	b.velocity = b.velocity.Add(p.Mul(b.invMass))
+	b.angularVelocity += b.invInertia * sp.rB.Cross(p)
+}
+
+func (cs *contactSolver) solve() {
+	n := cs.normal
+	t := f32.Vec2{n[1], -n[0]}
+	for i := 0; i < cs.count; i++ {
+		sp := &cs.points[i]
+
+		// friction
+		vt := cs.relativeVelocity(sp).Dot(t)
+		lambda := -vt * sp.tangentMass
+		maxFriction := cs.friction * sp.normalImpulse
+		impulse := math.Clamp(sp.tangentImpulse+lambda, -maxFriction, maxFriction)
+		lambda, sp.tangentImpulse = impulse-sp.tangentImpulse, impulse
+		cs.apply(sp, t.Mul(lambda))
+
+		// normal
+		vn := cs.relativeVelocity(sp).Dot(n)
+		lambda = -sp.normalMass * (vn - sp.bias)
+		impulse = math.Max(sp.normalImpulse+lambda, 0)
+		lambda, sp.normalImpulse = impulse-sp.normalImpulse, impulse
+		cs.apply(sp, n.Mul(lambda))
+	}
+}
+
+// push bodies apart along the normal, only the deepest point is used
+func (cs *contactSolver) correct() {
+	a, b := cs.a, cs.b
+	total := a.invMass + b.invMass
+	if total == 0 {
+		return
+	}
+	depth := cs.points[0].depth
+	if cs.count > 1 && cs.points[1].depth > depth {
+		depth = cs.points[1].depth
+	}
+	c := math.Max(depth-linearSlop, 0) * baumgarte / total
+	if c == 0 {
+		return
+	}
+	p := cs.normal.Mul(c)
+	a.position = a.position.Sub(p.Mul(a.invMass))
+	b.position = b.position.Add(p.Mul(b.invMass))
+}